@@ -2,37 +2,121 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"runtime/debug"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/m-breuer/webguard-instance-v2/internal/config"
 	"github.com/m-breuer/webguard-instance-v2/internal/core"
+	"github.com/m-breuer/webguard-instance-v2/internal/logging"
+	"github.com/m-breuer/webguard-instance-v2/internal/metrics"
 	"github.com/m-breuer/webguard-instance-v2/internal/runner"
 	"github.com/m-breuer/webguard-instance-v2/internal/scheduler"
 	"github.com/m-breuer/webguard-instance-v2/internal/server"
 )
 
+var (
+	version = "dev"
+	commit  string
+	date    string
+)
+
+const shutdownTimeout = 30 * time.Second
+
+const selfTestTimeout = 10 * time.Second
+
+// selfTestResolver resolves the Core host for STARTUP_SELFTEST. Tests
+// replace it.
+var selfTestResolver = net.DefaultResolver
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
 type monitoringService interface {
 	RunMonitoring(ctx context.Context) error
+	RunMonitoringType(ctx context.Context, name string) error
+	CollectMonitoring(ctx context.Context, name string) ([]runner.CheckResult, error)
+	Shutdown(ctx context.Context) error
+	Pause()
+	Resume()
+	Paused() bool
 }
 
-type serveFunc func(logger *log.Logger, service monitoringService, cfg config.Config) int
+type serveFunc func(logger *slog.Logger, service monitoringService, cfg config.Config) int
 
 func main() {
-	logger := log.New(os.Stdout, "", 0)
-	cfg := config.FromEnv()
+	configPath, args, err := parseGlobalFlags(os.Args[1:], os.Stderr)
+	if err != nil {
+		os.Exit(1)
+	}
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.HTTPUserAgent == "" {
+		cfg.HTTPUserAgent = defaultUserAgent()
+	}
+	logger := logging.New(logOutput(args), cfg.LogFormat, cfg.LogLevel)
 	coreClient := core.NewClient(cfg.WebGuardCoreAPIURL, cfg.WebGuardCoreAPIKey, cfg.WebGuardLocation)
-	service := runner.New(coreClient, cfg, logger)
+	coreClient.SetRetry(cfg.CoreAPIRetryTimes, time.Duration(cfg.CoreAPIRetryBaseDelayMS)*time.Millisecond)
+	coreClient.SetRateLimit(cfg.CoreAPIRPS)
+	coreClient.SetTimeouts(time.Duration(cfg.CoreFetchTimeoutSeconds)*time.Second, time.Duration(cfg.CorePostTimeoutSeconds)*time.Second)
+	registry := metrics.NewRegistry()
+	service := runner.New(coreClient, cfg, logger, registry)
 
-	exitCode := run(os.Args[1:], logger, cfg, service, runServe, os.Stderr)
+	exitCode := run(args, logger, cfg, service, newServe(coreClient, registry), os.Stdout, os.Stderr)
 	os.Exit(exitCode)
 }
 
-func run(args []string, logger *log.Logger, cfg config.Config, service monitoringService, serve serveFunc, stderr io.Writer) int {
+// parseGlobalFlags consumes the flags that precede the command and returns
+// the config file path together with the remaining arguments.
+func parseGlobalFlags(args []string, stderr io.Writer) (string, []string, error) {
+	flags := flag.NewFlagSet("webguard-instance", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", "", "read settings from a YAML or JSON file; environment variables take precedence")
+	if err := flags.Parse(args); err != nil {
+		return "", nil, err
+	}
+	return *configPath, flags.Args(), nil
+}
+
+// logOutput keeps logs off stdout when the monitoring command prints its
+// results there as JSON. It parses the command's flags like
+// runMonitoringCommand does, so every form the flag package accepts counts.
+func logOutput(args []string) io.Writer {
+	if len(args) == 0 || args[0] != "monitoring" {
+		return os.Stdout
+	}
+	options, err := parseMonitoringFlags(args[1:], io.Discard)
+	if err == nil && options.output == outputJSON {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+func loadConfig(path string) (config.Config, error) {
+	if path == "" {
+		return config.FromEnv(), nil
+	}
+	return config.Load(path)
+}
+
+func run(args []string, logger *slog.Logger, cfg config.Config, service monitoringService, serve serveFunc, stdout, stderr io.Writer) int {
 	command := "serve"
 	if len(args) > 0 {
 		command = args[0]
@@ -40,29 +124,363 @@ func run(args []string, logger *log.Logger, cfg config.Config, service monitorin
 
 	switch command {
 	case "serve":
-		return serve(logger, service, cfg)
+		var serveArgs []string
+		if len(args) > 0 {
+			serveArgs = args[1:]
+		}
+		return runServeCommand(serveArgs, logger, cfg, service, serve, stderr)
 	case "monitoring":
-		_ = service.RunMonitoring(context.Background())
+		return runMonitoringCommand(args[1:], service, stdout, stderr)
+	case "validate":
+		return runValidateCommand(cfg, stdout, stderr)
+	case "version":
+		if err := json.NewEncoder(stdout).Encode(buildInfo()); err != nil {
+			fmt.Fprintf(stderr, "failed to write version: %v\n", err)
+			return 1
+		}
 		return 0
 	default:
 		fmt.Fprintf(stderr, "unknown command: %s\n\n", command)
 		fmt.Fprintln(stderr, "Usage:")
-		fmt.Fprintln(stderr, "  webguard-instance serve")
-		fmt.Fprintln(stderr, "  webguard-instance monitoring")
+		fmt.Fprintln(stderr, "  webguard-instance [--config=path] serve [--once]")
+		fmt.Fprintln(stderr, "  webguard-instance [--config=path] monitoring [--type=http|ping|icmp|keyword|port|dns|smtp|websocket|ssl|domain_expiration] [--output=text|json]")
+		fmt.Fprintln(stderr, "  webguard-instance [--config=path] validate")
+		fmt.Fprintln(stderr, "  webguard-instance version")
+		return 1
+	}
+}
+
+// runServeCommand starts the scheduler and health server, or with --once
+// runs a single monitoring cycle for cron-driven deployments and exits.
+func runServeCommand(args []string, logger *slog.Logger, cfg config.Config, service monitoringService, serve serveFunc, stderr io.Writer) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	once := flags.Bool("once", false, "run a single monitoring cycle and exit")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if !*once {
+		return serve(logger, service, cfg)
+	}
+
+	if err := service.RunMonitoring(context.Background()); err != nil {
+		logger.Error("Monitoring run failed", "error", err)
+		return 1
+	}
+	return 0
+}
+
+type monitoringOptions struct {
+	monitoringType string
+	output         string
+}
+
+func parseMonitoringFlags(args []string, stderr io.Writer) (monitoringOptions, error) {
+	var options monitoringOptions
+	flags := flag.NewFlagSet("monitoring", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&options.monitoringType, "type", "", "run only the given monitoring type")
+	flags.StringVar(&options.output, "output", outputText, "text logs results as they are posted, json prints them to stdout instead of posting")
+	err := flags.Parse(args)
+	return options, err
+}
+
+func runMonitoringCommand(args []string, service monitoringService, stdout, stderr io.Writer) int {
+	options, err := parseMonitoringFlags(args, stderr)
+	if err != nil {
+		return 1
+	}
+
+	if options.monitoringType != "" && !runner.IsRunnableType(options.monitoringType) {
+		fmt.Fprintf(stderr, "unknown monitoring type: %s\n", options.monitoringType)
+		return 1
+	}
+
+	switch {
+	case options.output == outputJSON:
+		err = printMonitoringResults(service, options.monitoringType, stdout)
+	case options.output != outputText:
+		fmt.Fprintf(stderr, "unknown output format: %s\n", options.output)
+		return 1
+	case options.monitoringType == "":
+		err = service.RunMonitoring(context.Background())
+	default:
+		err = service.RunMonitoringType(context.Background(), options.monitoringType)
+	}
+
+	// Individual checks reporting down are results, not errors; only a run
+	// that could not fetch or finish its phases fails the command.
+	if err != nil {
+		fmt.Fprintf(stderr, "monitoring run failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// printMonitoringResults runs the checks without posting them and writes the
+// results to stdout as a JSON array, which is empty rather than null when no
+// check ran.
+func printMonitoringResults(service monitoringService, monitoringType string, stdout io.Writer) error {
+	results, err := service.CollectMonitoring(context.Background(), monitoringType)
+	if results == nil {
+		results = []runner.CheckResult{}
+	}
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if encodeErr := encoder.Encode(results); encodeErr != nil {
+		return errors.Join(err, fmt.Errorf("write results: %w", encodeErr))
+	}
+	return err
+}
+
+func runValidateCommand(cfg config.Config, stdout, stderr io.Writer) int {
+	settings := []struct {
+		name  string
+		value any
+	}{
+		{name: "WEBGUARD_LOCATION", value: cfg.WebGuardLocation},
+		{name: "WEBGUARD_CORE_API_URL", value: cfg.WebGuardCoreAPIURL},
+		{name: "WEBGUARD_CORE_API_KEY", value: maskSecret(cfg.WebGuardCoreAPIKey)},
+		{name: "CORE_API_RETRY_TIMES", value: cfg.CoreAPIRetryTimes},
+		{name: "CORE_API_RETRY_BASE_DELAY_MS", value: cfg.CoreAPIRetryBaseDelayMS},
+		{name: "CORE_API_RPS", value: cfg.CoreAPIRPS},
+		{name: "CORE_POST_FAILURE_THRESHOLD", value: cfg.CorePostFailureThreshold},
+		{name: "CORE_POST_BATCH_SIZE", value: cfg.CorePostBatchSize},
+		{name: "POST_ON_CHANGE_ONLY", value: cfg.PostOnChangeOnly},
+		{name: "POST_HEARTBEAT_EVERY_RUNS", value: cfg.PostHeartbeatEveryRuns},
+		{name: "CORE_FETCH_TIMEOUT", value: cfg.CoreFetchTimeoutSeconds},
+		{name: "CORE_POST_TIMEOUT", value: cfg.CorePostTimeoutSeconds},
+		{name: "QUEUE_DEFAULT_WORKERS", value: cfg.QueueDefaultWorkers},
+		{name: "QUEUE_RESPONSE_WORKERS", value: cfg.QueueResponseWorkers},
+		{name: "QUEUE_SSL_WORKERS", value: cfg.QueueSSLWorkers},
+		{name: "MAX_CONCURRENT_CHECKS", value: cfg.MaxConcurrentChecks},
+		{name: "MAX_MONITORINGS_PER_RUN", value: cfg.MaxMonitoringsPerRun},
+		{name: "ENABLED_TYPES", value: strings.Join(cfg.EnabledTypes, ",")},
+		{name: "MONITORING_INTERVAL_SECONDS", value: cfg.MonitoringIntervalSeconds},
+		{name: "PHASE_TIMEOUT_SECONDS", value: cfg.PhaseTimeoutSeconds},
+		{name: "RUN_MAX_DURATION_SECONDS", value: cfg.RunMaxDurationSeconds},
+		{name: "SCHEDULER_JITTER_SECONDS", value: cfg.SchedulerJitterSeconds},
+		{name: "HTTP_RETRY_TIMES", value: cfg.HTTPRetryTimes},
+		{name: "HTTP_RETRY_BASE_DELAY_MS", value: cfg.HTTPRetryBaseDelayMS},
+		{name: "HTTP_MAX_BODY_BYTES", value: cfg.HTTPMaxBodyBytes},
+		{name: "HTTP_KEEP_ALIVE_SECONDS", value: cfg.HTTPKeepAliveSeconds},
+		{name: "HTTP_MAX_IDLE_CONNS_PER_HOST", value: cfg.HTTPMaxIdleConnsPerHost},
+		{name: "HTTP_USER_AGENT", value: cfg.HTTPUserAgent},
+		{name: "HTTP_ACCEPT_LANGUAGE", value: cfg.HTTPAcceptLanguage},
+		{name: "BYPASS_CACHE", value: cfg.BypassCache},
+		{name: "HTTP_PROXY_URL", value: maskUserinfo(cfg.HTTPProxyURL)},
+		{name: "SOCKS5_PROXY", value: maskSecret(cfg.SOCKS5Proxy)},
+		{name: "DNS_RESOLVER", value: cfg.DNSResolver},
+		{name: "BLOCK_PRIVATE_TARGETS", value: cfg.BlockPrivateTargets},
+		{name: "DRY_RUN", value: cfg.DryRun},
+		{name: "RESULT_SINK", value: cfg.ResultSink},
+		{name: "RESULT_SINK_FILE", value: cfg.ResultSinkFile},
+		{name: "VERIFY_TLS", value: cfg.VerifyTLS},
+		{name: "TLS_MIN_VERSION", value: cfg.TLSMinVersion},
+		{name: "CA_BUNDLE_PATH", value: cfg.CABundlePath},
+		{name: "SSL_EXPIRY_WARN_DAYS", value: cfg.SSLExpiryWarnDays},
+		{name: "NOTIFY_WEBHOOK_URL", value: maskSecret(cfg.NotifyWebhookURL)},
+		{name: "LOG_FORMAT", value: cfg.LogFormat},
+		{name: "LOG_LEVEL", value: cfg.LogLevel},
+		{name: "BIND_ADDRESS", value: cfg.Address},
+		{name: "ADMIN_BIND_ADDRESS", value: cfg.AdminAddress},
+		{name: "HEALTH_PATH_PREFIX", value: cfg.HealthPathPrefix},
+		{name: "STARTUP_SELFTEST", value: cfg.StartupSelfTest},
+		{name: "SHUTDOWN_GRACE_SECONDS", value: cfg.ShutdownGraceSeconds},
+		{name: "ADMIN_TOKEN", value: maskSecret(cfg.AdminToken)},
+	}
+
+	fmt.Fprintln(stdout, "Configuration:")
+	for _, setting := range settings {
+		fmt.Fprintf(stdout, "  %-30s %v\n", setting.name, setting.value)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(stderr, "Configuration is invalid:")
+		for _, problem := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(stderr, "  - %s\n", problem)
+		}
 		return 1
 	}
+
+	fmt.Fprintln(stdout, "Configuration is valid.")
+	return 0
+}
+
+func maskSecret(secret string) string {
+	if secret == "" {
+		return "(not set)"
+	}
+	return "********"
+}
+
+// maskUserinfo masks the credentials of a URL like maskSecret does and keeps
+// the rest readable. A value that does not parse is masked whole.
+func maskUserinfo(rawURL string) string {
+	if rawURL == "" {
+		return maskSecret(rawURL)
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return maskSecret(rawURL)
+	}
+	if parsed.User == nil {
+		return parsed.String()
+	}
+	// url.User would percent-encode the mask, so it is put in by hand.
+	parsed.User = nil
+	return strings.Replace(parsed.String(), "//", "//"+maskSecret(rawURL)+"@", 1)
+}
+
+func newServe(readiness server.CoreClient, registry *metrics.Registry) serveFunc {
+	return func(logger *slog.Logger, service monitoringService, cfg config.Config) int {
+		return runServe(logger, service, cfg, readiness, registry)
+	}
 }
 
-func runServe(logger *log.Logger, service monitoringService, cfg config.Config) int {
+func runServe(logger *slog.Logger, service monitoringService, cfg config.Config, readiness server.CoreClient, registry *metrics.Registry) int {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	go scheduler.RunEveryFiveMinutes(ctx, logger, service.RunMonitoring)
+	if cfg.StartupSelfTest {
+		if err := startupSelfTest(ctx, logger, cfg.WebGuardCoreAPIURL, selfTestResolver); err != nil {
+			logger.Error("Startup self-test failed, refusing to start", "error", err)
+			return 1
+		}
+	}
+
+	interval := time.Duration(cfg.MonitoringIntervalSeconds) * time.Second
+	maxRunDuration := time.Duration(cfg.RunMaxDurationSeconds) * time.Second
+	jitter := time.Duration(cfg.SchedulerJitterSeconds) * time.Second
+	// Runs outlive the signal so checks in flight can still post their
+	// results while draining; runCtx is only cancelled once the drain
+	// deadline has passed.
+	runCtx, cancelRuns := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelRuns()
+	go scheduler.RunEveryInterval(ctx, logger, interval, service.RunMonitoring, scheduler.Options{
+		RunContext:  runCtx,
+		MaxDuration: maxRunDuration,
+		Jitter:      jitter,
+	})
+
+	var admin http.Handler
+	if cfg.AdminToken != "" {
+		admin = server.AdminHandler(service, cfg.AdminToken)
+	}
+
+	public := server.WithPathPrefix(cfg.HealthPathPrefix, server.PublicHandler(readiness, buildInfo(), logger))
+	internal := server.InternalHandler(registry, admin)
+
+	exitCode := 0
+	if err := startServers(ctx, logger, cfg, public, internal); err != nil {
+		logger.Error("Health server exited with error", "error", err)
+		exitCode = 1
+	}
 
-	if err := server.Start(ctx, cfg.Address, logger); err != nil {
-		logger.Printf("Health server exited with error: %v", err)
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer drainCancel()
+	if err := service.Shutdown(drainCtx); err != nil {
+		cancelRuns()
+		logger.Error("Monitoring run did not drain before shutdown", "error", err)
 		return 1
 	}
 
-	return 0
+	return exitCode
+}
+
+// startupSelfTest checks that the Core host resolves and accepts TCP
+// connections, so a wrong WEBGUARD_CORE_API_URL or a blocked network fails
+// the start instead of every run.
+func startupSelfTest(ctx context.Context, logger *slog.Logger, coreURL string, resolver *net.Resolver) error {
+	ctx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+	defer cancel()
+
+	endpoint, err := url.Parse(strings.TrimSpace(coreURL))
+	if err != nil || endpoint.Hostname() == "" {
+		return fmt.Errorf("parse Core URL %q: invalid URL", coreURL)
+	}
+	host := endpoint.Hostname()
+	port := endpoint.Port()
+	if port == "" {
+		port = "443"
+		if strings.EqualFold(endpoint.Scheme, "http") {
+			port = "80"
+		}
+	}
+
+	addresses, err := resolver.LookupHost(ctx, host)
+	if err != nil {
+		return fmt.Errorf("resolve Core host %s: %w", host, err)
+	}
+	logger.Info("Startup self-test resolved Core host", "host", host, "addresses", addresses)
+
+	start := time.Now()
+	dialer := &net.Dialer{Resolver: resolver}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return fmt.Errorf("connect to Core host %s: %w", net.JoinHostPort(host, port), err)
+	}
+	_ = conn.Close()
+	logger.Info("Startup self-test reached Core host", "address", net.JoinHostPort(host, port), "duration_ms", time.Since(start).Milliseconds())
+	return nil
+}
+
+// startServers serves public on BIND_ADDRESS. internal shares that listener
+// unless ADMIN_BIND_ADDRESS gives it its own, in which case either listener
+// failing stops the other.
+func startServers(ctx context.Context, logger *slog.Logger, cfg config.Config, public, internal http.Handler) error {
+	shutdownGrace := time.Duration(cfg.ShutdownGraceSeconds) * time.Second
+	if strings.TrimSpace(cfg.AdminAddress) == "" {
+		return server.Start(ctx, cfg.Address, server.Mount(public, internal), shutdownGrace, logger)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- server.Start(ctx, cfg.Address, public, shutdownGrace, logger)
+	}()
+	go func() {
+		errs <- server.Start(ctx, strings.TrimSpace(cfg.AdminAddress), internal, shutdownGrace, logger.With("listener", "admin"))
+	}()
+
+	var firstErr error
+	for range 2 {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	return firstErr
+}
+
+func defaultUserAgent() string {
+	return "WebGuard-Instance/" + version
+}
+
+func buildInfo() server.BuildInfo {
+	info := server.BuildInfo{
+		Version: version,
+		Commit:  commit,
+		Date:    date,
+	}
+
+	if details, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range details.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			}
+		}
+	}
+
+	return info
 }
@@ -0,0 +1,15 @@
+package clock
+
+import "time"
+
+// Clock reports the current wall-clock time. Tests swap in a fixed clock to
+// check date-dependent logic at arbitrary instants.
+type Clock interface {
+	Now() time.Time
+}
+
+type Real struct{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
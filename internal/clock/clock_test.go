@@ -0,0 +1,18 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRealNowTracksWallClock(t *testing.T) {
+	t.Parallel()
+
+	before := time.Now()
+	now := Real{}.Now()
+	after := time.Now()
+
+	if now.Before(before) || now.After(after) {
+		t.Fatalf("expected %s to be between %s and %s", now, before, after)
+	}
+}
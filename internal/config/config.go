@@ -1,8 +1,24 @@
 package config
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+// Result sinks selectable through RESULT_SINK.
+const (
+	ResultSinkCore   = "core"
+	ResultSinkStdout = "stdout"
+	ResultSinkFile   = "file"
 )
 
 type Config struct {
@@ -10,34 +26,346 @@ type Config struct {
 	WebGuardCoreAPIURL string
 	WebGuardLocation   string
 
-	QueueDefaultWorkers int
+	CoreAPIRetryTimes       int
+	CoreAPIRetryBaseDelayMS int
+	CoreAPIRPS              int
+
+	CorePostFailureThreshold int
+	CorePostBatchSize        int
+
+	PostOnChangeOnly       bool
+	PostHeartbeatEveryRuns int
+
+	CoreFetchTimeoutSeconds int
+	CorePostTimeoutSeconds  int
+
+	QueueDefaultWorkers  int
+	QueueResponseWorkers int
+	QueueSSLWorkers      int
+	MaxConcurrentChecks  int
+	MaxMonitoringsPerRun int
+
+	EnabledTypes []string
+
+	HTTPRetryTimes       int
+	HTTPRetryBaseDelayMS int
+	HTTPMaxBodyBytes     int
+	HTTPUserAgent        string
+	HTTPAcceptLanguage   string
+	BypassCache          bool
+
+	HTTPKeepAliveSeconds    int
+	HTTPMaxIdleConnsPerHost int
+
+	VerifyTLS     bool
+	TLSMinVersion string
+	CABundlePath  string
+
+	HTTPProxyURL string
+	SOCKS5Proxy  string
+
+	DNSResolver string
 
-	Address string
+	BlockPrivateTargets bool
+
+	SSLExpiryWarnDays int
+
+	NotifyWebhookURL string
+
+	MonitoringIntervalSeconds int
+	PhaseTimeoutSeconds       int
+	RunMaxDurationSeconds     int
+	SchedulerJitterSeconds    int
+
+	DryRun bool
+
+	ResultSink     string
+	ResultSinkFile string
+
+	LogFormat string
+	LogLevel  string
+
+	Address          string
+	AdminAddress     string
+	HealthPathPrefix string
+
+	StartupSelfTest bool
+
+	ShutdownGraceSeconds int
+
+	AdminToken string
 }
 
+// FromEnv reads the configuration from environment variables.
 func FromEnv() Config {
-	port := env("PORT", "8080")
+	return fromSource(os.Getenv)
+}
+
+// fromSource builds the configuration from a lookup that returns the raw
+// value of a setting or "" when it is unset.
+func fromSource(lookup func(key string) string) Config {
+	port := env(lookup, "PORT", "8080")
 	return Config{
-		WebGuardCoreAPIKey: env("WEBGUARD_CORE_API_KEY", ""),
-		WebGuardCoreAPIURL: env("WEBGUARD_CORE_API_URL", ""),
-		WebGuardLocation:   env("WEBGUARD_LOCATION", ""),
+		WebGuardCoreAPIKey: env(lookup, "WEBGUARD_CORE_API_KEY", ""),
+		WebGuardCoreAPIURL: env(lookup, "WEBGUARD_CORE_API_URL", ""),
+		WebGuardLocation:   env(lookup, "WEBGUARD_LOCATION", ""),
+
+		CoreAPIRetryTimes:       envInt(lookup, "CORE_API_RETRY_TIMES", 2),
+		CoreAPIRetryBaseDelayMS: envInt(lookup, "CORE_API_RETRY_BASE_DELAY_MS", 500),
+		CoreAPIRPS:              envInt(lookup, "CORE_API_RPS", 0),
 
-		QueueDefaultWorkers: envInt("QUEUE_DEFAULT_WORKERS", 3),
+		CorePostFailureThreshold: envInt(lookup, "CORE_POST_FAILURE_THRESHOLD", 5),
+		CorePostBatchSize:        envInt(lookup, "CORE_POST_BATCH_SIZE", 0),
 
-		Address: env("BIND_ADDRESS", ":"+port),
+		PostOnChangeOnly:       envBool(lookup, "POST_ON_CHANGE_ONLY", false),
+		PostHeartbeatEveryRuns: envInt(lookup, "POST_HEARTBEAT_EVERY_RUNS", 12),
+
+		CoreFetchTimeoutSeconds: envInt(lookup, "CORE_FETCH_TIMEOUT", 30),
+		CorePostTimeoutSeconds:  envInt(lookup, "CORE_POST_TIMEOUT", 30),
+
+		QueueDefaultWorkers:  envInt(lookup, "QUEUE_DEFAULT_WORKERS", 3),
+		QueueResponseWorkers: envInt(lookup, "QUEUE_RESPONSE_WORKERS", 0),
+		QueueSSLWorkers:      envInt(lookup, "QUEUE_SSL_WORKERS", 0),
+		MaxConcurrentChecks:  envInt(lookup, "MAX_CONCURRENT_CHECKS", 0),
+		MaxMonitoringsPerRun: envInt(lookup, "MAX_MONITORINGS_PER_RUN", 0),
+
+		EnabledTypes: envList(lookup, "ENABLED_TYPES"),
+
+		HTTPRetryTimes:       envInt(lookup, "HTTP_RETRY_TIMES", 1),
+		HTTPRetryBaseDelayMS: envInt(lookup, "HTTP_RETRY_BASE_DELAY_MS", 250),
+		HTTPMaxBodyBytes:     envInt(lookup, "HTTP_MAX_BODY_BYTES", 5*1024*1024),
+		HTTPUserAgent:        env(lookup, "HTTP_USER_AGENT", ""),
+		HTTPAcceptLanguage:   env(lookup, "HTTP_ACCEPT_LANGUAGE", ""),
+		BypassCache:          envBool(lookup, "BYPASS_CACHE", false),
+
+		HTTPKeepAliveSeconds:    envInt(lookup, "HTTP_KEEP_ALIVE_SECONDS", 30),
+		HTTPMaxIdleConnsPerHost: envInt(lookup, "HTTP_MAX_IDLE_CONNS_PER_HOST", 2),
+
+		VerifyTLS:     envBool(lookup, "VERIFY_TLS", false),
+		TLSMinVersion: env(lookup, "TLS_MIN_VERSION", ""),
+		CABundlePath:  env(lookup, "CA_BUNDLE_PATH", ""),
+
+		HTTPProxyURL: env(lookup, "HTTP_PROXY_URL", ""),
+		SOCKS5Proxy:  env(lookup, "SOCKS5_PROXY", ""),
+
+		DNSResolver: env(lookup, "DNS_RESOLVER", ""),
+
+		BlockPrivateTargets: envBool(lookup, "BLOCK_PRIVATE_TARGETS", false),
+
+		SSLExpiryWarnDays: envInt(lookup, "SSL_EXPIRY_WARN_DAYS", 14),
+
+		NotifyWebhookURL: env(lookup, "NOTIFY_WEBHOOK_URL", ""),
+
+		MonitoringIntervalSeconds: envInt(lookup, "MONITORING_INTERVAL_SECONDS", 300),
+		PhaseTimeoutSeconds:       envInt(lookup, "PHASE_TIMEOUT_SECONDS", 0),
+		RunMaxDurationSeconds:     envInt(lookup, "RUN_MAX_DURATION_SECONDS", 0),
+		SchedulerJitterSeconds:    envInt(lookup, "SCHEDULER_JITTER_SECONDS", 0),
+
+		DryRun: envBool(lookup, "DRY_RUN", false),
+
+		ResultSink:     env(lookup, "RESULT_SINK", ResultSinkCore),
+		ResultSinkFile: env(lookup, "RESULT_SINK_FILE", ""),
+
+		LogFormat: env(lookup, "LOG_FORMAT", "text"),
+		LogLevel:  env(lookup, "LOG_LEVEL", "info"),
+
+		Address:          env(lookup, "BIND_ADDRESS", ":"+port),
+		AdminAddress:     env(lookup, "ADMIN_BIND_ADDRESS", ""),
+		HealthPathPrefix: env(lookup, "HEALTH_PATH_PREFIX", ""),
+
+		StartupSelfTest: envBool(lookup, "STARTUP_SELFTEST", false),
+
+		ShutdownGraceSeconds: envInt(lookup, "SHUTDOWN_GRACE_SECONDS", 5),
+
+		AdminToken: env(lookup, "ADMIN_TOKEN", ""),
 	}
 }
 
-func env(key, fallback string) string {
-	value := os.Getenv(key)
+// Validate reports every setting that would keep the instance from talking to
+// Core or running checks. All problems are returned together.
+func (c Config) Validate() error {
+	var problems []error
+
+	if strings.TrimSpace(c.WebGuardCoreAPIKey) == "" {
+		problems = append(problems, errors.New("WEBGUARD_CORE_API_KEY is required"))
+	}
+	if strings.TrimSpace(c.WebGuardLocation) == "" {
+		problems = append(problems, errors.New("WEBGUARD_LOCATION is required"))
+	}
+	if strings.TrimSpace(c.WebGuardCoreAPIURL) == "" {
+		problems = append(problems, errors.New("WEBGUARD_CORE_API_URL is required"))
+	} else if endpoint, err := url.Parse(c.WebGuardCoreAPIURL); err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		problems = append(problems, fmt.Errorf("WEBGUARD_CORE_API_URL must be an absolute http(s) URL, got %q", c.WebGuardCoreAPIURL))
+	}
+
+	if c.QueueDefaultWorkers < 1 {
+		problems = append(problems, fmt.Errorf("QUEUE_DEFAULT_WORKERS must be at least 1, got %d", c.QueueDefaultWorkers))
+	}
+	if c.MonitoringIntervalSeconds < 1 {
+		problems = append(problems, fmt.Errorf("MONITORING_INTERVAL_SECONDS must be at least 1, got %d", c.MonitoringIntervalSeconds))
+	}
+	if c.HTTPMaxBodyBytes < 1 {
+		problems = append(problems, fmt.Errorf("HTTP_MAX_BODY_BYTES must be at least 1, got %d", c.HTTPMaxBodyBytes))
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value int
+	}{
+		{name: "CORE_API_RETRY_TIMES", value: c.CoreAPIRetryTimes},
+		{name: "CORE_API_RETRY_BASE_DELAY_MS", value: c.CoreAPIRetryBaseDelayMS},
+		{name: "CORE_API_RPS", value: c.CoreAPIRPS},
+		{name: "CORE_POST_FAILURE_THRESHOLD", value: c.CorePostFailureThreshold},
+		{name: "CORE_POST_BATCH_SIZE", value: c.CorePostBatchSize},
+		{name: "POST_HEARTBEAT_EVERY_RUNS", value: c.PostHeartbeatEveryRuns},
+		{name: "CORE_FETCH_TIMEOUT", value: c.CoreFetchTimeoutSeconds},
+		{name: "CORE_POST_TIMEOUT", value: c.CorePostTimeoutSeconds},
+		{name: "QUEUE_RESPONSE_WORKERS", value: c.QueueResponseWorkers},
+		{name: "QUEUE_SSL_WORKERS", value: c.QueueSSLWorkers},
+		{name: "MAX_CONCURRENT_CHECKS", value: c.MaxConcurrentChecks},
+		{name: "MAX_MONITORINGS_PER_RUN", value: c.MaxMonitoringsPerRun},
+		{name: "HTTP_RETRY_TIMES", value: c.HTTPRetryTimes},
+		{name: "HTTP_RETRY_BASE_DELAY_MS", value: c.HTTPRetryBaseDelayMS},
+		{name: "HTTP_KEEP_ALIVE_SECONDS", value: c.HTTPKeepAliveSeconds},
+		{name: "HTTP_MAX_IDLE_CONNS_PER_HOST", value: c.HTTPMaxIdleConnsPerHost},
+		{name: "SSL_EXPIRY_WARN_DAYS", value: c.SSLExpiryWarnDays},
+		{name: "PHASE_TIMEOUT_SECONDS", value: c.PhaseTimeoutSeconds},
+		{name: "RUN_MAX_DURATION_SECONDS", value: c.RunMaxDurationSeconds},
+		{name: "SCHEDULER_JITTER_SECONDS", value: c.SchedulerJitterSeconds},
+		{name: "SHUTDOWN_GRACE_SECONDS", value: c.ShutdownGraceSeconds},
+	} {
+		if setting.value < 0 {
+			problems = append(problems, fmt.Errorf("%s must not be negative, got %d", setting.name, setting.value))
+		}
+	}
+
+	for _, enabledType := range c.EnabledTypes {
+		if !slices.Contains(monitor.CheckedTypes, monitor.Type(enabledType)) {
+			problems = append(problems, fmt.Errorf("ENABLED_TYPES must only list %s, got %q", joinTypes(monitor.CheckedTypes), enabledType))
+		}
+	}
+
+	if proxyURL := strings.TrimSpace(c.HTTPProxyURL); proxyURL != "" {
+		if endpoint, err := url.Parse(proxyURL); err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+			problems = append(problems, fmt.Errorf("HTTP_PROXY_URL must be an absolute URL, got %q", c.HTTPProxyURL))
+		}
+	}
+	if _, err := SOCKS5Proxy(c.SOCKS5Proxy); err != nil {
+		problems = append(problems, err)
+	}
+
+	if _, err := TLSVersion(c.TLSMinVersion); err != nil {
+		problems = append(problems, err)
+	}
+	if _, err := LoadCABundle(c.CABundlePath); err != nil {
+		problems = append(problems, err)
+	}
+
+	if webhookURL := strings.TrimSpace(c.NotifyWebhookURL); webhookURL != "" {
+		if endpoint, err := url.Parse(webhookURL); err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			problems = append(problems, fmt.Errorf("NOTIFY_WEBHOOK_URL must be an absolute http(s) URL, got %q", c.NotifyWebhookURL))
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(c.ResultSink)) {
+	case "", ResultSinkCore, ResultSinkStdout:
+	case ResultSinkFile:
+		if strings.TrimSpace(c.ResultSinkFile) == "" {
+			problems = append(problems, errors.New("RESULT_SINK_FILE is required when RESULT_SINK is file"))
+		}
+	default:
+		problems = append(problems, fmt.Errorf("RESULT_SINK must be core, stdout or file, got %q", c.ResultSink))
+	}
+
+	if prefix := strings.TrimSpace(c.HealthPathPrefix); prefix != "" && !strings.HasPrefix(prefix, "/") {
+		problems = append(problems, fmt.Errorf("HEALTH_PATH_PREFIX must start with /, got %q", c.HealthPathPrefix))
+	}
+	if adminAddress := strings.TrimSpace(c.AdminAddress); adminAddress != "" && adminAddress == strings.TrimSpace(c.Address) {
+		problems = append(problems, fmt.Errorf("ADMIN_BIND_ADDRESS must differ from BIND_ADDRESS, got %q for both", c.AdminAddress))
+	}
+
+	switch strings.ToLower(strings.TrimSpace(c.LogFormat)) {
+	case "", "text", "json":
+	default:
+		problems = append(problems, fmt.Errorf("LOG_FORMAT must be text or json, got %q", c.LogFormat))
+	}
+
+	switch strings.ToLower(strings.TrimSpace(c.LogLevel)) {
+	case "", "debug", "info", "warn", "error":
+	default:
+		problems = append(problems, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+
+	return errors.Join(problems...)
+}
+
+// TLSVersion maps a TLS_MIN_VERSION value such as "1.2" to its crypto/tls
+// constant. An empty value returns 0, which keeps the Go default.
+func TLSVersion(raw string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(raw)), "tls") {
+	case "":
+		return 0, nil
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("TLS_MIN_VERSION must be 1.0, 1.1, 1.2 or 1.3, got %q", raw)
+	}
+}
+
+// SOCKS5Proxy parses a SOCKS5_PROXY value, either host:port or
+// socks5[h]://[user:password@]host:port. A bare host:port is taken as
+// socks5h, so the proxy resolves hostnames. An empty value returns nil,
+// which connects to targets directly.
+func SOCKS5Proxy(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "socks5h://" + raw
+	}
+	proxyURL, err := url.Parse(raw)
+	if err != nil || (proxyURL.Scheme != "socks5" && proxyURL.Scheme != "socks5h") || proxyURL.Hostname() == "" || proxyURL.Port() == "" {
+		return nil, fmt.Errorf("SOCKS5_PROXY must be host:port or socks5[h]://[user:password@]host:port, got %q", raw)
+	}
+	return proxyURL, nil
+}
+
+// LoadCABundle reads the PEM certificates at CA_BUNDLE_PATH into a pool. An
+// empty path returns a nil pool, which makes crypto/tls use the system roots.
+func LoadCABundle(path string) (*x509.CertPool, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, nil
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("CA_BUNDLE_PATH could not be read: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(contents) {
+		return nil, fmt.Errorf("CA_BUNDLE_PATH %q contains no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+func env(lookup func(string) string, key, fallback string) string {
+	value := lookup(key)
 	if value == "" {
 		return fallback
 	}
 	return value
 }
 
-func envInt(key string, fallback int) int {
-	raw := os.Getenv(key)
+func envInt(lookup func(string) string, key string, fallback int) int {
+	raw := lookup(key)
 	if raw == "" {
 		return fallback
 	}
@@ -47,3 +375,35 @@ func envInt(key string, fallback int) int {
 	}
 	return value
 }
+
+// envList splits a comma separated value into lower-cased entries, dropping
+// empty ones. An unset value returns nil.
+func envList(lookup func(string) string, key string) []string {
+	var values []string
+	for _, value := range strings.Split(lookup(key), ",") {
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func envBool(lookup func(string) string, key string, fallback bool) bool {
+	raw := strings.ToLower(strings.TrimSpace(lookup(key)))
+	switch raw {
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	default:
+		return fallback
+	}
+}
+
+func joinTypes(types []monitor.Type) string {
+	names := make([]string, len(types))
+	for index, monitoringType := range types {
+		names[index] = string(monitoringType)
+	}
+	return strings.Join(names, ", ")
+}
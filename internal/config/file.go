@@ -0,0 +1,121 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads the configuration file at path and merges it with the
+// environment. Keys are the environment variable names, matched
+// case-insensitively, and a non-empty environment variable takes precedence
+// over the file. Files ending in .json are parsed as a JSON object; anything
+// else is read as a YAML mapping of scalar values.
+func Load(path string) (Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("read config file: %w", err)
+	}
+
+	var values map[string]string
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		values, err = parseJSONFile(content)
+	} else {
+		values, err = parseYAMLFile(content)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	known := knownKeys()
+	var unknown []string
+	for key := range values {
+		if !slices.Contains(known, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return Config{}, fmt.Errorf("config file %s has unknown keys: %s", path, strings.Join(unknown, ", "))
+	}
+
+	return fromSource(func(key string) string {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+		return values[key]
+	}), nil
+}
+
+// knownKeys lists every setting fromSource reads.
+func knownKeys() []string {
+	var keys []string
+	fromSource(func(key string) string {
+		keys = append(keys, key)
+		return ""
+	})
+	return keys
+}
+
+func parseJSONFile(content []byte) (map[string]string, error) {
+	var raw map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		switch typed := value.(type) {
+		case string:
+			values[strings.ToUpper(key)] = typed
+		case json.Number:
+			values[strings.ToUpper(key)] = typed.String()
+		case bool:
+			values[strings.ToUpper(key)] = strconv.FormatBool(typed)
+		case nil:
+			values[strings.ToUpper(key)] = ""
+		default:
+			return nil, fmt.Errorf("key %s must be a string, number or boolean", key)
+		}
+	}
+	return values, nil
+}
+
+// parseYAMLFile reads a YAML mapping of settings. Values must be scalars and
+// are kept as written, so "0.10" stays "0.10" instead of becoming a float.
+func parseYAMLFile(content []byte) (map[string]string, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if len(document.Content) == 0 {
+		return values, nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected \"key: value\" pairs", root.Line)
+	}
+	for index := 0; index+1 < len(root.Content); index += 2 {
+		key, value := root.Content[index], root.Content[index+1]
+		if value.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("line %d: nested values are not supported", value.Line)
+		}
+		if value.Tag == "!!null" {
+			values[strings.ToUpper(key.Value)] = ""
+			continue
+		}
+		values[strings.ToUpper(key.Value)] = value.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadYAMLFile(t *testing.T) {
+	t.Setenv("WEBGUARD_LOCATION", "")
+	t.Setenv("WEBGUARD_CORE_API_URL", "")
+	t.Setenv("QUEUE_DEFAULT_WORKERS", "")
+	t.Setenv("DRY_RUN", "")
+	t.Setenv("HTTP_USER_AGENT", "")
+
+	path := writeConfigFile(t, "config.yaml", `# WebGuard instance
+---
+WEBGUARD_LOCATION: de-1
+webguard_core_api_url: "https://core.example.com"
+QUEUE_DEFAULT_WORKERS: 8 # more workers for this node
+DRY_RUN: true
+HTTP_USER_AGENT: 'Probe ''A'''
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.WebGuardLocation != "de-1" {
+		t.Fatalf("expected location de-1, got %q", cfg.WebGuardLocation)
+	}
+	if cfg.WebGuardCoreAPIURL != "https://core.example.com" {
+		t.Fatalf("expected core api url from lowercase key, got %q", cfg.WebGuardCoreAPIURL)
+	}
+	if cfg.QueueDefaultWorkers != 8 {
+		t.Fatalf("expected workers 8, got %d", cfg.QueueDefaultWorkers)
+	}
+	if !cfg.DryRun {
+		t.Fatalf("expected dry run to be enabled")
+	}
+	if cfg.HTTPUserAgent != "Probe 'A'" {
+		t.Fatalf("expected user agent Probe 'A', got %q", cfg.HTTPUserAgent)
+	}
+	if cfg.MonitoringIntervalSeconds != 300 {
+		t.Fatalf("expected unset keys to keep their defaults, got interval %d", cfg.MonitoringIntervalSeconds)
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	t.Setenv("WEBGUARD_LOCATION", "us-1")
+	t.Setenv("QUEUE_DEFAULT_WORKERS", "")
+
+	path := writeConfigFile(t, "config.yml", "WEBGUARD_LOCATION: de-1\nQUEUE_DEFAULT_WORKERS: 8\n")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.WebGuardLocation != "us-1" {
+		t.Fatalf("expected env location us-1 to win, got %q", cfg.WebGuardLocation)
+	}
+	if cfg.QueueDefaultWorkers != 8 {
+		t.Fatalf("expected workers 8 from the file, got %d", cfg.QueueDefaultWorkers)
+	}
+}
+
+func TestLoadJSONFile(t *testing.T) {
+	t.Setenv("MONITORING_INTERVAL_SECONDS", "")
+	t.Setenv("VERIFY_TLS", "")
+	t.Setenv("LOG_FORMAT", "")
+
+	path := writeConfigFile(t, "config.json", `{"MONITORING_INTERVAL_SECONDS": 60, "verify_tls": true, "LOG_FORMAT": "json"}`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MonitoringIntervalSeconds != 60 {
+		t.Fatalf("expected interval 60, got %d", cfg.MonitoringIntervalSeconds)
+	}
+	if !cfg.VerifyTLS {
+		t.Fatalf("expected verify tls to be enabled")
+	}
+	if cfg.LogFormat != "json" {
+		t.Fatalf("expected log format json, got %q", cfg.LogFormat)
+	}
+}
+
+func TestLoadRejectsInvalidFiles(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     string
+		content  string
+		expected string
+	}{
+		{name: "unknown yaml key", file: "config.yaml", content: "WEBGUARD_LOCATION: de-1\nQUEUE_WORKERS: 3\n", expected: "unknown keys: QUEUE_WORKERS"},
+		{name: "unknown json key", file: "config.json", content: `{"LOCATION": "de-1"}`, expected: "unknown keys: LOCATION"},
+		{name: "nested yaml", file: "config.yaml", content: "core:\n  url: https://core.example.com\n", expected: "nested values are not supported"},
+		{name: "flow sequence", file: "config.yaml", content: "WEBGUARD_LOCATION: [de-1, us-1]\n", expected: "nested values are not supported"},
+		{name: "missing separator", file: "config.yaml", content: "WEBGUARD_LOCATION de-1\n", expected: "expected \"key: value\" pairs"},
+		{name: "malformed yaml", file: "config.yaml", content: "WEBGUARD_LOCATION: \"de-1\n", expected: "parse config file"},
+		{name: "json object value", file: "config.json", content: `{"DNS_RESOLVER": {"host": "8.8.8.8"}}`, expected: "must be a string, number or boolean"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Load(writeConfigFile(t, testCase.file, testCase.content))
+			if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+				t.Fatalf("expected error containing %q, got %v", testCase.expected, err)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err == nil {
+		t.Fatalf("expected error for missing config file")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+	"golang.org/x/time/rate"
 )
 
 type Client struct {
@@ -19,8 +20,18 @@ type Client struct {
 	apiKey       string
 	instanceCode string
 	httpClient   *http.Client
+
+	retryTimes     int
+	retryBaseDelay time.Duration
+
+	limiter *rate.Limiter
+
+	fetchTimeout time.Duration
+	postTimeout  time.Duration
 }
 
+const defaultRequestTimeout = 30 * time.Second
+
 type HTTPStatusError struct {
 	StatusCode int
 	Body       string
@@ -35,9 +46,10 @@ func NewClient(baseURL, apiKey, instanceCode string) *Client {
 		baseURL:      strings.TrimRight(baseURL, "/"),
 		apiKey:       strings.TrimSpace(apiKey),
 		instanceCode: strings.TrimSpace(instanceCode),
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		httpClient:   &http.Client{},
+		limiter:      rate.NewLimiter(rate.Inf, 0),
+		fetchTimeout: defaultRequestTimeout,
+		postTimeout:  defaultRequestTimeout,
 	}
 }
 
@@ -48,6 +60,34 @@ func (c *Client) SetHTTPClient(httpClient *http.Client) {
 	c.httpClient = httpClient
 }
 
+func (c *Client) SetRetry(times int, baseDelay time.Duration) {
+	c.retryTimes = max(0, times)
+	c.retryBaseDelay = max(0, baseDelay)
+}
+
+// SetRateLimit spaces requests so at most rps start per second. The burst
+// of one lets requests through evenly instead of in bunches. Retries count
+// as requests. A non-positive rps removes the limit.
+func (c *Client) SetRateLimit(rps int) {
+	if rps <= 0 {
+		c.limiter = rate.NewLimiter(rate.Inf, 0)
+		return
+	}
+	c.limiter = rate.NewLimiter(rate.Limit(rps), 1)
+}
+
+// SetTimeouts bounds each attempt of a fetch (GET and HEAD) and of a result
+// post separately, so a slow post does not hold a worker as long as a large
+// fetch may take. Non-positive values keep the 30 second default.
+func (c *Client) SetTimeouts(fetch, post time.Duration) {
+	if fetch > 0 {
+		c.fetchTimeout = fetch
+	}
+	if post > 0 {
+		c.postTimeout = post
+	}
+}
+
 func (c *Client) GetMonitorings(ctx context.Context, location string, types []monitor.Type) ([]monitor.Monitoring, error) {
 	location = strings.TrimSpace(location)
 	if location == "" {
@@ -60,36 +100,55 @@ func (c *Client) GetMonitorings(ctx context.Context, location string, types []mo
 		return nil, fmt.Errorf("location must match instance code")
 	}
 
-	if len(types) == 0 {
+	wanted := make(map[monitor.Type]struct{}, len(types))
+	for _, monitoringType := range types {
+		wanted[monitoringType] = struct{}{}
+	}
+	switch len(wanted) {
+	case 0:
 		return c.getMonitorings(ctx, location, "")
+	case 1:
+		return c.getMonitorings(ctx, location, types[0])
 	}
 
-	seenTypes := make(map[monitor.Type]struct{}, len(types))
-	seenMonitorings := make(map[string]struct{})
-	monitorings := make([]monitor.Monitoring, 0)
-
-	for _, monitoringType := range types {
-		if _, ok := seenTypes[monitoringType]; ok {
+	// The type query takes a single type, so several types are fetched in
+	// one unfiltered request and filtered here.
+	items, err := c.getMonitorings(ctx, location, "")
+	if err != nil {
+		return nil, err
+	}
+	seenMonitorings := make(map[string]struct{}, len(items))
+	monitorings := make([]monitor.Monitoring, 0, len(items))
+	for _, item := range items {
+		if _, ok := wanted[item.Type]; !ok {
 			continue
 		}
-		seenTypes[monitoringType] = struct{}{}
-
-		items, err := c.getMonitorings(ctx, location, monitoringType)
-		if err != nil {
-			return nil, err
-		}
-		for _, item := range items {
-			if _, ok := seenMonitorings[item.ID]; ok {
-				continue
-			}
-			seenMonitorings[item.ID] = struct{}{}
-			monitorings = append(monitorings, item)
+		if _, ok := seenMonitorings[item.ID]; ok {
+			continue
 		}
+		seenMonitorings[item.ID] = struct{}{}
+		monitorings = append(monitorings, item)
 	}
 
 	return monitorings, nil
 }
 
+func (c *Client) Ping(ctx context.Context) error {
+	if c.instanceCode == "" {
+		return fmt.Errorf("WEBGUARD_LOCATION is empty")
+	}
+
+	query := make(url.Values)
+	query.Set("location", c.instanceCode)
+
+	request, err := c.newRequest(ctx, http.MethodHead, "/api/v1/internal/monitorings", query, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.doJSON(request, nil)
+}
+
 func (c *Client) getMonitorings(ctx context.Context, location string, monitoringType monitor.Type) ([]monitor.Monitoring, error) {
 	query := make(url.Values)
 	query.Set("location", location)
@@ -127,6 +186,26 @@ func (c *Client) PostSSLResult(ctx context.Context, payload monitor.SSLResultPay
 	return c.doJSON(request, nil)
 }
 
+// PostMonitoringResponses posts several response results in one request.
+func (c *Client) PostMonitoringResponses(ctx context.Context, payloads []monitor.MonitoringResponsePayload) error {
+	request, err := c.newRequest(ctx, http.MethodPost, "/api/v1/internal/monitoring-responses/batch", nil, payloads)
+	if err != nil {
+		return err
+	}
+
+	return c.doJSON(request, nil)
+}
+
+// PostSSLResults posts several SSL results in one request.
+func (c *Client) PostSSLResults(ctx context.Context, payloads []monitor.SSLResultPayload) error {
+	request, err := c.newRequest(ctx, http.MethodPost, "/api/v1/internal/ssl-results/batch", nil, payloads)
+	if err != nil {
+		return err
+	}
+
+	return c.doJSON(request, nil)
+}
+
 func (c *Client) PostDomainResult(ctx context.Context, payload monitor.DomainResultPayload) error {
 	request, err := c.newRequest(ctx, http.MethodPost, "/api/v1/internal/domain-results", nil, payload)
 	if err != nil {
@@ -136,6 +215,15 @@ func (c *Client) PostDomainResult(ctx context.Context, payload monitor.DomainRes
 	return c.doJSON(request, nil)
 }
 
+func (c *Client) PostRunSummary(ctx context.Context, payload monitor.RunSummaryPayload) error {
+	request, err := c.newRequest(ctx, http.MethodPost, "/api/v1/internal/run-summaries", nil, payload)
+	if err != nil {
+		return err
+	}
+
+	return c.doJSON(request, nil)
+}
+
 func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body any) (*http.Request, error) {
 	if c.baseURL == "" {
 		return nil, fmt.Errorf("WEBGUARD_CORE_API_URL is empty")
@@ -177,27 +265,97 @@ func (c *Client) newRequest(ctx context.Context, method, path string, query url.
 }
 
 func (c *Client) doJSON(request *http.Request, out any) error {
-	response, err := c.httpClient.Do(request)
+	ctx := request.Context()
+
+	var lastErr error
+	for attempt := 0; attempt <= c.retryTimes; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, c.retryBaseDelay<<(attempt-1)); err != nil {
+				return lastErr
+			}
+
+			retryRequest, err := rewindRequest(request)
+			if err != nil {
+				return lastErr
+			}
+			request = retryRequest
+		}
+
+		if err := c.limiter.Wait(ctx); err != nil {
+			if lastErr != nil {
+				return lastErr
+			}
+			return err
+		}
+		retryable, err := c.doJSONOnce(request, out)
+		if err == nil || !retryable || ctx.Err() != nil {
+			return err
+		}
+		lastErr = err
+	}
+
+	return lastErr
+}
+
+func (c *Client) doJSONOnce(request *http.Request, out any) (bool, error) {
+	ctx, cancel := context.WithTimeout(request.Context(), c.requestTimeout(request.Method))
+	defer cancel()
+
+	response, err := c.httpClient.Do(request.WithContext(ctx))
 	if err != nil {
-		return err
+		return true, err
 	}
 	defer response.Body.Close()
 
 	raw, err := io.ReadAll(response.Body)
 	if err != nil {
-		return err
+		return true, err
 	}
 
 	if response.StatusCode >= http.StatusBadRequest {
-		return &HTTPStatusError{
+		return response.StatusCode >= http.StatusInternalServerError, &HTTPStatusError{
 			StatusCode: response.StatusCode,
 			Body:       string(raw),
 		}
 	}
 
 	if out == nil || len(raw) == 0 {
-		return nil
+		return false, nil
 	}
 
-	return json.Unmarshal(raw, out)
+	return false, json.Unmarshal(raw, out)
+}
+
+func (c *Client) requestTimeout(method string) time.Duration {
+	if method == http.MethodGet || method == http.MethodHead {
+		return c.fetchTimeout
+	}
+	return c.postTimeout
+}
+
+func rewindRequest(request *http.Request) (*http.Request, error) {
+	retryRequest := request.Clone(request.Context())
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retryRequest.Body = body
+	}
+	return retryRequest, nil
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
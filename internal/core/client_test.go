@@ -4,8 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -60,33 +65,30 @@ func TestGetMonitoringsIncludesHeadersAndQuery(t *testing.T) {
 	}
 }
 
-func TestGetMonitoringsWithMultipleTypesFetchesAndMerges(t *testing.T) {
+func TestGetMonitoringsWithMultipleTypesFetchesOnceAndFilters(t *testing.T) {
 	t.Parallel()
 
-	requestedTypes := make([]string, 0)
+	var requests atomic.Int32
+	var requestedTypes []string
 
 	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests.Add(1)
 		if request.Header.Get("X-INSTANCE-CODE") != "de-1" {
-			t.Fatalf("missing/invalid X-INSTANCE-CODE header: %q", request.Header.Get("X-INSTANCE-CODE"))
+			t.Errorf("missing/invalid X-INSTANCE-CODE header: %q", request.Header.Get("X-INSTANCE-CODE"))
 		}
 		if request.URL.Query().Get("location") != "de-1" {
-			t.Fatalf("expected location=de-1, got %q", request.URL.Query().Get("location"))
+			t.Errorf("expected location=de-1, got %q", request.URL.Query().Get("location"))
 		}
-
-		monitoringType := request.URL.Query().Get("type")
-		requestedTypes = append(requestedTypes, monitoringType)
+		requestedTypes = append(requestedTypes, request.URL.Query().Get("type"))
 
 		writer.Header().Set("Content-Type", "application/json")
-		switch monitoringType {
-		case "http":
-			_, _ = writer.Write([]byte(`[{"id":"shared","type":"http","target":"https://example.com","timeout":5},{"id":"http-only","type":"http","target":"https://example.com","timeout":5}]`))
-		case "keyword":
-			_, _ = writer.Write([]byte(`[{"id":"shared","type":"keyword","target":"https://example.com","timeout":5}]`))
-		case "port":
-			_, _ = writer.Write([]byte(`[{"id":"port-only","type":"port","target":"example.com","port":443}]`))
-		default:
-			t.Fatalf("unexpected type query: %q", monitoringType)
-		}
+		_, _ = writer.Write([]byte(`[
+			{"id":"shared","type":"http","target":"https://example.com","timeout":5},
+			{"id":"http-only","type":"http","target":"https://example.com","timeout":5},
+			{"id":"shared","type":"keyword","target":"https://example.com","timeout":5},
+			{"id":"port-only","type":"port","target":"example.com","port":443},
+			{"id":"dns-only","type":"dns","target":"example.com"}
+		]`))
 	}))
 	defer server.Close()
 
@@ -101,25 +103,19 @@ func TestGetMonitoringsWithMultipleTypesFetchesAndMerges(t *testing.T) {
 		t.Fatalf("GetMonitorings failed: %v", err)
 	}
 
-	if len(requestedTypes) != 3 {
-		t.Fatalf("expected 3 unique type requests, got %d (%v)", len(requestedTypes), requestedTypes)
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("expected 1 request to Core, got %d", got)
+	}
+	if requestedTypes[0] != "" {
+		t.Fatalf("expected no type filter on the combined fetch, got %q", requestedTypes[0])
 	}
 
-	ids := make(map[string]struct{}, len(monitorings))
+	ids := make([]string, 0, len(monitorings))
 	for _, item := range monitorings {
-		ids[item.ID] = struct{}{}
-	}
-	if len(ids) != 3 {
-		t.Fatalf("expected 3 unique monitorings, got %d (%#v)", len(ids), ids)
+		ids = append(ids, item.ID)
 	}
-	if _, ok := ids["shared"]; !ok {
-		t.Fatalf("expected merged result to contain shared id")
-	}
-	if _, ok := ids["http-only"]; !ok {
-		t.Fatalf("expected merged result to contain http-only id")
-	}
-	if _, ok := ids["port-only"]; !ok {
-		t.Fatalf("expected merged result to contain port-only id")
+	if !slices.Equal(ids, []string{"shared", "http-only", "port-only"}) {
+		t.Fatalf("expected the requested types without duplicates, got %v", ids)
 	}
 }
 
@@ -273,6 +269,42 @@ func TestPostSSLResultPayloadShape(t *testing.T) {
 	}
 }
 
+func TestPostBatchedResultsPayloadShape(t *testing.T) {
+	t.Parallel()
+
+	bodies := make(map[string][]map[string]any)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var body []map[string]any
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode payload: %v", err)
+		}
+		bodies[request.URL.Path] = body
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-key", "de-1")
+	err := client.PostMonitoringResponses(context.Background(), []monitor.MonitoringResponsePayload{
+		{MonitoringID: "1", Status: monitor.StatusUp},
+		{MonitoringID: "2", Status: monitor.StatusDown},
+	})
+	if err != nil {
+		t.Fatalf("PostMonitoringResponses failed: %v", err)
+	}
+	if err := client.PostSSLResults(context.Background(), []monitor.SSLResultPayload{{MonitoringID: "3", IsValid: true}}); err != nil {
+		t.Fatalf("PostSSLResults failed: %v", err)
+	}
+
+	responses := bodies["/api/v1/internal/monitoring-responses/batch"]
+	if len(responses) != 2 || responses[0]["monitoring_id"] != "1" || responses[1]["status"] != "down" {
+		t.Fatalf("unexpected batched responses: %#v", responses)
+	}
+	sslResults := bodies["/api/v1/internal/ssl-results/batch"]
+	if len(sslResults) != 1 || sslResults[0]["monitoring_id"] != "3" || sslResults[0]["is_valid"] != true {
+		t.Fatalf("unexpected batched SSL results: %#v", sslResults)
+	}
+}
+
 func TestPostDomainResultPayloadShape(t *testing.T) {
 	t.Parallel()
 
@@ -326,6 +358,55 @@ func TestPostDomainResultPayloadShape(t *testing.T) {
 	}
 }
 
+func TestPostRunSummaryPayloadShape(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			t.Fatalf("expected POST, got %s", request.Method)
+		}
+		if request.URL.Path != "/api/v1/internal/run-summaries" {
+			t.Fatalf("unexpected path: %s", request.URL.Path)
+		}
+		if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode payload: %v", err)
+		}
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-key", "de-1")
+	err := client.PostRunSummary(context.Background(), monitor.RunSummaryPayload{
+		StartedAt:          time.Date(2026, 4, 24, 12, 0, 0, 0, time.UTC),
+		DurationMs:         1500,
+		Checks:             10,
+		Up:                 7,
+		Down:               2,
+		Unknown:            1,
+		SkippedMaintenance: 3,
+	})
+	if err != nil {
+		t.Fatalf("PostRunSummary failed: %v", err)
+	}
+
+	expected := map[string]any{
+		"started_at":          "2026-04-24T12:00:00Z",
+		"duration_ms":         float64(1500),
+		"checks":              float64(10),
+		"up":                  float64(7),
+		"down":                float64(2),
+		"unknown":             float64(1),
+		"skipped_maintenance": float64(3),
+	}
+	for key, value := range expected {
+		if body[key] != value {
+			t.Fatalf("expected %s=%v, got %#v", key, value, body[key])
+		}
+	}
+}
+
 func TestGetMonitoringsReturnsStatusError(t *testing.T) {
 	t.Parallel()
 
@@ -400,3 +481,330 @@ func ptr(value string) *string {
 func intPtr(value int) *int {
 	return &value
 }
+
+func TestPingSendsAuthenticatedHeadRequest(t *testing.T) {
+	t.Parallel()
+
+	var gotMethod string
+	var gotLocation string
+	var gotAPIKey string
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		gotMethod = request.Method
+		gotLocation = request.URL.Query().Get("location")
+		gotAPIKey = request.Header.Get("X-API-KEY")
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-key", "de-1")
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+
+	if gotMethod != http.MethodHead {
+		t.Fatalf("expected HEAD, got %s", gotMethod)
+	}
+	if gotLocation != "de-1" {
+		t.Fatalf("expected location=de-1, got %q", gotLocation)
+	}
+	if gotAPIKey != "secret-key" {
+		t.Fatalf("expected api key secret-key, got %q", gotAPIKey)
+	}
+}
+
+func TestPingReturnsStatusError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-key", "de-1")
+	err := client.Ping(context.Background())
+
+	var statusError *HTTPStatusError
+	if !errors.As(err, &statusError) || statusError.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected HTTPStatusError 401, got %v", err)
+	}
+}
+
+func TestGetMonitoringsRetriesTransientFailures(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if attempts.Add(1) <= 2 {
+			writer.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`[{"id":"1","type":"http"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-key", "de-1")
+	client.SetRetry(2, time.Millisecond)
+
+	monitorings, err := client.GetMonitorings(context.Background(), "de-1", nil)
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if len(monitorings) != 1 {
+		t.Fatalf("expected 1 monitoring, got %d", len(monitorings))
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestPostMonitoringResponseRetriesNetworkErrorsWithBody(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	var lastBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if attempts.Add(1) <= 2 {
+			connection, _, err := writer.(http.Hijacker).Hijack()
+			if err == nil {
+				_ = connection.Close()
+			}
+			return
+		}
+		lastBody, _ = io.ReadAll(request.Body)
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-key", "de-1")
+	client.SetRetry(2, time.Millisecond)
+
+	err := client.PostMonitoringResponse(context.Background(), monitor.MonitoringResponsePayload{
+		MonitoringID: "42",
+		Status:       monitor.StatusUp,
+	})
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+	if !strings.Contains(string(lastBody), `"monitoring_id":"42"`) {
+		t.Fatalf("expected request body to be resent, got %q", string(lastBody))
+	}
+}
+
+func TestGetMonitoringsDoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts.Add(1)
+		writer.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-key", "de-1")
+	client.SetRetry(3, time.Millisecond)
+
+	_, err := client.GetMonitorings(context.Background(), "de-1", nil)
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 status error, got %v", err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("expected 1 attempt, got %d", got)
+	}
+}
+
+func TestGetMonitoringsRetryExhaustedReturnsLastError(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts.Add(1)
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-key", "de-1")
+	client.SetRetry(2, time.Millisecond)
+
+	_, err := client.GetMonitorings(context.Background(), "de-1", nil)
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 status error, got %v", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestGetMonitoringsRetryStopsOnContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts.Add(1)
+		writer.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-key", "de-1")
+	client.SetRetry(5, 10*time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetMonitorings(ctx, "de-1", nil)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected retry wait to stop on cancellation, took %s", elapsed)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("expected 1 attempt before cancellation, got %d", got)
+	}
+}
+
+func TestRateLimitThrottlesBurstOfPosts(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		arrivals []time.Time
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		mu.Unlock()
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	const rps = 20
+	const posts = 6
+	client := NewClient(server.URL, "secret-key", "de-1")
+	client.SetRateLimit(rps)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < posts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.PostMonitoringResponse(context.Background(), monitor.MonitoringResponsePayload{MonitoringID: "mon-1", Status: monitor.StatusUp}); err != nil {
+				t.Errorf("unexpected post error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The first post goes out at once, every further one waits an interval.
+	minimum := time.Duration(posts-1) * time.Second / rps
+	if elapsed := time.Since(start); elapsed < minimum {
+		t.Fatalf("expected %d posts to take at least %s, took %s", posts, minimum, elapsed)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(arrivals) != posts {
+		t.Fatalf("expected %d posts, got %d", posts, len(arrivals))
+	}
+}
+
+func TestRateLimitWaitStopsOnContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests.Add(1)
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-key", "de-1")
+	client.SetRateLimit(1)
+
+	if _, err := client.GetMonitorings(context.Background(), "de-1", nil); err != nil {
+		t.Fatalf("unexpected error on first fetch: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	// The next token is a second away, past the deadline, so the fetch fails
+	// without waiting.
+	_, err := client.GetMonitorings(ctx, "de-1", nil)
+	if err == nil {
+		t.Fatalf("expected the fetch to fail while waiting for a token")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the wait to stop on cancellation, took %s", elapsed)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("expected the throttled fetch not to reach Core, got %d requests", got)
+	}
+}
+
+func TestPostTimeoutCancelsSlowPostWhileFetchUsesItsOwn(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method == http.MethodPost {
+			select {
+			case <-request.Context().Done():
+			case <-release:
+			}
+			return
+		}
+		time.Sleep(150 * time.Millisecond)
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`[{"id":"mon-1","type":"http","target":"https://example.com"}]`))
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	client := NewClient(server.URL, "secret-key", "de-1")
+	client.SetTimeouts(5*time.Second, 50*time.Millisecond)
+
+	start := time.Now()
+	err := client.PostMonitoringResponse(context.Background(), monitor.MonitoringResponsePayload{MonitoringID: "mon-1", Status: monitor.StatusUp})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the post to hit its deadline, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the post to be canceled after its timeout, took %s", elapsed)
+	}
+
+	monitorings, err := client.GetMonitorings(context.Background(), "de-1", nil)
+	if err != nil {
+		t.Fatalf("expected the fetch to finish within its own timeout, got %v", err)
+	}
+	if len(monitorings) != 1 || monitorings[0].ID != "mon-1" {
+		t.Fatalf("expected fetched monitoring mon-1, got %+v", monitorings)
+	}
+}
+
+func TestSetTimeoutsKeepsDefaultsForNonPositiveValues(t *testing.T) {
+	t.Parallel()
+
+	client := NewClient("https://core.example.test", "secret-key", "de-1")
+	client.SetTimeouts(0, -time.Second)
+	if client.requestTimeout(http.MethodGet) != 30*time.Second || client.requestTimeout(http.MethodPost) != 30*time.Second {
+		t.Fatalf("expected 30 second defaults, got fetch %s post %s", client.requestTimeout(http.MethodGet), client.requestTimeout(http.MethodPost))
+	}
+
+	client.SetTimeouts(time.Minute, 5*time.Second)
+	if got := client.requestTimeout(http.MethodHead); got != time.Minute {
+		t.Fatalf("expected HEAD to use the fetch timeout, got %s", got)
+	}
+	if got := client.requestTimeout(http.MethodPost); got != 5*time.Second {
+		t.Fatalf("expected POST to use the post timeout, got %s", got)
+	}
+}
@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// New returns a logger writing in the given format that drops records below
+// level. An empty or unknown level logs at info.
+func New(writer io.Writer, format, level string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: parseLevel(level)}
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(writer, options))
+	default:
+		return slog.New(slog.NewTextHandler(writer, options))
+	}
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewJSONFormat(t *testing.T) {
+	t.Parallel()
+
+	var output bytes.Buffer
+	logger := New(&output, "JSON", "")
+	logger.Info("Response monitoring result computed", "monitoring_id", "42", "status", "up", "duration_ms", 12)
+
+	var entry map[string]any
+	if err := json.Unmarshal(output.Bytes(), &entry); err != nil {
+		t.Fatalf("expected JSON log line, got %q: %v", output.String(), err)
+	}
+	if entry["msg"] != "Response monitoring result computed" {
+		t.Fatalf("unexpected msg: %#v", entry["msg"])
+	}
+	if entry["monitoring_id"] != "42" {
+		t.Fatalf("unexpected monitoring_id: %#v", entry["monitoring_id"])
+	}
+	if entry["status"] != "up" {
+		t.Fatalf("unexpected status: %#v", entry["status"])
+	}
+	if entry["duration_ms"] != float64(12) {
+		t.Fatalf("unexpected duration_ms: %#v", entry["duration_ms"])
+	}
+}
+
+func TestNewDefaultsToText(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{"", "text", "unknown"} {
+		var output bytes.Buffer
+		logger := New(&output, format, "")
+		logger.Info("Dispatching response monitoring jobs", "monitoring_id", "42")
+
+		line := output.String()
+		if json.Valid(output.Bytes()) {
+			t.Fatalf("expected text output for format %q, got %q", format, line)
+		}
+		if !strings.Contains(line, `msg="Dispatching response monitoring jobs"`) || !strings.Contains(line, "monitoring_id=42") {
+			t.Fatalf("unexpected text output for format %q: %q", format, line)
+		}
+	}
+}
+
+func TestNewFiltersBelowLevel(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		level    string
+		expected []string
+	}{
+		{level: "", expected: []string{"info", "warn", "error"}},
+		{level: "debug", expected: []string{"debug", "info", "warn", "error"}},
+		{level: "WARN", expected: []string{"warn", "error"}},
+		{level: "error", expected: []string{"error"}},
+		{level: "unknown", expected: []string{"info", "warn", "error"}},
+	}
+
+	for _, testCase := range testCases {
+		var output bytes.Buffer
+		logger := New(&output, FormatText, testCase.level)
+		logger.Debug("debug")
+		logger.Info("info")
+		logger.Warn("warn")
+		logger.Error("error")
+
+		lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+		if len(lines) != len(testCase.expected) {
+			t.Fatalf("expected %d lines for level %q, got %q", len(testCase.expected), testCase.level, output.String())
+		}
+		for index, message := range testCase.expected {
+			if !strings.Contains(lines[index], "msg="+message) {
+				t.Fatalf("expected line %d to log %q for level %q, got %q", index, message, testCase.level, lines[index])
+			}
+		}
+	}
+}
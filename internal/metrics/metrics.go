@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Registry holds the instance metrics in its own Prometheus registry, so
+// /metrics only exposes what the instance records.
+type Registry struct {
+	registry  *prometheus.Registry
+	checks    *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+	lastRun   prometheus.Gauge
+}
+
+func NewRegistry() *Registry {
+	r := &Registry{
+		registry: prometheus.NewRegistry(),
+		checks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "webguard_monitoring_checks_total",
+			Help: "Total number of monitoring checks by type and status.",
+		}, []string{"type", "status"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "webguard_check_duration_seconds",
+			Help:    "Duration of monitoring checks by type.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"type"}),
+		lastRun: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "webguard_last_run_timestamp_seconds",
+			Help: "Unix timestamp of the last completed monitoring run.",
+		}),
+	}
+	r.registry.MustRegister(r.checks, r.durations, r.lastRun)
+	return r
+}
+
+func (r *Registry) ObserveCheck(monitoringType, status string, duration time.Duration) {
+	if r == nil {
+		return
+	}
+
+	r.checks.WithLabelValues(monitoringType, status).Inc()
+	r.durations.WithLabelValues(monitoringType).Observe(duration.Seconds())
+}
+
+func (r *Registry) SetLastRun(at time.Time) {
+	if r == nil {
+		return
+	}
+
+	r.lastRun.Set(float64(at.UnixNano()) / float64(time.Second))
+}
+
+func (r *Registry) CheckCount(monitoringType, status string) uint64 {
+	if r == nil {
+		return 0
+	}
+
+	// Gathering instead of WithLabelValues keeps a lookup from creating an
+	// empty series.
+	families, err := r.registry.Gather()
+	if err != nil {
+		return 0
+	}
+	for _, family := range families {
+		if family.GetName() != "webguard_monitoring_checks_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if labelValue(metric, "type") == monitoringType && labelValue(metric, "status") == status {
+				return uint64(metric.GetCounter().GetValue())
+			}
+		}
+	}
+	return 0
+}
+
+func labelValue(metric *dto.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
+
+func (r *Registry) Handler() http.Handler {
+	registry := prometheus.NewRegistry()
+	if r != nil {
+		registry = r.registry
+	}
+	metrics := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		metrics.ServeHTTP(writer, request)
+	})
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T, registry *Registry) string {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	return recorder.Body.String()
+}
+
+func TestRegistryWritesPrometheusExposition(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.ObserveCheck("http", "up", 31250*time.Microsecond)
+	registry.ObserveCheck("http", "up", 2*time.Second)
+	registry.ObserveCheck("http", "down", 3906250*time.Nanosecond)
+	registry.SetLastRun(time.Unix(1700000000, 0))
+
+	body := scrape(t, registry)
+
+	expectedLines := []string{
+		"# TYPE webguard_monitoring_checks_total counter",
+		`webguard_monitoring_checks_total{status="down",type="http"} 1`,
+		`webguard_monitoring_checks_total{status="up",type="http"} 2`,
+		"# TYPE webguard_check_duration_seconds histogram",
+		`webguard_check_duration_seconds_bucket{type="http",le="0.005"} 1`,
+		`webguard_check_duration_seconds_bucket{type="http",le="0.05"} 2`,
+		`webguard_check_duration_seconds_bucket{type="http",le="2.5"} 3`,
+		`webguard_check_duration_seconds_bucket{type="http",le="+Inf"} 3`,
+		`webguard_check_duration_seconds_sum{type="http"} 2.03515625`,
+		`webguard_check_duration_seconds_count{type="http"} 3`,
+		"# TYPE webguard_last_run_timestamp_seconds gauge",
+		"webguard_last_run_timestamp_seconds 1.7e+09",
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("expected line %q in output:\n%s", line, body)
+		}
+	}
+
+	if registry.CheckCount("http", "up") != 2 {
+		t.Fatalf("expected 2 up checks, got %d", registry.CheckCount("http", "up"))
+	}
+}
+
+func TestRegistryEscapesLabelValues(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.ObserveCheck(`we"ird\type`, "up", time.Millisecond)
+
+	body := scrape(t, registry)
+	if !strings.Contains(body, `webguard_monitoring_checks_total{status="up",type="we\"ird\\type"} 1`) {
+		t.Fatalf("expected escaped label value, got:\n%s", body)
+	}
+}
+
+func TestNilRegistryIsNoop(t *testing.T) {
+	t.Parallel()
+
+	var registry *Registry
+	registry.ObserveCheck("http", "up", time.Millisecond)
+	registry.SetLastRun(time.Now())
+
+	if registry.CheckCount("http", "up") != 0 {
+		t.Fatalf("expected nil registry to report zero")
+	}
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+}
+
+func TestHandlerRejectsNonGet(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	NewRegistry().Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status 405, got %d", recorder.Code)
+	}
+}
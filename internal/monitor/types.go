@@ -13,30 +13,168 @@ type Type string
 const (
 	TypeHTTP             Type = "http"
 	TypePing             Type = "ping"
+	TypeICMP             Type = "icmp"
 	TypeKeyword          Type = "keyword"
 	TypePort             Type = "port"
+	TypeDNS              Type = "dns"
+	TypeSMTP             Type = "smtp"
+	TypeWebSocket        Type = "websocket"
 	TypeHeartbeat        Type = "heartbeat"
 	TypeDomainExpiration Type = "domain_expiration"
 )
 
+// CheckedTypes are the types a full run fetches from Core and the values
+// ENABLED_TYPES accepts. Heartbeat monitorings are passive and not fetched.
+var CheckedTypes = []Type{
+	TypeHTTP,
+	TypePing,
+	TypeICMP,
+	TypeKeyword,
+	TypePort,
+	TypeDNS,
+	TypeSMTP,
+	TypeWebSocket,
+	TypeDomainExpiration,
+}
+
 type Status string
 
 const (
 	StatusUp      Status = "up"
 	StatusDown    Status = "down"
 	StatusUnknown Status = "unknown"
+
+	// StatusDegraded is reported instead of StatusUp when a check succeeded
+	// but took longer than the monitoring's degraded_threshold_ms.
+	StatusDegraded Status = "degraded"
 )
 
 type HTTPMethod string
 
 const (
-	HTTPMethodGet    HTTPMethod = "get"
-	HTTPMethodPost   HTTPMethod = "post"
-	HTTPMethodPut    HTTPMethod = "put"
-	HTTPMethodPatch  HTTPMethod = "patch"
-	HTTPMethodDelete HTTPMethod = "delete"
+	HTTPMethodGet     HTTPMethod = "get"
+	HTTPMethodPost    HTTPMethod = "post"
+	HTTPMethodPut     HTTPMethod = "put"
+	HTTPMethodPatch   HTTPMethod = "patch"
+	HTTPMethodDelete  HTTPMethod = "delete"
+	HTTPMethodHead    HTTPMethod = "head"
+	HTTPMethodOptions HTTPMethod = "options"
+)
+
+type HTTPProtocol string
+
+const (
+	HTTPProtocolAny   HTTPProtocol = "any"
+	HTTPProtocolHTTP2 HTTPProtocol = "http2"
+	HTTPProtocolHTTP3 HTTPProtocol = "http3"
+)
+
+type KeywordMode string
+
+const (
+	KeywordModeContains KeywordMode = "contains"
+	KeywordModeAbsent   KeywordMode = "absent"
+	KeywordModeRegex    KeywordMode = "regex"
+)
+
+type DNSRecordType string
+
+const (
+	DNSRecordTypeA     DNSRecordType = "A"
+	DNSRecordTypeAAAA  DNSRecordType = "AAAA"
+	DNSRecordTypeCNAME DNSRecordType = "CNAME"
+	DNSRecordTypeMX    DNSRecordType = "MX"
+	DNSRecordTypeNS    DNSRecordType = "NS"
+	DNSRecordTypeTXT   DNSRecordType = "TXT"
+)
+
+type Protocol string
+
+const (
+	ProtocolTCP Protocol = "tcp"
+	ProtocolUDP Protocol = "udp"
+)
+
+type AuthType string
+
+const (
+	AuthTypeBasic  AuthType = "basic"
+	AuthTypeBearer AuthType = "bearer"
+)
+
+type PortProbeMode string
+
+const (
+	PortProbeModeConnect PortProbeMode = "connect"
+	PortProbeModeReset   PortProbeMode = "reset"
 )
 
+// IPVersion pins the address family a check connects over on dual-stack
+// targets. Empty or unknown values behave like IPVersionAuto.
+type IPVersion string
+
+const (
+	IPVersionAuto IPVersion = "auto"
+	IPVersionIPv4 IPVersion = "ipv4"
+	IPVersionIPv6 IPVersion = "ipv6"
+)
+
+// FailureTargetBlocked is reported for any check whose target is refused by
+// BLOCK_PRIVATE_TARGETS.
+const FailureTargetBlocked = "target_blocked"
+
+// FailureIPVersionUnavailable is reported when the target has no address of
+// the monitoring's ip_version.
+const FailureIPVersionUnavailable = "ip_version_unavailable"
+
+// FailureSRVNotFound is reported when a monitoring with srv_target has no SRV
+// record to check.
+const FailureSRVNotFound = "srv_not_found"
+
+const (
+	SSLFailureConnectionFailed = "connection_failed"
+	SSLFailureNotYetValid      = "not_yet_valid"
+	SSLFailureExpired          = "expired"
+	SSLFailureHostnameMismatch = "hostname_mismatch"
+	SSLFailureRevoked          = "revoked"
+	SSLFailureUntrustedChain   = "untrusted_chain"
+	SSLFailureSelfSigned       = "self_signed"
+)
+
+const (
+	PortFailureConnectionRefused = "connection_refused"
+	PortFailureTimeout           = "timeout"
+	PortFailureUnreachable       = "unreachable"
+)
+
+const (
+	HTTPFailureDNS               = "dns_failure"
+	HTTPFailureConnectionRefused = "connection_refused"
+	HTTPFailureConnectionReset   = "connection_reset"
+	HTTPFailureTLS               = "tls_error"
+	HTTPFailureTimeout           = "timeout"
+	HTTPFailureRequest           = "request_failed"
+	HTTPFailureBodyHashMismatch  = "body_hash_mismatch"
+	HTTPFailureSchemeDowngraded  = "scheme_downgraded"
+	HTTPFailureUnexpectedContent = "unexpected_content"
+)
+
+type StatusCodeRange struct {
+	Min int
+	Max int
+}
+
+type StatusCodeRanges []StatusCodeRange
+
+func (r StatusCodeRanges) Contains(statusCode int) bool {
+	for _, item := range r {
+		if statusCode >= item.Min && statusCode <= item.Max {
+			return true
+		}
+	}
+	return false
+}
+
 type Monitoring struct {
 	ID   string `json:"id"`
 	Type Type   `json:"type"`
@@ -45,21 +183,127 @@ type Monitoring struct {
 
 	Timeout int `json:"timeout"`
 
+	// DegradedThresholdMs reports a successful response check that took
+	// longer than this many milliseconds as degraded. Zero disables it.
+	DegradedThresholdMs int `json:"degraded_threshold_ms"`
+
 	HTTPMethod  HTTPMethod `json:"http_method"`
 	HTTPBody    any        `json:"http_body"`
 	HTTPHeaders any        `json:"http_headers"`
 
-	AuthUsername string `json:"auth_username"`
-	AuthPassword string `json:"auth_password"`
+	// HTTPProtocol requires the response to arrive over the given protocol;
+	// empty or "any" accepts whatever the transport negotiates.
+	HTTPProtocol HTTPProtocol `json:"http_protocol"`
+
+	// AuthType selects the Authorization header HTTP checks send: "basic"
+	// (the default) uses AuthUsername and AuthPassword, "bearer" sends
+	// AuthToken and ignores the basic credentials.
+	AuthType     AuthType `json:"auth_type"`
+	AuthUsername string   `json:"auth_username"`
+	AuthPassword string   `json:"auth_password"`
+	AuthToken    string   `json:"auth_token"`
+
+	ClientCertPEM string `json:"client_cert_pem"`
+	ClientKeyPEM  string `json:"client_key_pem"`
+
+	// TLSServerName overrides the SNI sent to the target and the hostname
+	// its certificate is verified against.
+	TLSServerName string `json:"tls_server_name"`
 
-	Keyword string `json:"keyword"`
-	Port    int    `json:"port"`
+	ProxyURL string `json:"proxy_url"`
+
+	ExpectedStatusCodes StatusCodeRanges `json:"expected_status_codes"`
+	MaxRedirects        *int             `json:"max_redirects"`
+	UseCookieJar        bool             `json:"use_cookie_jar"`
+
+	// RetryUnsafeMethods lets HTTP_RETRY_TIMES apply to POST, PUT and PATCH
+	// checks, which are otherwise sent only once.
+	RetryUnsafeMethods bool `json:"retry_unsafe_methods"`
+
+	// FailOnSchemeDowngrade stops at a redirect from https to http and
+	// reports the check down instead of only flagging the downgrade.
+	FailOnSchemeDowngrade bool `json:"fail_on_scheme_downgrade"`
+
+	// RecordHopStatuses reports the status code of every response along the
+	// redirect chain, so the edge of a CDN-fronted site and the origin that
+	// finally answers can be told apart.
+	RecordHopStatuses bool `json:"record_hop_statuses"`
+
+	// PinResolvedIPs flags the check result when the IP set the target
+	// resolves to differs from the previous check, which can indicate a
+	// hijacked DNS record.
+	PinResolvedIPs bool `json:"pin_resolved_ips"`
+
+	// ConditionalRequests makes HTTP checks send the ETag and Last-Modified
+	// of the previous response as If-None-Match and If-Modified-Since. A 304
+	// then counts as up and unchanged, a 200 with new validators as changed.
+	ConditionalRequests bool `json:"conditional_requests"`
+
+	// BypassCache sends no-cache headers and a unique query parameter so
+	// caches in front of the target cannot answer for the origin.
+	BypassCache bool `json:"bypass_cache"`
+
+	// AcceptLanguage overrides HTTP_ACCEPT_LANGUAGE for this monitoring.
+	AcceptLanguage string `json:"accept_language"`
+
+	// ExpectedBodyHash is the hex SHA-256 of the response body an HTTP check
+	// expects; any other body reports down. BodyHashIgnoreWhitespace hashes
+	// the body with all whitespace removed.
+	ExpectedBodyHash         string `json:"expected_body_hash"`
+	BodyHashIgnoreWhitespace bool   `json:"body_hash_ignore_whitespace"`
+
+	// ExpectedContentType is the media type, such as application/json or
+	// text/*, a successful HTTP or keyword check must answer with. Anything
+	// else, typically the HTML of a captive portal, reports down.
+	ExpectedContentType string `json:"expected_content_type"`
+
+	Keyword                string      `json:"keyword"`
+	KeywordMode            KeywordMode `json:"keyword_mode"`
+	KeywordCaseInsensitive bool        `json:"keyword_case_insensitive"`
+	Port                   int         `json:"port"`
+
+	Protocol     Protocol `json:"protocol"`
+	ProbePayload string   `json:"probe_payload"`
+
+	// ProbeCount makes ping, ICMP and TCP port checks send this many probes
+	// and report the average latency of the successful ones. Zero or one
+	// sends a single probe.
+	ProbeCount int `json:"probe_count"`
+
+	// PortProbeMode "reset" closes TCP port checks with an RST instead of
+	// a graceful FIN, so frequent checks leave no TIME_WAIT sockets behind.
+	PortProbeMode PortProbeMode `json:"port_probe_mode"`
+
+	// IPVersion forces port, ping, ICMP, HTTP, keyword, SMTP and WebSocket
+	// checks onto IPv4 or IPv6 instead of whatever the resolver prefers.
+	IPVersion IPVersion `json:"ip_version"`
+
+	// SRVTarget treats the target of port, ping and ICMP checks as an SRV
+	// name such as _sip._tcp.example.com and checks the host and port of its
+	// highest-priority record instead.
+	SRVTarget bool `json:"srv_target"`
+
+	DNSRecordType DNSRecordType `json:"dns_record_type"`
+
+	SMTPRequireStartTLS bool `json:"smtp_require_starttls"`
+
+	WebSocketPing bool `json:"websocket_ping"`
 
 	HeartbeatIntervalMinutes *int       `json:"heartbeat_interval_minutes"`
 	HeartbeatGraceMinutes    *int       `json:"heartbeat_grace_minutes"`
 	HeartbeatLastPingAt      *time.Time `json:"heartbeat_last_ping_at"`
 
 	MaintenanceActive bool `json:"maintenance_active"`
+
+	// Enabled is nil when Core does not send the flag, which counts as
+	// enabled. A disabled monitoring is skipped without posting anything,
+	// unlike maintenance, which posts unknown.
+	Enabled *bool `json:"enabled"`
+}
+
+// IsEnabled reports whether Core wants the monitoring checked.
+func (m Monitoring) IsEnabled() bool {
+	return m.Enabled == nil || *m.Enabled
 }
 
 func (m *Monitoring) UnmarshalJSON(data []byte) error {
@@ -72,21 +316,77 @@ func (m *Monitoring) UnmarshalJSON(data []byte) error {
 
 		Timeout any `json:"timeout"`
 
+		DegradedThresholdMs any `json:"degraded_threshold_ms"`
+
 		HTTPMethod  HTTPMethod `json:"http_method"`
 		HTTPBody    any        `json:"http_body"`
 		HTTPHeaders any        `json:"http_headers"`
 
-		AuthUsername string `json:"auth_username"`
-		AuthPassword string `json:"auth_password"`
+		HTTPProtocol HTTPProtocol `json:"http_protocol"`
+
+		AuthType     AuthType `json:"auth_type"`
+		AuthUsername string   `json:"auth_username"`
+		AuthPassword string   `json:"auth_password"`
+		AuthToken    string   `json:"auth_token"`
+
+		ClientCertPEM string `json:"client_cert_pem"`
+		ClientKeyPEM  string `json:"client_key_pem"`
+
+		TLSServerName string `json:"tls_server_name"`
+
+		ProxyURL string `json:"proxy_url"`
 
-		Keyword string `json:"keyword"`
-		Port    any    `json:"port"`
+		ExpectedStatusCodes any `json:"expected_status_codes"`
+		MaxRedirects        any `json:"max_redirects"`
+		UseCookieJar        any `json:"use_cookie_jar"`
+
+		RetryUnsafeMethods any `json:"retry_unsafe_methods"`
+
+		FailOnSchemeDowngrade any `json:"fail_on_scheme_downgrade"`
+
+		RecordHopStatuses any `json:"record_hop_statuses"`
+
+		PinResolvedIPs any `json:"pin_resolved_ips"`
+
+		ConditionalRequests any `json:"conditional_requests"`
+
+		BypassCache any `json:"bypass_cache"`
+
+		AcceptLanguage string `json:"accept_language"`
+
+		ExpectedBodyHash         string `json:"expected_body_hash"`
+		BodyHashIgnoreWhitespace any    `json:"body_hash_ignore_whitespace"`
+
+		ExpectedContentType string `json:"expected_content_type"`
+
+		Keyword                string      `json:"keyword"`
+		KeywordMode            KeywordMode `json:"keyword_mode"`
+		KeywordCaseInsensitive any         `json:"keyword_case_insensitive"`
+		Port                   any         `json:"port"`
+
+		Protocol     Protocol `json:"protocol"`
+		ProbePayload string   `json:"probe_payload"`
+
+		ProbeCount any `json:"probe_count"`
+
+		PortProbeMode PortProbeMode `json:"port_probe_mode"`
+		IPVersion     IPVersion     `json:"ip_version"`
+
+		SRVTarget any `json:"srv_target"`
+
+		DNSRecordType DNSRecordType `json:"dns_record_type"`
+
+		SMTPRequireStartTLS any `json:"smtp_require_starttls"`
+
+		WebSocketPing any `json:"websocket_ping"`
 
 		HeartbeatIntervalMinutes any `json:"heartbeat_interval_minutes"`
 		HeartbeatGraceMinutes    any `json:"heartbeat_grace_minutes"`
 		HeartbeatLastPingAt      any `json:"heartbeat_last_ping_at"`
 
 		MaintenanceActive any `json:"maintenance_active"`
+
+		Enabled any `json:"enabled"`
 	}
 
 	var raw rawMonitoring
@@ -103,10 +403,26 @@ func (m *Monitoring) UnmarshalJSON(data []byte) error {
 	if err != nil {
 		return err
 	}
+	degradedThresholdMs, err := parseIntFlexible(raw.DegradedThresholdMs, "degraded_threshold_ms")
+	if err != nil {
+		return err
+	}
 	port, err := parseIntFlexible(raw.Port, "port")
 	if err != nil {
 		return err
 	}
+	probeCount, err := parseIntFlexible(raw.ProbeCount, "probe_count")
+	if err != nil {
+		return err
+	}
+	expectedStatusCodes, err := parseStatusCodeRangesFlexible(raw.ExpectedStatusCodes, "expected_status_codes")
+	if err != nil {
+		return err
+	}
+	maxRedirects, err := parseOptionalIntFlexible(raw.MaxRedirects, "max_redirects")
+	if err != nil {
+		return err
+	}
 	heartbeatIntervalMinutes, err := parseOptionalIntFlexible(raw.HeartbeatIntervalMinutes, "heartbeat_interval_minutes")
 	if err != nil {
 		return err
@@ -119,10 +435,62 @@ func (m *Monitoring) UnmarshalJSON(data []byte) error {
 	if err != nil {
 		return err
 	}
+	keywordCaseInsensitive, err := parseBoolFlexible(raw.KeywordCaseInsensitive, "keyword_case_insensitive")
+	if err != nil {
+		return err
+	}
+	smtpRequireStartTLS, err := parseBoolFlexible(raw.SMTPRequireStartTLS, "smtp_require_starttls")
+	if err != nil {
+		return err
+	}
+	useCookieJar, err := parseBoolFlexible(raw.UseCookieJar, "use_cookie_jar")
+	if err != nil {
+		return err
+	}
+	bypassCache, err := parseBoolFlexible(raw.BypassCache, "bypass_cache")
+	if err != nil {
+		return err
+	}
+	retryUnsafeMethods, err := parseBoolFlexible(raw.RetryUnsafeMethods, "retry_unsafe_methods")
+	if err != nil {
+		return err
+	}
+	failOnSchemeDowngrade, err := parseBoolFlexible(raw.FailOnSchemeDowngrade, "fail_on_scheme_downgrade")
+	if err != nil {
+		return err
+	}
+	recordHopStatuses, err := parseBoolFlexible(raw.RecordHopStatuses, "record_hop_statuses")
+	if err != nil {
+		return err
+	}
+	pinResolvedIPs, err := parseBoolFlexible(raw.PinResolvedIPs, "pin_resolved_ips")
+	if err != nil {
+		return err
+	}
+	conditionalRequests, err := parseBoolFlexible(raw.ConditionalRequests, "conditional_requests")
+	if err != nil {
+		return err
+	}
+	bodyHashIgnoreWhitespace, err := parseBoolFlexible(raw.BodyHashIgnoreWhitespace, "body_hash_ignore_whitespace")
+	if err != nil {
+		return err
+	}
+	srvTarget, err := parseBoolFlexible(raw.SRVTarget, "srv_target")
+	if err != nil {
+		return err
+	}
+	webSocketPing, err := parseBoolFlexible(raw.WebSocketPing, "websocket_ping")
+	if err != nil {
+		return err
+	}
 	maintenanceActive, err := parseBoolFlexible(raw.MaintenanceActive, "maintenance_active")
 	if err != nil {
 		return err
 	}
+	enabled, err := parseOptionalBoolFlexible(raw.Enabled, "enabled")
+	if err != nil {
+		return err
+	}
 
 	*m = Monitoring{
 		ID:   id,
@@ -132,21 +500,77 @@ func (m *Monitoring) UnmarshalJSON(data []byte) error {
 
 		Timeout: timeout,
 
+		DegradedThresholdMs: degradedThresholdMs,
+
 		HTTPMethod:  raw.HTTPMethod,
 		HTTPBody:    raw.HTTPBody,
 		HTTPHeaders: raw.HTTPHeaders,
 
+		HTTPProtocol: HTTPProtocol(strings.ToLower(strings.TrimSpace(string(raw.HTTPProtocol)))),
+
+		AuthType:     AuthType(strings.ToLower(strings.TrimSpace(string(raw.AuthType)))),
 		AuthUsername: raw.AuthUsername,
 		AuthPassword: raw.AuthPassword,
+		AuthToken:    strings.TrimSpace(raw.AuthToken),
+
+		ClientCertPEM: raw.ClientCertPEM,
+		ClientKeyPEM:  raw.ClientKeyPEM,
+
+		TLSServerName: strings.TrimSpace(raw.TLSServerName),
+
+		ProxyURL: strings.TrimSpace(raw.ProxyURL),
+
+		ExpectedStatusCodes: expectedStatusCodes,
+		MaxRedirects:        maxRedirects,
+		UseCookieJar:        useCookieJar,
+
+		RetryUnsafeMethods: retryUnsafeMethods,
+
+		FailOnSchemeDowngrade: failOnSchemeDowngrade,
+
+		RecordHopStatuses: recordHopStatuses,
+
+		PinResolvedIPs: pinResolvedIPs,
+
+		ConditionalRequests: conditionalRequests,
+
+		BypassCache: bypassCache,
 
-		Keyword: raw.Keyword,
-		Port:    port,
+		AcceptLanguage: strings.TrimSpace(raw.AcceptLanguage),
+
+		ExpectedBodyHash:         strings.ToLower(strings.TrimPrefix(strings.TrimSpace(raw.ExpectedBodyHash), "sha256:")),
+		BodyHashIgnoreWhitespace: bodyHashIgnoreWhitespace,
+
+		ExpectedContentType: strings.ToLower(strings.TrimSpace(raw.ExpectedContentType)),
+
+		Keyword:                raw.Keyword,
+		KeywordMode:            KeywordMode(strings.ToLower(strings.TrimSpace(string(raw.KeywordMode)))),
+		KeywordCaseInsensitive: keywordCaseInsensitive,
+		Port:                   port,
+
+		Protocol:     Protocol(strings.ToLower(strings.TrimSpace(string(raw.Protocol)))),
+		ProbePayload: raw.ProbePayload,
+
+		ProbeCount: probeCount,
+
+		PortProbeMode: PortProbeMode(strings.ToLower(strings.TrimSpace(string(raw.PortProbeMode)))),
+		IPVersion:     IPVersion(strings.ToLower(strings.TrimSpace(string(raw.IPVersion)))),
+
+		SRVTarget: srvTarget,
+
+		DNSRecordType: DNSRecordType(strings.ToUpper(strings.TrimSpace(string(raw.DNSRecordType)))),
+
+		SMTPRequireStartTLS: smtpRequireStartTLS,
+
+		WebSocketPing: webSocketPing,
 
 		HeartbeatIntervalMinutes: heartbeatIntervalMinutes,
 		HeartbeatGraceMinutes:    heartbeatGraceMinutes,
 		HeartbeatLastPingAt:      heartbeatLastPingAt,
 
 		MaintenanceActive: maintenanceActive,
+
+		Enabled: enabled,
 	}
 
 	return nil
@@ -157,14 +581,83 @@ type MonitoringResponsePayload struct {
 	Status         Status   `json:"status"`
 	ResponseTime   *float64 `json:"response_time"`
 	HTTPStatusCode *int     `json:"http_status_code"`
+	DNSMs          *float64 `json:"dns_ms,omitempty"`
+	ConnectMs      *float64 `json:"connect_ms,omitempty"`
+	TLSMs          *float64 `json:"tls_ms,omitempty"`
+	TTFBMs         *float64 `json:"ttfb_ms,omitempty"`
+	FailureReason  *string  `json:"failure_reason,omitempty"`
+	ResolvedIP     *string  `json:"resolved_ip,omitempty"`
+	FinalURL       *string  `json:"final_url,omitempty"`
+	RedirectCount  *int     `json:"redirect_count,omitempty"`
+
+	// SchemeDowngraded is true when a redirect of an HTTP check went from
+	// https to http.
+	SchemeDowngraded bool `json:"scheme_downgraded,omitempty"`
+
+	// HopStatusCodes is set for monitorings with record_hop_statuses and
+	// lists the status code of every response along the redirect chain,
+	// first hop first. FirstHopStatusCode and FinalHopStatusCode are its
+	// ends: the edge that was asked and the server that finally answered.
+	HopStatusCodes     []int `json:"hop_status_codes,omitempty"`
+	FirstHopStatusCode *int  `json:"first_hop_status_code,omitempty"`
+	FinalHopStatusCode *int  `json:"final_hop_status_code,omitempty"`
+
+	// ResolvedIPsChanged is set for monitorings with pin_resolved_ips and is
+	// true when the target resolved to a different IP set than on the
+	// previous check.
+	ResolvedIPsChanged *bool `json:"resolved_ips_changed,omitempty"`
+
+	// ContentChanged is set for monitorings with conditional_requests once a
+	// previous response is known: false for a 304 or unchanged validators,
+	// true when the target answered with a new ETag or Last-Modified.
+	ContentChanged *bool `json:"content_changed,omitempty"`
 }
 
 type SSLResultPayload struct {
-	MonitoringID string     `json:"monitoring_id"`
-	IsValid      bool       `json:"is_valid"`
-	ExpiresAt    *time.Time `json:"expires_at"`
-	Issuer       *string    `json:"issuer"`
-	IssuedAt     *time.Time `json:"issued_at"`
+	MonitoringID    string     `json:"monitoring_id"`
+	IsValid         bool       `json:"is_valid"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	Issuer          *string    `json:"issuer"`
+	IssuedAt        *time.Time `json:"issued_at"`
+	DaysUntilExpiry *int       `json:"days_until_expiry"`
+	ExpiringSoon    bool       `json:"expiring_soon"`
+	SANs            []string   `json:"sans"`
+	ChainLength     int        `json:"chain_length"`
+	TLSVersion      *string    `json:"tls_version"`
+	CipherSuite     *string    `json:"cipher_suite"`
+	Deprecated      bool       `json:"deprecated"`
+	Revoked         *bool      `json:"revoked"`
+	FailureReason   *string    `json:"failure_reason"`
+
+	// SerialNumber and Fingerprint identify the leaf certificate so Core can
+	// tell when it was rotated: the serial as lowercase hex and the SHA-256
+	// of the DER encoding as lowercase hex.
+	SerialNumber *string `json:"serial_number"`
+	Fingerprint  *string `json:"fingerprint"`
+}
+
+type RunSummaryPayload struct {
+	StartedAt          time.Time `json:"started_at"`
+	DurationMs         int64     `json:"duration_ms"`
+	Checks             int       `json:"checks"`
+	Up                 int       `json:"up"`
+	Down               int       `json:"down"`
+	Degraded           int       `json:"degraded"`
+	Unknown            int       `json:"unknown"`
+	SkippedMaintenance int       `json:"skipped_maintenance"`
+	ResponseTimeP50    *float64  `json:"response_time_p50,omitempty"`
+	ResponseTimeP95    *float64  `json:"response_time_p95,omitempty"`
+	ResponseTimeP99    *float64  `json:"response_time_p99,omitempty"`
+	ResponseTimeMax    *float64  `json:"response_time_max,omitempty"`
+}
+
+type StatusTransitionPayload struct {
+	MonitoringID   string    `json:"monitoring_id"`
+	Type           Type      `json:"type"`
+	Target         string    `json:"target"`
+	PreviousStatus Status    `json:"previous_status"`
+	Status         Status    `json:"status"`
+	ChangedAt      time.Time `json:"changed_at"`
 }
 
 type DomainResultPayload struct {
@@ -246,6 +739,67 @@ func parseOptionalIntFlexible(value any, field string) (*int, error) {
 	return &parsed, nil
 }
 
+func parseStatusCodeRangesFlexible(value any, field string) (StatusCodeRanges, error) {
+	switch typed := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		ranges := make(StatusCodeRanges, 0)
+		for _, part := range strings.Split(typed, ",") {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+			parsed, err := parseStatusCodeRange(part, field)
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, parsed)
+		}
+		if len(ranges) == 0 {
+			return nil, nil
+		}
+		return ranges, nil
+	case []any:
+		ranges := make(StatusCodeRanges, 0, len(typed))
+		for _, item := range typed {
+			parsed, err := parseStatusCodeRangesFlexible(item, field)
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, parsed...)
+		}
+		if len(ranges) == 0 {
+			return nil, nil
+		}
+		return ranges, nil
+	default:
+		statusCode, err := parseIntFlexible(value, field)
+		if err != nil {
+			return nil, err
+		}
+		return StatusCodeRanges{{Min: statusCode, Max: statusCode}}, nil
+	}
+}
+
+func parseStatusCodeRange(value string, field string) (StatusCodeRange, error) {
+	lower, upper, isRange := strings.Cut(strings.TrimSpace(value), "-")
+	minimum, err := strconv.Atoi(strings.TrimSpace(lower))
+	if err != nil {
+		return StatusCodeRange{}, fmt.Errorf("invalid %s: %q", field, value)
+	}
+	maximum := minimum
+	if isRange {
+		maximum, err = strconv.Atoi(strings.TrimSpace(upper))
+		if err != nil {
+			return StatusCodeRange{}, fmt.Errorf("invalid %s: %q", field, value)
+		}
+	}
+	if minimum > maximum {
+		return StatusCodeRange{}, fmt.Errorf("invalid %s: %q", field, value)
+	}
+	return StatusCodeRange{Min: minimum, Max: maximum}, nil
+}
+
 func parseTimeFlexible(value any, field string) (*time.Time, error) {
 	switch typed := value.(type) {
 	case nil:
@@ -268,6 +822,19 @@ func parseTimeFlexible(value any, field string) (*time.Time, error) {
 	}
 }
 
+func parseOptionalBoolFlexible(value any, field string) (*bool, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	parsed, err := parseBoolFlexible(value, field)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
+
 func parseBoolFlexible(value any, field string) (bool, error) {
 	switch typed := value.(type) {
 	case nil:
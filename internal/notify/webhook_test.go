@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+func TestSendStatusTransitionPostsJSON(t *testing.T) {
+	t.Parallel()
+
+	var got map[string]any
+	var gotContentType string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", request.Method)
+		}
+		gotContentType = request.Header.Get("Content-Type")
+		if err := json.NewDecoder(request.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	err := NewWebhook(server.URL).SendStatusTransition(context.Background(), monitor.StatusTransitionPayload{
+		MonitoringID:   "42",
+		Type:           monitor.TypeHTTP,
+		Target:         "https://example.com",
+		PreviousStatus: monitor.StatusUp,
+		Status:         monitor.StatusDown,
+		ChangedAt:      time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotContentType != "application/json" {
+		t.Fatalf("expected application/json, got %q", gotContentType)
+	}
+	expected := map[string]any{
+		"monitoring_id":   "42",
+		"type":            "http",
+		"target":          "https://example.com",
+		"previous_status": "up",
+		"status":          "down",
+		"changed_at":      "2026-05-01T12:00:00Z",
+	}
+	for key, value := range expected {
+		if got[key] != value {
+			t.Fatalf("expected %s=%v, got %v", key, value, got[key])
+		}
+	}
+}
+
+func TestSendStatusTransitionReportsErrorStatus(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := NewWebhook(server.URL).SendStatusTransition(context.Background(), monitor.StatusTransitionPayload{MonitoringID: "42"})
+	if err == nil {
+		t.Fatalf("expected error for non-2xx response")
+	}
+}
@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+// resultBatch collects results of one run until size of them can be posted
+// to Core in a single request.
+type resultBatch[T any] struct {
+	mu      sync.Mutex
+	size    int
+	pending []T
+}
+
+func newResultBatch[T any](size int) *resultBatch[T] {
+	return &resultBatch[T]{size: size}
+}
+
+// add queues item and returns a full chunk once size items are pending.
+func (b *resultBatch[T]) add(item T) []T {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending = append(b.pending, item)
+	if len(b.pending) < b.size {
+		return nil
+	}
+	chunk := b.pending
+	b.pending = nil
+	return chunk
+}
+
+// drain returns everything still pending.
+func (b *resultBatch[T]) drain() []T {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	chunk := b.pending
+	b.pending = nil
+	return chunk
+}
+
+// batchesResults reports whether response and SSL results are batched:
+// CORE_POST_BATCH_SIZE is set and results go to Core.
+func (r *Runner) batchesResults() bool {
+	if r.cfg.CorePostBatchSize <= 0 || r.cfg.DryRun {
+		return false
+	}
+	_, postsToCore := r.sink.(coreSink)
+	return postsToCore
+}
+
+// flushResults posts the results a phase left pending in its run's batches.
+func (r *Runner) flushResults(ctx context.Context, run *runState) {
+	if responses := run.responseBatch().drain(); len(responses) > 0 {
+		r.postMonitoringResponseBatch(ctx, run, responses)
+	}
+	if sslResults := run.sslBatch().drain(); len(sslResults) > 0 {
+		r.postSSLResultBatch(ctx, run, sslResults)
+	}
+}
+
+func (r *Runner) postMonitoringResponseBatch(ctx context.Context, run *runState, payloads []monitor.MonitoringResponsePayload) {
+	err := run.breaker().do(func() error {
+		return r.client.PostMonitoringResponses(ctx, payloads)
+	})
+	if err == nil {
+		r.markResponsesPosted(payloads...)
+	}
+	r.logBatchPostError("Failed to post batched response results", len(payloads), err)
+}
+
+func (r *Runner) postSSLResultBatch(ctx context.Context, run *runState, payloads []monitor.SSLResultPayload) {
+	err := run.breaker().do(func() error {
+		return r.client.PostSSLResults(ctx, payloads)
+	})
+	r.logBatchPostError("Failed to post batched SSL results", len(payloads), err)
+}
+
+func (r *Runner) logBatchPostError(message string, results int, err error) {
+	if err == nil || errors.Is(err, errCorePostsSuspended) {
+		return
+	}
+	r.logger.Error(message, "results", results, "error", err)
+}
@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"errors"
+	"sync"
+)
+
+var errCorePostsSuspended = errors.New("core posts suspended after repeated failures")
+
+// postBreaker stops posting results for the rest of a run once Core has
+// rejected threshold posts in a row. A threshold of zero disables it.
+type postBreaker struct {
+	mu sync.Mutex
+
+	threshold   int
+	consecutive int
+	open        bool
+	skipped     int
+}
+
+func newPostBreaker(threshold int) *postBreaker {
+	return &postBreaker{threshold: max(0, threshold)}
+}
+
+func (b *postBreaker) do(post func() error) error {
+	if b == nil {
+		return post()
+	}
+
+	b.mu.Lock()
+	if b.open {
+		b.skipped++
+		b.mu.Unlock()
+		return errCorePostsSuspended
+	}
+	b.mu.Unlock()
+
+	err := post()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		b.consecutive = 0
+		return nil
+	}
+	b.consecutive++
+	if b.threshold > 0 && b.consecutive >= b.threshold {
+		b.open = true
+	}
+	return err
+}
+
+func (b *postBreaker) skippedPosts() int {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.skipped
+}
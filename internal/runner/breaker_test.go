@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPostBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+
+	breaker := newPostBreaker(2)
+	failure := errors.New("core unavailable")
+	calls := 0
+	fail := func() error {
+		calls++
+		return failure
+	}
+	succeed := func() error {
+		calls++
+		return nil
+	}
+
+	if err := breaker.do(fail); !errors.Is(err, failure) {
+		t.Fatalf("expected post error, got %v", err)
+	}
+	if err := breaker.do(succeed); err != nil {
+		t.Fatalf("expected success to pass through, got %v", err)
+	}
+	if err := breaker.do(fail); !errors.Is(err, failure) {
+		t.Fatalf("expected post error after reset, got %v", err)
+	}
+	if err := breaker.do(fail); !errors.Is(err, failure) {
+		t.Fatalf("expected post error at threshold, got %v", err)
+	}
+	if err := breaker.do(succeed); !errors.Is(err, errCorePostsSuspended) {
+		t.Fatalf("expected posts to be suspended, got %v", err)
+	}
+
+	if calls != 4 {
+		t.Fatalf("expected 4 posts to reach the client, got %d", calls)
+	}
+	if skipped := breaker.skippedPosts(); skipped != 1 {
+		t.Fatalf("expected 1 skipped post, got %d", skipped)
+	}
+}
+
+func TestPostBreakerNilAndDisabledAlwaysPost(t *testing.T) {
+	t.Parallel()
+
+	for _, breaker := range []*postBreaker{nil, newPostBreaker(0)} {
+		calls := 0
+		for i := 0; i < 5; i++ {
+			_ = breaker.do(func() error {
+				calls++
+				return errors.New("core unavailable")
+			})
+		}
+		if calls != 5 {
+			t.Fatalf("expected every post to reach the client, got %d", calls)
+		}
+		if skipped := breaker.skippedPosts(); skipped != 0 {
+			t.Fatalf("expected no skipped posts, got %d", skipped)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package runner
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+	"github.com/m-breuer/webguard-instance-v2/internal/target"
+)
+
+// CheckResult is the outcome of one check as returned by CollectMonitoring.
+// SSL results carry the type "ssl" so they can be told apart from the
+// response check of the same monitoring.
+type CheckResult struct {
+	MonitoringID  string         `json:"id"`
+	Type          string         `json:"type"`
+	Target        string         `json:"target"`
+	Status        monitor.Status `json:"status"`
+	ResponseTime  *float64       `json:"response_time"`
+	FailureReason *string        `json:"failure_reason,omitempty"`
+}
+
+// CollectMonitoring runs the checks of RunMonitoring, or of RunMonitoringType
+// when name is set, and returns their results instead of posting them. Domain
+// expiration details and the run summary are not posted either.
+func (r *Runner) CollectMonitoring(ctx context.Context, name string) ([]CheckResult, error) {
+	if name != "" && !IsRunnableType(name) {
+		return nil, fmt.Errorf("unsupported monitoring type %q", name)
+	}
+
+	if err := r.beginRun(); err != nil {
+		return nil, err
+	}
+	defer r.runs.Done()
+
+	run := r.newRunState()
+	run.results = newResultCollector()
+
+	var err error
+	if name == "" {
+		err = r.runAll(ctx, run)
+	} else {
+		err = r.runType(ctx, name, run)
+	}
+	return run.results.list(), err
+}
+
+type resultCollector struct {
+	mu          sync.Mutex
+	monitorings map[string]monitor.Monitoring
+	order       map[string]int
+	results     []CheckResult
+}
+
+func newResultCollector() *resultCollector {
+	return &resultCollector{
+		monitorings: map[string]monitor.Monitoring{},
+		order:       map[string]int{},
+	}
+}
+
+func (s *runState) collecting() bool {
+	return s != nil && s.results != nil
+}
+
+func (s *runState) trackMonitorings(monitorings []monitor.Monitoring) {
+	if !s.collecting() {
+		return
+	}
+	s.results.mu.Lock()
+	defer s.results.mu.Unlock()
+	for index, monitoring := range monitorings {
+		s.results.monitorings[monitoring.ID] = monitoring
+		s.results.order[monitoring.ID] = index
+	}
+}
+
+func (c *resultCollector) addResponse(payload monitor.MonitoringResponsePayload) {
+	c.add(payload.MonitoringID, "", payload.Status, payload.ResponseTime, payload.FailureReason)
+}
+
+func (c *resultCollector) addSSL(payload monitor.SSLResultPayload) {
+	c.add(payload.MonitoringID, runTypeSSL, validStatus(payload.IsValid), nil, payload.FailureReason)
+}
+
+func (c *resultCollector) add(monitoringID, checkType string, status monitor.Status, responseTime *float64, failureReason *string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	monitoring := c.monitorings[monitoringID]
+	c.results = append(c.results, CheckResult{
+		MonitoringID:  monitoringID,
+		Type:          cmp.Or(checkType, string(monitoring.Type)),
+		Target:        target.Redact(monitoring.Target),
+		Status:        status,
+		ResponseTime:  responseTime,
+		FailureReason: failureReason,
+	})
+}
+
+// list returns the results in the order Core returned the monitorings, with
+// the response result of a monitoring ahead of its SSL result.
+func (c *resultCollector) list() []CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	results := slices.Clone(c.results)
+	slices.SortStableFunc(results, func(a, b CheckResult) int {
+		return cmp.Or(
+			cmp.Compare(c.order[a.MonitoringID], c.order[b.MonitoringID]),
+			cmp.Compare(sslRank(a), sslRank(b)),
+		)
+	})
+	return results
+}
+
+func sslRank(result CheckResult) int {
+	if result.Type == runTypeSSL {
+		return 1
+	}
+	return 0
+}
+
+func validStatus(valid bool) monitor.Status {
+	if valid {
+		return monitor.StatusUp
+	}
+	return monitor.StatusDown
+}
@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+// httpValidators are the cache validators of a response, sent back as
+// If-None-Match and If-Modified-Since on the next check.
+type httpValidators struct {
+	etag         string
+	lastModified string
+}
+
+func (v httpValidators) empty() bool {
+	return v.etag == "" && v.lastModified == ""
+}
+
+// validatorStore remembers the validators of the last successful response
+// per monitoring with conditional_requests. Like ipSetStore it lives only in
+// memory, so the first check after a restart fetches the content again.
+type validatorStore struct {
+	mu         sync.Mutex
+	validators map[string]httpValidators
+}
+
+func newValidatorStore() *validatorStore {
+	return &validatorStore{validators: make(map[string]httpValidators)}
+}
+
+func (s *validatorStore) get(monitoringID string) (httpValidators, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	validators, ok := s.validators[monitoringID]
+	return validators, ok
+}
+
+// observe records validators and reports whether they differ from the
+// previous ones. The first observation never counts as a change.
+func (s *validatorStore) observe(monitoringID string, validators httpValidators) (bool, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, seen := s.validators[monitoringID]
+	s.validators[monitoringID] = validators
+	return previous != validators, seen
+}
+
+// setConditionalHeaders turns the stored validators of an HTTP check with
+// conditional_requests into If-None-Match and If-Modified-Since. Keyword
+// checks always need the body, so they never send them.
+func (r *Runner) setConditionalHeaders(request *http.Request, monitoring monitor.Monitoring) {
+	if !monitoring.ConditionalRequests || monitoring.Type != monitor.TypeHTTP {
+		return
+	}
+	validators, ok := r.validators.get(monitoring.ID)
+	if !ok {
+		return
+	}
+	if validators.etag != "" {
+		request.Header.Set("If-None-Match", validators.etag)
+	}
+	if validators.lastModified != "" {
+		request.Header.Set("If-Modified-Since", validators.lastModified)
+	}
+}
+
+// checkContentChanged stores the validators of a successful response and
+// reports whether they changed since the previous one. It returns nil when
+// conditional_requests is off or there is nothing to compare yet.
+func (r *Runner) checkContentChanged(monitoring monitor.Monitoring, timings httpTimings) *bool {
+	if !monitoring.ConditionalRequests {
+		return nil
+	}
+	validators := httpValidators{etag: timings.ETag, lastModified: timings.LastModified}
+	if validators.empty() {
+		r.logger.Warn("Conditional monitoring response carries no ETag or Last-Modified", "monitoring_id", monitoring.ID)
+	}
+
+	changed, seen := r.validators.observe(monitoring.ID, validators)
+	if !seen {
+		return nil
+	}
+	if changed {
+		r.logger.Info("Response content changed", "monitoring_id", monitoring.ID, "etag", validators.etag, "last_modified", validators.lastModified)
+	}
+	return &changed
+}
@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+func TestDispatchResponseReportsConditionalContentChanges(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu          sync.Mutex
+		etag        = `"v1"`
+		ifNoneMatch []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		ifNoneMatch = append(ifNoneMatch, request.Header.Get("If-None-Match"))
+		writer.Header().Set("ETag", etag)
+		if request.Header.Get("If-None-Match") == etag {
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	client := &fakeCoreClient{}
+	r := New(client, config.Config{QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+	monitoring := monitor.Monitoring{ID: "feed", Type: monitor.TypeHTTP, Target: server.URL, Timeout: 2, HTTPMethod: monitor.HTTPMethodGet, ConditionalRequests: true}
+
+	unchanged, changed := false, true
+	steps := []struct {
+		name       string
+		etag       string
+		statusCode int
+		changed    *bool
+	}{
+		{name: "first run fetches the content", etag: `"v1"`, statusCode: http.StatusOK},
+		{name: "unchanged content", etag: `"v1"`, statusCode: http.StatusNotModified, changed: &unchanged},
+		{name: "new etag", etag: `"v2"`, statusCode: http.StatusOK, changed: &changed},
+	}
+	for index, step := range steps {
+		mu.Lock()
+		etag = step.etag
+		mu.Unlock()
+
+		if err := r.dispatchResponse(context.Background(), []monitor.Monitoring{monitoring}, r.newRunState()); err != nil {
+			t.Fatalf("%s: dispatchResponse failed: %v", step.name, err)
+		}
+
+		posted := client.snapshotPostedResponses()
+		if len(posted) != index+1 {
+			t.Fatalf("%s: expected %d posted responses, got %d", step.name, index+1, len(posted))
+		}
+		payload := posted[index]
+		if payload.Status != monitor.StatusUp {
+			t.Fatalf("%s: expected up, got %s", step.name, payload.Status)
+		}
+		if payload.HTTPStatusCode == nil || *payload.HTTPStatusCode != step.statusCode {
+			t.Fatalf("%s: expected status code %d, got %v", step.name, step.statusCode, payload.HTTPStatusCode)
+		}
+		if (payload.ContentChanged == nil) != (step.changed == nil) || (step.changed != nil && *payload.ContentChanged != *step.changed) {
+			t.Fatalf("%s: expected content_changed %v, got %v", step.name, step.changed, payload.ContentChanged)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{"", `"v1"`, `"v1"`}
+	for index, value := range expected {
+		if ifNoneMatch[index] != value {
+			t.Fatalf("expected If-None-Match %q on request %d, got %q", value, index+1, ifNoneMatch[index])
+		}
+	}
+}
+
+func TestSetConditionalHeadersSkipsUnconfiguredMonitorings(t *testing.T) {
+	t.Parallel()
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	r.validators.observe("feed", httpValidators{etag: `"v1"`, lastModified: "Wed, 21 Oct 2026 07:28:00 GMT"})
+
+	testCases := []struct {
+		name       string
+		monitoring monitor.Monitoring
+		expected   bool
+	}{
+		{name: "enabled", monitoring: monitor.Monitoring{ID: "feed", Type: monitor.TypeHTTP, ConditionalRequests: true}, expected: true},
+		{name: "disabled", monitoring: monitor.Monitoring{ID: "feed", Type: monitor.TypeHTTP}},
+		{name: "keyword check", monitoring: monitor.Monitoring{ID: "feed", Type: monitor.TypeKeyword, ConditionalRequests: true}},
+	}
+
+	for _, testCase := range testCases {
+		request := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		r.setConditionalHeaders(request, testCase.monitoring)
+		sent := request.Header.Get("If-None-Match") != "" && request.Header.Get("If-Modified-Since") != ""
+		if sent != testCase.expected {
+			t.Fatalf("%s: expected conditional headers %v, got %v", testCase.name, testCase.expected, sent)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+	"github.com/m-breuer/webguard-instance-v2/internal/target"
+)
+
+const fixedDNSTimeoutSeconds = 5
+
+func (r *Runner) handleDNSMonitoring(ctx context.Context, monitoring monitor.Monitoring) (monitor.Status, *float64) {
+	host, err := target.Host(monitoring.Target)
+	if err != nil {
+		return monitor.StatusDown, nil
+	}
+
+	timeoutSeconds := fixedDNSTimeoutSeconds
+	if monitoring.Timeout > 0 {
+		timeoutSeconds = monitoring.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+
+	resolver := r.resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	start := time.Now()
+	records, err := lookupDNSRecords(ctx, resolver, host, monitoring.DNSRecordType)
+	if err != nil {
+		var dnsError *net.DNSError
+		if errors.As(err, &dnsError) && dnsError.IsNotFound {
+			return monitor.StatusDown, nil
+		}
+		if errors.As(err, &dnsError) && (dnsError.IsTimeout || dnsError.IsTemporary) {
+			return monitor.StatusUnknown, nil
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return monitor.StatusUnknown, nil
+		}
+		return monitor.StatusDown, nil
+	}
+	if records == 0 {
+		return monitor.StatusDown, nil
+	}
+
+	responseTime := roundMilliseconds(time.Since(start))
+	return monitor.StatusUp, &responseTime
+}
+
+func lookupDNSRecords(ctx context.Context, resolver *net.Resolver, host string, recordType monitor.DNSRecordType) (int, error) {
+	switch monitor.DNSRecordType(strings.ToUpper(string(recordType))) {
+	case "", monitor.DNSRecordTypeA:
+		addresses, err := resolver.LookupIP(ctx, "ip4", host)
+		return len(addresses), err
+	case monitor.DNSRecordTypeAAAA:
+		addresses, err := resolver.LookupIP(ctx, "ip6", host)
+		return len(addresses), err
+	case monitor.DNSRecordTypeCNAME:
+		canonicalName, err := resolver.LookupCNAME(ctx, host)
+		if err != nil {
+			return 0, err
+		}
+		if strings.EqualFold(strings.TrimSuffix(canonicalName, "."), strings.TrimSuffix(host, ".")) {
+			return 0, nil
+		}
+		return 1, nil
+	case monitor.DNSRecordTypeMX:
+		records, err := resolver.LookupMX(ctx, host)
+		return len(records), err
+	case monitor.DNSRecordTypeNS:
+		records, err := resolver.LookupNS(ctx, host)
+		return len(records), err
+	case monitor.DNSRecordTypeTXT:
+		records, err := resolver.LookupTXT(ctx, host)
+		return len(records), err
+	default:
+		return 0, fmt.Errorf("unsupported DNS record type %q", recordType)
+	}
+}
@@ -0,0 +1,280 @@
+package runner
+
+import (
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+const (
+	dnsTypeA     uint16 = 1
+	dnsTypeCNAME uint16 = 5
+	dnsTypeTXT   uint16 = 16
+	dnsTypeAAAA  uint16 = 28
+
+	dnsRcodeNameError = 3
+)
+
+type fakeDNSAnswer struct {
+	recordType uint16
+	data       []byte
+}
+
+type fakeDNSHandler func(name string, recordType uint16) (rcode int, answers []fakeDNSAnswer, respond bool)
+
+func startFakeDNSServer(t *testing.T, handler fakeDNSHandler) *net.Resolver {
+	t.Helper()
+
+	address := startFakeDNSListener(t, handler)
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "udp", address)
+		},
+	}
+}
+
+func startFakeDNSListener(t *testing.T, handler fakeDNSHandler) string {
+	t.Helper()
+
+	connection, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to open DNS listener: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = connection.Close()
+	})
+
+	go func() {
+		buffer := make([]byte, 1500)
+		for {
+			n, peer, err := connection.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			response := buildFakeDNSResponse(buffer[:n], handler)
+			if response != nil {
+				_, _ = connection.WriteTo(response, peer)
+			}
+		}
+	}()
+
+	return connection.LocalAddr().String()
+}
+
+func buildFakeDNSResponse(query []byte, handler fakeDNSHandler) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+
+	offset := 12
+	labels := make([]string, 0)
+	for offset < len(query) && query[offset] != 0 {
+		length := int(query[offset])
+		if offset+1+length > len(query) {
+			return nil
+		}
+		labels = append(labels, string(query[offset+1:offset+1+length]))
+		offset += 1 + length
+	}
+	offset++
+	if offset+4 > len(query) {
+		return nil
+	}
+	recordType := binary.BigEndian.Uint16(query[offset : offset+2])
+	question := query[12 : offset+4]
+
+	rcode, answers, respond := handler(strings.ToLower(strings.Join(labels, ".")), recordType)
+	if !respond {
+		return nil
+	}
+
+	response := make([]byte, 12, 512)
+	copy(response[0:2], query[0:2])
+	binary.BigEndian.PutUint16(response[2:4], 0x8180|uint16(rcode))
+	binary.BigEndian.PutUint16(response[4:6], 1)
+	binary.BigEndian.PutUint16(response[6:8], uint16(len(answers)))
+	response = append(response, question...)
+
+	for _, answer := range answers {
+		record := make([]byte, 12)
+		binary.BigEndian.PutUint16(record[0:2], 0xC00C)
+		binary.BigEndian.PutUint16(record[2:4], answer.recordType)
+		binary.BigEndian.PutUint16(record[4:6], 1)
+		binary.BigEndian.PutUint32(record[6:10], 60)
+		binary.BigEndian.PutUint16(record[10:12], uint16(len(answer.data)))
+		response = append(response, record...)
+		response = append(response, answer.data...)
+	}
+
+	return response
+}
+
+func encodeDNSName(name string) []byte {
+	encoded := make([]byte, 0, len(name)+2)
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		encoded = append(encoded, byte(len(label)))
+		encoded = append(encoded, label...)
+	}
+	return append(encoded, 0)
+}
+
+func fakeDNSZone(name string, rcode int, answers []fakeDNSAnswer, answerTypes ...uint16) fakeDNSHandler {
+	return func(queried string, recordType uint16) (int, []fakeDNSAnswer, bool) {
+		if queried != name {
+			return dnsRcodeNameError, nil, true
+		}
+		for _, answerType := range answerTypes {
+			if answerType == recordType {
+				return rcode, answers, true
+			}
+		}
+		return rcode, nil, true
+	}
+}
+
+func TestHandleDNSMonitoring(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		handler        fakeDNSHandler
+		target         string
+		recordType     monitor.DNSRecordType
+		expectedStatus monitor.Status
+	}{
+		{
+			name: "a record present",
+			handler: fakeDNSZone("service.example.test", 0, []fakeDNSAnswer{
+				{recordType: dnsTypeA, data: []byte{192, 0, 2, 10}},
+			}, dnsTypeA),
+			target:         "https://service.example.test/health",
+			recordType:     monitor.DNSRecordTypeA,
+			expectedStatus: monitor.StatusUp,
+		},
+		{
+			name: "defaults to a record",
+			handler: fakeDNSZone("service.example.test", 0, []fakeDNSAnswer{
+				{recordType: dnsTypeA, data: []byte{192, 0, 2, 10}},
+			}, dnsTypeA),
+			target:         "service.example.test",
+			expectedStatus: monitor.StatusUp,
+		},
+		{
+			name: "aaaa record present",
+			handler: fakeDNSZone("service.example.test", 0, []fakeDNSAnswer{
+				{recordType: dnsTypeAAAA, data: net.ParseIP("2001:db8::10").To16()},
+			}, dnsTypeAAAA),
+			target:         "service.example.test",
+			recordType:     monitor.DNSRecordTypeAAAA,
+			expectedStatus: monitor.StatusUp,
+		},
+		{
+			name: "aaaa record missing",
+			handler: fakeDNSZone("service.example.test", 0, []fakeDNSAnswer{
+				{recordType: dnsTypeA, data: []byte{192, 0, 2, 10}},
+			}, dnsTypeA),
+			target:         "service.example.test",
+			recordType:     monitor.DNSRecordTypeAAAA,
+			expectedStatus: monitor.StatusDown,
+		},
+		{
+			name: "cname record present",
+			handler: fakeDNSZone("alias.example.test", 0, []fakeDNSAnswer{
+				{recordType: dnsTypeCNAME, data: encodeDNSName("origin.example.net")},
+			}, dnsTypeA, dnsTypeAAAA, dnsTypeCNAME),
+			target:         "alias.example.test",
+			recordType:     monitor.DNSRecordTypeCNAME,
+			expectedStatus: monitor.StatusUp,
+		},
+		{
+			name: "cname record missing",
+			handler: fakeDNSZone("alias.example.test", 0, []fakeDNSAnswer{
+				{recordType: dnsTypeA, data: []byte{192, 0, 2, 10}},
+			}, dnsTypeA),
+			target:         "alias.example.test",
+			recordType:     monitor.DNSRecordTypeCNAME,
+			expectedStatus: monitor.StatusDown,
+		},
+		{
+			name: "txt record present",
+			handler: fakeDNSZone("service.example.test", 0, []fakeDNSAnswer{
+				{recordType: dnsTypeTXT, data: append([]byte{byte(len("v=spf1 -all"))}, "v=spf1 -all"...)},
+			}, dnsTypeTXT),
+			target:         "service.example.test",
+			recordType:     monitor.DNSRecordTypeTXT,
+			expectedStatus: monitor.StatusUp,
+		},
+		{
+			name:           "nxdomain",
+			handler:        fakeDNSZone("other.example.test", 0, nil),
+			target:         "missing.example.test",
+			recordType:     monitor.DNSRecordTypeA,
+			expectedStatus: monitor.StatusDown,
+		},
+		{
+			name:           "unsupported record type",
+			handler:        fakeDNSZone("service.example.test", 0, nil),
+			target:         "service.example.test",
+			recordType:     monitor.DNSRecordType("SOA"),
+			expectedStatus: monitor.StatusDown,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			r.resolver = startFakeDNSServer(t, testCase.handler)
+
+			status, responseTime := r.handleDNSMonitoring(context.Background(), monitor.Monitoring{
+				Type:          monitor.TypeDNS,
+				Target:        testCase.target,
+				Timeout:       2,
+				DNSRecordType: testCase.recordType,
+			})
+
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected %s, got %s", testCase.expectedStatus, status)
+			}
+			if status == monitor.StatusUp && responseTime == nil {
+				t.Fatalf("expected response time for resolved record")
+			}
+			if status != monitor.StatusUp && responseTime != nil {
+				t.Fatalf("expected nil response time, got %v", *responseTime)
+			}
+		})
+	}
+}
+
+func TestHandleDNSMonitoringResolverTimeoutIsUnknown(t *testing.T) {
+	t.Parallel()
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	r.resolver = startFakeDNSServer(t, func(string, uint16) (int, []fakeDNSAnswer, bool) {
+		return 0, nil, false
+	})
+
+	status, responseTime := r.handleDNSMonitoring(context.Background(), monitor.Monitoring{
+		Type:          monitor.TypeDNS,
+		Target:        "slow.example.test",
+		Timeout:       1,
+		DNSRecordType: monitor.DNSRecordTypeA,
+	})
+
+	if status != monitor.StatusUnknown {
+		t.Fatalf("expected unknown on resolver timeout, got %s", status)
+	}
+	if responseTime != nil {
+		t.Fatalf("expected nil response time, got %v", *responseTime)
+	}
+}
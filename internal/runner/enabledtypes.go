@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+// typeEnabled reports whether ENABLED_TYPES lets monitorings of
+// monitoringType run. Without ENABLED_TYPES every type runs.
+func (r *Runner) typeEnabled(monitoringType monitor.Type) bool {
+	return len(r.cfg.EnabledTypes) == 0 || slices.Contains(r.cfg.EnabledTypes, string(monitoringType))
+}
+
+// fetchMonitorings fetches the monitorings of those types ENABLED_TYPES
+// allows. Core is not asked at all when none of them are allowed, since an
+// empty type list would fetch every type.
+func (r *Runner) fetchMonitorings(ctx context.Context, types []monitor.Type) ([]monitor.Monitoring, error) {
+	types = slices.DeleteFunc(slices.Clone(types), func(monitoringType monitor.Type) bool {
+		return !r.typeEnabled(monitoringType)
+	})
+	if len(types) == 0 {
+		r.logger.Info("Skipping fetch, no requested monitoring type is enabled", "enabled_types", strings.Join(r.cfg.EnabledTypes, ","))
+		return nil, nil
+	}
+
+	monitorings, err := r.client.GetMonitorings(ctx, r.cfg.WebGuardLocation, types)
+	if err != nil {
+		r.logFetchError(err)
+		return nil, err
+	}
+	return r.skipDisabledTypes(monitorings), nil
+}
+
+// skipDisabledTypes drops monitorings of types ENABLED_TYPES leaves out that
+// Core returned anyway. Nothing is posted for them.
+func (r *Runner) skipDisabledTypes(monitorings []monitor.Monitoring) []monitor.Monitoring {
+	if len(r.cfg.EnabledTypes) == 0 {
+		return monitorings
+	}
+
+	enabled := make([]monitor.Monitoring, 0, len(monitorings))
+	for _, monitoring := range monitorings {
+		if r.typeEnabled(monitoring.Type) {
+			enabled = append(enabled, monitoring)
+		}
+	}
+	if skipped := len(monitorings) - len(enabled); skipped > 0 {
+		r.logger.Info(
+			"Skipping monitorings of disabled types",
+			"skipped", skipped,
+			"enabled_types", strings.Join(r.cfg.EnabledTypes, ","),
+		)
+	}
+	return enabled
+}
@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+// unfilteredCoreClient returns the same monitorings whatever types are
+// requested, like a Core that ignores the type filter.
+type unfilteredCoreClient struct {
+	*fakeCoreClient
+	monitorings []monitor.Monitoring
+}
+
+func (c *unfilteredCoreClient) GetMonitorings(ctx context.Context, location string, types []monitor.Type) ([]monitor.Monitoring, error) {
+	_, _ = c.fakeCoreClient.GetMonitorings(ctx, location, types)
+	return slices.Clone(c.monitorings), nil
+}
+
+func TestRunMonitoringSkipsDisabledTypes(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	client := &unfilteredCoreClient{
+		fakeCoreClient: &fakeCoreClient{},
+		monitorings: []monitor.Monitoring{
+			{ID: "http-1", Type: monitor.TypeHTTP, Target: server.URL, Timeout: 5},
+			{ID: "smtp-1", Type: monitor.TypeSMTP, Target: "127.0.0.1", Port: 1},
+			{ID: "icmp-1", Type: monitor.TypeICMP, Target: "127.0.0.1"},
+		},
+	}
+	r := New(client, config.Config{WebGuardLocation: "de-1", EnabledTypes: []string{"http", "dns"}}, slog.New(slog.DiscardHandler), nil)
+
+	if err := r.RunMonitoring(context.Background()); err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if len(client.calls) != 1 || !slices.Equal(client.calls[0].types, []monitor.Type{monitor.TypeHTTP, monitor.TypeDNS}) {
+		t.Fatalf("expected one fetch limited to http and dns, got %+v", client.calls)
+	}
+	if len(client.postedResponses) != 1 || client.postedResponses[0].MonitoringID != "http-1" {
+		t.Fatalf("expected only the http monitoring to be dispatched, got %+v", client.postedResponses)
+	}
+	for _, payload := range client.postedSSL {
+		if payload.MonitoringID != "http-1" {
+			t.Fatalf("expected no SSL check for disabled type, got %+v", payload)
+		}
+	}
+	if expected := len(client.postedResponses) + len(client.postedSSL); len(client.postedSummaries) != 1 || client.postedSummaries[0].Checks != expected {
+		t.Fatalf("expected run summary to count only the %d enabled checks, got %+v", expected, client.postedSummaries)
+	}
+}
+
+func TestRunMonitoringTypeSkipsFetchForDisabledType(t *testing.T) {
+	t.Parallel()
+
+	client := &unfilteredCoreClient{
+		fakeCoreClient: &fakeCoreClient{},
+		monitorings:    []monitor.Monitoring{{ID: "smtp-1", Type: monitor.TypeSMTP, Target: "127.0.0.1"}},
+	}
+	r := New(client, config.Config{WebGuardLocation: "de-1", EnabledTypes: []string{"http"}}, slog.New(slog.DiscardHandler), nil)
+
+	for _, name := range []string{"smtp", string(monitor.TypeDomainExpiration)} {
+		if err := r.RunMonitoringType(context.Background(), name); err != nil {
+			t.Fatalf("unexpected error for %s: %v", name, err)
+		}
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if len(client.calls) != 0 {
+		t.Fatalf("expected no fetch for disabled types, got %+v", client.calls)
+	}
+	if len(client.postedResponses) != 0 || len(client.postedDomains) != 0 {
+		t.Fatalf("expected nothing posted for disabled types, got %+v and %+v", client.postedResponses, client.postedDomains)
+	}
+}
@@ -0,0 +1,155 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	icmpv4Protocol = 1
+	icmpv6Protocol = 58
+)
+
+var icmpEchoer = sendICMPEcho
+
+var icmpSequence atomic.Uint32
+
+var errICMPPermission = errors.New("icmp requires ping socket or raw socket privileges")
+
+// sendICMPEcho sends one echo request to host and waits for the matching
+// reply. Hostnames are resolved with resolver.
+func sendICMPEcho(ctx context.Context, resolver *net.Resolver, host string, timeout time.Duration) (time.Duration, error) {
+	if timeout <= 0 {
+		timeout = fixedPingTimeoutSeconds * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	ip, err := resolveHostIP(ctx, resolver, host)
+	if err != nil {
+		return 0, err
+	}
+
+	isIPv4 := ip.To4() != nil
+	connection, privileged, err := listenICMP(isIPv4)
+	if err != nil {
+		return 0, err
+	}
+	defer connection.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = connection.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = connection.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	var requestType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	protocol := icmpv4Protocol
+	if !isIPv4 {
+		requestType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+		protocol = icmpv6Protocol
+	}
+
+	id := os.Getpid() & 0xffff
+	sequence := int(icmpSequence.Add(1) & 0xffff)
+	request := icmp.Message{
+		Type: requestType,
+		Body: &icmp.Echo{ID: id, Seq: sequence, Data: []byte("webguard")},
+	}
+	// The kernel fills in the ICMPv6 checksum.
+	packet, err := request.Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var destination net.Addr = &net.UDPAddr{IP: ip}
+	if privileged {
+		destination = &net.IPAddr{IP: ip}
+	}
+
+	start := time.Now()
+	if _, err := connection.WriteTo(packet, destination); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return 0, fmt.Errorf("%w: %v", errICMPPermission, err)
+		}
+		return 0, err
+	}
+
+	buffer := make([]byte, 1500)
+	for {
+		n, peer, err := connection.ReadFrom(buffer)
+		if err != nil {
+			return 0, err
+		}
+		if !peerIP(peer).Equal(ip) {
+			continue
+		}
+		reply, err := icmp.ParseMessage(protocol, buffer[:n])
+		if err != nil {
+			continue
+		}
+		// Ping sockets replace the echo ID with their own, so only raw
+		// sockets can match on it.
+		if isICMPEchoReply(reply, replyType, id, sequence, privileged) {
+			return time.Since(start), nil
+		}
+	}
+}
+
+// listenICMP prefers an unprivileged ping socket, which Linux grants to the
+// groups in net.ipv4.ping_group_range, and falls back to a raw socket. The
+// returned flag reports whether the socket is raw.
+func listenICMP(isIPv4 bool) (*icmp.PacketConn, bool, error) {
+	network, rawNetwork, address := "udp4", "ip4:icmp", "0.0.0.0"
+	if !isIPv4 {
+		network, rawNetwork, address = "udp6", "ip6:ipv6-icmp", "::"
+	}
+
+	if connection, err := icmp.ListenPacket(network, address); err == nil {
+		return connection, false, nil
+	}
+	connection, err := icmp.ListenPacket(rawNetwork, address)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return nil, false, fmt.Errorf("%w: %v", errICMPPermission, err)
+		}
+		return nil, false, err
+	}
+	return connection, true, nil
+}
+
+func peerIP(address net.Addr) net.IP {
+	switch peer := address.(type) {
+	case *net.UDPAddr:
+		return peer.IP
+	case *net.IPAddr:
+		return peer.IP
+	default:
+		return nil
+	}
+}
+
+func isICMPEchoReply(message *icmp.Message, replyType icmp.Type, id, sequence int, matchID bool) bool {
+	if message.Type != replyType || message.Code != 0 {
+		return false
+	}
+	echo, ok := message.Body.(*icmp.Echo)
+	if !ok || echo.Seq != sequence {
+		return false
+	}
+	return !matchID || echo.ID == id
+}
@@ -0,0 +1,119 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+func TestIsICMPEchoReplyMatchesIDAndSequence(t *testing.T) {
+	t.Parallel()
+
+	reply := &icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: &icmp.Echo{ID: 0x1234, Seq: 7}}
+	if !isICMPEchoReply(reply, ipv4.ICMPTypeEchoReply, 0x1234, 7, true) {
+		t.Fatalf("expected matching echo reply")
+	}
+	if isICMPEchoReply(reply, ipv4.ICMPTypeEchoReply, 0x1234, 8, true) {
+		t.Fatalf("expected sequence mismatch to be rejected")
+	}
+	if isICMPEchoReply(reply, ipv6.ICMPTypeEchoReply, 0x1234, 7, true) {
+		t.Fatalf("expected type mismatch to be rejected")
+	}
+	if isICMPEchoReply(reply, ipv4.ICMPTypeEchoReply, 0x4321, 7, true) {
+		t.Fatalf("expected id mismatch to be rejected on raw sockets")
+	}
+	if !isICMPEchoReply(reply, ipv4.ICMPTypeEchoReply, 0x4321, 7, false) {
+		t.Fatalf("expected ping sockets to ignore the rewritten id")
+	}
+}
+
+func TestHandleICMPMonitoringUp(t *testing.T) {
+	originalEchoer := icmpEchoer
+	t.Cleanup(func() {
+		icmpEchoer = originalEchoer
+	})
+
+	var receivedHost string
+	var receivedTimeout time.Duration
+	icmpEchoer = func(_ context.Context, _ *net.Resolver, host string, timeout time.Duration) (time.Duration, error) {
+		receivedHost = host
+		receivedTimeout = timeout
+		return 12340 * time.Microsecond, nil
+	}
+
+	status, responseTime := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil).handleICMPMonitoring(context.Background(), monitor.Monitoring{
+		Target:  "https://example.com/path",
+		Timeout: 2,
+	})
+	if status != monitor.StatusUp {
+		t.Fatalf("expected up, got %s", status)
+	}
+	if responseTime == nil || *responseTime != 12.34 {
+		t.Fatalf("expected response time 12.34, got %v", pointerFloat64Value(responseTime))
+	}
+	if receivedHost != "example.com" {
+		t.Fatalf("expected host example.com, got %q", receivedHost)
+	}
+	if receivedTimeout != 2*time.Second {
+		t.Fatalf("expected timeout 2s, got %s", receivedTimeout)
+	}
+}
+
+func TestHandleICMPMonitoringWithoutPrivilegesIsDown(t *testing.T) {
+	originalEchoer := icmpEchoer
+	t.Cleanup(func() {
+		icmpEchoer = originalEchoer
+	})
+
+	icmpEchoer = func(context.Context, *net.Resolver, string, time.Duration) (time.Duration, error) {
+		return 0, errICMPPermission
+	}
+
+	status, responseTime := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil).handleICMPMonitoring(context.Background(), monitor.Monitoring{
+		Target: "127.0.0.1",
+	})
+	if status != monitor.StatusDown {
+		t.Fatalf("expected down, got %s", status)
+	}
+	if responseTime != nil {
+		t.Fatalf("expected nil response time, got %v", *responseTime)
+	}
+}
+
+func TestSendICMPEchoLoopback(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		host string
+	}{
+		{name: "ipv4", host: "127.0.0.1"},
+		{name: "ipv6", host: "::1"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			latency, err := sendICMPEcho(context.Background(), nil, testCase.host, 2*time.Second)
+			if errors.Is(err, errICMPPermission) {
+				t.Skipf("raw ICMP sockets unavailable: %v", err)
+			}
+			if err != nil {
+				t.Fatalf("unexpected echo error: %v", err)
+			}
+			if latency <= 0 {
+				t.Fatalf("expected positive latency, got %s", latency)
+			}
+		})
+	}
+}
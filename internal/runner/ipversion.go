@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+	"github.com/m-breuer/webguard-instance-v2/internal/target"
+)
+
+var errIPVersionUnavailable = errors.New("target has no address of the requested IP version")
+
+// ipNetwork narrows a dial network such as "tcp" or "udp" to the address
+// family of version. Auto and unknown versions keep the network as is.
+func ipNetwork(network string, version monitor.IPVersion) string {
+	switch version {
+	case monitor.IPVersionIPv4:
+		return network + "4"
+	case monitor.IPVersionIPv6:
+		return network + "6"
+	default:
+		return network
+	}
+}
+
+// matchesIPVersion reports whether ip belongs to the family of version.
+func matchesIPVersion(ip net.IP, version monitor.IPVersion) bool {
+	switch version {
+	case monitor.IPVersionIPv4:
+		return ip.To4() != nil
+	case monitor.IPVersionIPv6:
+		return ip.To4() == nil
+	default:
+		return true
+	}
+}
+
+func forcesIPVersion(version monitor.IPVersion) bool {
+	return version == monitor.IPVersionIPv4 || version == monitor.IPVersionIPv6
+}
+
+// lookupIPVersion returns the first address of host in the family of
+// version. IP literals are returned as is when they match.
+func lookupIPVersion(ctx context.Context, resolver *net.Resolver, host string, version monitor.IPVersion) (net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		if !matchesIPVersion(ip, version) {
+			return nil, errIPVersionUnavailable
+		}
+		return ip, nil
+	}
+
+	addresses, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range addresses {
+		if matchesIPVersion(address.IP, version) {
+			return address.IP, nil
+		}
+	}
+	return nil, errIPVersionUnavailable
+}
+
+// ipVersionAvailable checks a monitoring with a forced ip_version before it
+// dials, so a target without an address of that family is reported as such
+// rather than as a generic connection failure. Lookup errors are left to the
+// check itself so they keep their usual failure reason.
+func (r *Runner) ipVersionAvailable(ctx context.Context, monitoring monitor.Monitoring) bool {
+	if !forcesIPVersion(monitoring.IPVersion) {
+		return true
+	}
+	host, err := target.Host(monitoring.Target)
+	if err != nil {
+		return true
+	}
+	if _, err := lookupIPVersion(ctx, r.resolver, host, monitoring.IPVersion); errors.Is(err, errIPVersionUnavailable) {
+		r.logger.Warn("Monitoring target has no address of the requested IP version", "monitoring_id", monitoring.ID, "host", host, "ip_version", monitoring.IPVersion)
+		return false
+	}
+	return true
+}
+
+// resolveTargetHostVersion is resolveTargetHost for checks that hand the
+// host to an external prober: a forced ip_version always resolves to an IP
+// literal of that family so ping and ICMP cannot pick the other one.
+func (r *Runner) resolveTargetHostVersion(ctx context.Context, host string, version monitor.IPVersion) (string, error) {
+	if !forcesIPVersion(version) {
+		return r.resolveTargetHost(ctx, host)
+	}
+	ip, err := lookupIPVersion(ctx, r.resolver, host, version)
+	if err != nil {
+		return "", err
+	}
+	return ip.String(), nil
+}
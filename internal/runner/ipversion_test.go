@@ -0,0 +1,228 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+// startDualStackDNS serves dual.example.test with both an A and an AAAA
+// loopback record, and v4only.example.test with an A record only.
+func startDualStackDNS(t *testing.T) string {
+	t.Helper()
+
+	return startFakeDNSListener(t, func(name string, recordType uint16) (int, []fakeDNSAnswer, bool) {
+		switch {
+		case recordType == dnsTypeA && (name == "dual.example.test" || name == "v4only.example.test"):
+			return 0, []fakeDNSAnswer{{recordType: dnsTypeA, data: net.ParseIP("127.0.0.1").To4()}}, true
+		case recordType == dnsTypeAAAA && name == "dual.example.test":
+			return 0, []fakeDNSAnswer{{recordType: dnsTypeAAAA, data: net.ParseIP("::1").To16()}}, true
+		case name == "dual.example.test" || name == "v4only.example.test":
+			return 0, nil, true
+		default:
+			return dnsRcodeNameError, nil, true
+		}
+	})
+}
+
+// listenDualStack opens a listener reachable over both 127.0.0.1 and ::1, or
+// skips the test when the host has no IPv6 loopback.
+func listenDualStack(t *testing.T) net.Listener {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Skipf("dual-stack listener unavailable: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	for _, address := range []string{net.JoinHostPort("127.0.0.1", port), net.JoinHostPort("::1", port)} {
+		conn, err := net.DialTimeout("tcp", address, time.Second)
+		if err != nil {
+			t.Skipf("dual-stack loopback unavailable: %v", err)
+		}
+		_ = conn.Close()
+	}
+	return listener
+}
+
+func TestIPNetwork(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		network  string
+		version  monitor.IPVersion
+		expected string
+	}{
+		{network: "tcp", version: "", expected: "tcp"},
+		{network: "tcp", version: monitor.IPVersionAuto, expected: "tcp"},
+		{network: "tcp", version: monitor.IPVersionIPv4, expected: "tcp4"},
+		{network: "tcp", version: monitor.IPVersionIPv6, expected: "tcp6"},
+		{network: "udp", version: monitor.IPVersionIPv6, expected: "udp6"},
+		{network: "tcp", version: "ipv5", expected: "tcp"},
+	}
+
+	for _, testCase := range testCases {
+		if got := ipNetwork(testCase.network, testCase.version); got != testCase.expected {
+			t.Fatalf("expected %q for %s/%q, got %q", testCase.expected, testCase.network, testCase.version, got)
+		}
+	}
+}
+
+func TestPortMonitoringHonorsIPVersion(t *testing.T) {
+	t.Parallel()
+
+	listener := listenDualStack(t)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	r := New(nil, config.Config{DNSResolver: startDualStackDNS(t)}, slog.New(slog.DiscardHandler), nil)
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	testCases := []struct {
+		version    monitor.IPVersion
+		expectedIP string
+	}{
+		{version: monitor.IPVersionIPv4, expectedIP: "127.0.0.1"},
+		{version: monitor.IPVersionIPv6, expectedIP: "::1"},
+	}
+
+	for _, testCase := range testCases {
+		status, _, _, details := r.checkResponseMonitoring(context.Background(), monitor.Monitoring{
+			ID:        "port-1",
+			Type:      monitor.TypePort,
+			Target:    "dual.example.test",
+			Port:      port,
+			IPVersion: testCase.version,
+		})
+		if status != monitor.StatusUp {
+			t.Fatalf("expected up over %s, got %s", testCase.version, status)
+		}
+		if details.ResolvedIP == nil || *details.ResolvedIP != testCase.expectedIP {
+			t.Fatalf("expected %s to connect to %s, got %v", testCase.version, testCase.expectedIP, pointerStringValue(details.ResolvedIP))
+		}
+	}
+}
+
+func TestHTTPMonitoringHonorsIPVersion(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var remoteIP string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		host, _, _ := net.SplitHostPort(request.RemoteAddr)
+		mu.Lock()
+		remoteIP = host
+		mu.Unlock()
+		writer.WriteHeader(http.StatusOK)
+	}))
+	_ = server.Listener.Close()
+	server.Listener = listenDualStack(t)
+	server.Start()
+	t.Cleanup(server.Close)
+
+	r := New(nil, config.Config{DNSResolver: startDualStackDNS(t)}, slog.New(slog.DiscardHandler), nil)
+	target := fmt.Sprintf("http://dual.example.test:%d/", server.Listener.Addr().(*net.TCPAddr).Port)
+
+	testCases := []struct {
+		version    monitor.IPVersion
+		expectedIP string
+	}{
+		{version: monitor.IPVersionIPv4, expectedIP: "127.0.0.1"},
+		{version: monitor.IPVersionIPv6, expectedIP: "::1"},
+	}
+
+	for _, testCase := range testCases {
+		status, _, _, _ := r.checkResponseMonitoring(context.Background(), monitor.Monitoring{
+			ID:        "http-1",
+			Type:      monitor.TypeHTTP,
+			Target:    target,
+			Timeout:   5,
+			IPVersion: testCase.version,
+		})
+		if status != monitor.StatusUp {
+			t.Fatalf("expected up over %s, got %s", testCase.version, status)
+		}
+		mu.Lock()
+		got := remoteIP
+		mu.Unlock()
+		if got != testCase.expectedIP {
+			t.Fatalf("expected %s request from %s, got %s", testCase.version, testCase.expectedIP, got)
+		}
+	}
+}
+
+func TestCheckResponseMonitoringReportsUnavailableIPVersion(t *testing.T) {
+	t.Parallel()
+
+	r := New(nil, config.Config{DNSResolver: startDualStackDNS(t)}, slog.New(slog.DiscardHandler), nil)
+
+	testCases := []struct {
+		name       string
+		monitoring monitor.Monitoring
+	}{
+		{name: "hostname without aaaa", monitoring: monitor.Monitoring{Type: monitor.TypePort, Target: "v4only.example.test", Port: 1, IPVersion: monitor.IPVersionIPv6}},
+		{name: "ipv4 literal forced to ipv6", monitoring: monitor.Monitoring{Type: monitor.TypeHTTP, Target: "http://127.0.0.1:1/", IPVersion: monitor.IPVersionIPv6}},
+		{name: "ipv6 literal forced to ipv4", monitoring: monitor.Monitoring{Type: monitor.TypePing, Target: "::1", IPVersion: monitor.IPVersionIPv4}},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			status, _, _, details := r.checkResponseMonitoring(context.Background(), testCase.monitoring)
+			if status != monitor.StatusDown {
+				t.Fatalf("expected down, got %s", status)
+			}
+			if details.FailureReason == nil || *details.FailureReason != monitor.FailureIPVersionUnavailable {
+				t.Fatalf("expected failure reason %q, got %v", monitor.FailureIPVersionUnavailable, pointerStringValue(details.FailureReason))
+			}
+		})
+	}
+}
+
+func TestHandleICMPMonitoringResolvesRequestedIPVersion(t *testing.T) {
+	originalEchoer := icmpEchoer
+	t.Cleanup(func() {
+		icmpEchoer = originalEchoer
+	})
+
+	var receivedHost string
+	icmpEchoer = func(_ context.Context, _ *net.Resolver, host string, _ time.Duration) (time.Duration, error) {
+		receivedHost = host
+		return time.Millisecond, nil
+	}
+
+	r := New(nil, config.Config{DNSResolver: startDualStackDNS(t)}, slog.New(slog.DiscardHandler), nil)
+	status, _ := r.handleICMPMonitoring(context.Background(), monitor.Monitoring{
+		Target:    "dual.example.test",
+		IPVersion: monitor.IPVersionIPv6,
+	})
+	if status != monitor.StatusUp {
+		t.Fatalf("expected up, got %s", status)
+	}
+	if receivedHost != "::1" {
+		t.Fatalf("expected ICMP echo to ::1, got %q", receivedHost)
+	}
+}
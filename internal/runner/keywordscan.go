@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+const keywordScanChunkBytes = 32 * 1024
+
+// keywordScanner searches a response body for a plain keyword while it is
+// being read, so a keyword check can stop downloading as soon as the answer
+// is known. Between chunks it keeps a tail long enough to catch a keyword
+// that straddles two reads.
+type keywordScanner struct {
+	keyword         []byte
+	caseInsensitive bool
+
+	// streamed reports whether the body went through the scanner; when it
+	// did not, the caller has to match the returned body instead.
+	streamed bool
+	found    bool
+}
+
+// newKeywordScanner returns nil for checks that need the whole body, such as
+// regex matches or an empty keyword.
+func newKeywordScanner(monitoring monitor.Monitoring) *keywordScanner {
+	if monitoring.Keyword == "" || monitoring.KeywordMode == monitor.KeywordModeRegex {
+		return nil
+	}
+
+	keyword := []byte(monitoring.Keyword)
+	if monitoring.KeywordCaseInsensitive {
+		keyword = bytes.ToLower(keyword)
+	}
+	return &keywordScanner{keyword: keyword, caseInsensitive: monitoring.KeywordCaseInsensitive}
+}
+
+// scan reads reader until the keyword shows up or the reader is exhausted
+// and returns how many bytes it consumed.
+func (s *keywordScanner) scan(reader io.Reader) (int, error) {
+	s.streamed = true
+
+	// Lowercasing can change byte lengths, so the raw tail keeps a rune of
+	// slack on top of the keyword length.
+	tailBytes := len(s.keyword) - 1 + utf8.UTFMax
+	buffer := make([]byte, keywordScanChunkBytes)
+	var tail []byte
+	consumed := 0
+	for {
+		n, err := reader.Read(buffer)
+		if n > 0 {
+			consumed += n
+			window := append(tail, buffer[:n]...)
+			haystack := window
+			if s.caseInsensitive {
+				haystack = bytes.ToLower(window)
+			}
+			if bytes.Contains(haystack, s.keyword) {
+				s.found = true
+				return consumed, nil
+			}
+			tail = append([]byte(nil), window[max(0, len(window)-tailBytes):]...)
+		}
+		if err == io.EOF {
+			return consumed, nil
+		}
+		if err != nil {
+			return consumed, err
+		}
+	}
+}
+
+// matched applies the keyword mode to a streamed scan.
+func (s *keywordScanner) matched(mode monitor.KeywordMode) bool {
+	if mode == monitor.KeywordModeAbsent {
+		return !s.found
+	}
+	return s.found
+}
@@ -0,0 +1,125 @@
+package runner
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+func TestKeywordScannerFindsKeywordAcrossChunks(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		body       string
+		monitoring monitor.Monitoring
+		expected   bool
+	}{
+		{name: "straddles reads", body: "lorem ipsum status: healthy dolor", monitoring: monitor.Monitoring{Keyword: "healthy"}, expected: true},
+		{name: "case insensitive", body: "Status: HEALTHY", monitoring: monitor.Monitoring{Keyword: "healthy", KeywordCaseInsensitive: true}, expected: true},
+		{name: "case insensitive multibyte", body: "Grüße aus MÜNCHEN", monitoring: monitor.Monitoring{Keyword: "münchen", KeywordCaseInsensitive: true}, expected: true},
+		{name: "missing", body: "status: degraded", monitoring: monitor.Monitoring{Keyword: "healthy"}, expected: false},
+		{name: "case sensitive miss", body: "HEALTHY", monitoring: monitor.Monitoring{Keyword: "healthy"}, expected: false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			scanner := newKeywordScanner(testCase.monitoring)
+			consumed, err := scanner.scan(iotest.OneByteReader(strings.NewReader(testCase.body)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if scanner.found != testCase.expected {
+				t.Fatalf("expected found=%v, got %v", testCase.expected, scanner.found)
+			}
+			if !testCase.expected && consumed != len(testCase.body) {
+				t.Fatalf("expected the whole body to be read on a miss, got %d of %d bytes", consumed, len(testCase.body))
+			}
+		})
+	}
+}
+
+func TestNewKeywordScannerSkipsRegexAndEmptyKeyword(t *testing.T) {
+	t.Parallel()
+
+	if scanner := newKeywordScanner(monitor.Monitoring{Keyword: "up.*", KeywordMode: monitor.KeywordModeRegex}); scanner != nil {
+		t.Fatalf("expected regex checks to read the whole body")
+	}
+	if scanner := newKeywordScanner(monitor.Monitoring{}); scanner != nil {
+		t.Fatalf("expected no scanner without a keyword")
+	}
+}
+
+func TestHandleKeywordMonitoringStopsReadingAfterMatch(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		mode           monitor.KeywordMode
+		expectedStatus monitor.Status
+	}{
+		{name: "contains", mode: monitor.KeywordModeContains, expectedStatus: monitor.StatusUp},
+		{name: "absent", mode: monitor.KeywordModeAbsent, expectedStatus: monitor.StatusDown},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			// The server would stream for ten seconds; it only stops early
+			// when the client drops the connection.
+			stopped := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				defer close(stopped)
+				flusher := writer.(http.Flusher)
+				_, _ = writer.Write([]byte("<html><body>service healthy"))
+				flusher.Flush()
+
+				filler := []byte(strings.Repeat("x", 32*1024))
+				deadline := time.Now().Add(10 * time.Second)
+				for time.Now().Before(deadline) {
+					if _, err := writer.Write(filler); err != nil {
+						return
+					}
+					flusher.Flush()
+					time.Sleep(10 * time.Millisecond)
+				}
+			}))
+			t.Cleanup(server.Close)
+
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			start := time.Now()
+			status, _, _, _ := r.handleKeywordMonitoring(context.Background(), monitor.Monitoring{
+				Target:      server.URL,
+				Timeout:     15,
+				HTTPMethod:  monitor.HTTPMethodGet,
+				Keyword:     "healthy",
+				KeywordMode: testCase.mode,
+			})
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected %s, got %s", testCase.expectedStatus, status)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Fatalf("expected the check to stop reading after the match, took %s", elapsed)
+			}
+
+			select {
+			case <-stopped:
+			case <-time.After(3 * time.Second):
+				t.Fatalf("expected the server to see the connection close after the match")
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"crypto/x509"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// stapledRevocation reads a stapled OCSP response and reports whether it
+// marks certificate as revoked. The response must be signed by issuer, or a
+// responder it delegated to, and be current at now. It returns nil when the
+// staple is missing, does not verify, says "unknown" or is outside its
+// thisUpdate/nextUpdate window.
+func stapledRevocation(staple []byte, certificate, issuer *x509.Certificate, now time.Time) *bool {
+	if len(staple) == 0 || certificate == nil || issuer == nil {
+		return nil
+	}
+
+	response, err := ocsp.ParseResponseForCert(staple, certificate, issuer)
+	if err != nil {
+		return nil
+	}
+	if now.Before(response.ThisUpdate) || (!response.NextUpdate.IsZero() && now.After(response.NextUpdate)) {
+		return nil
+	}
+
+	switch response.Status {
+	case ocsp.Good:
+		revoked := false
+		return &revoked
+	case ocsp.Revoked:
+		revoked := true
+		return &revoked
+	default:
+		return nil
+	}
+}
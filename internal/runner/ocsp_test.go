@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+	"golang.org/x/crypto/ocsp"
+)
+
+// buildOCSPStaple returns an OCSP response for serial signed by signer,
+// valid from thisUpdate until nextUpdate.
+func buildOCSPStaple(t *testing.T, signer *x509.Certificate, signerKey crypto.Signer, serial int64, status int, thisUpdate, nextUpdate time.Time) []byte {
+	t.Helper()
+
+	template := ocsp.Response{
+		Status:       status,
+		SerialNumber: big.NewInt(serial),
+		ThisUpdate:   thisUpdate,
+		NextUpdate:   nextUpdate,
+	}
+	if status == ocsp.Revoked {
+		template.RevokedAt = thisUpdate.Add(-time.Hour)
+	}
+	staple, err := ocsp.CreateResponse(signer, signer, template, signerKey)
+	if err != nil {
+		t.Fatalf("failed to create OCSP response: %v", err)
+	}
+	return staple
+}
+
+func TestStapledRevocation(t *testing.T) {
+	t.Parallel()
+
+	ca, caKey, _ := newTestCA(t, "WebGuard Test CA")
+	otherCA, otherCAKey, _ := newTestCA(t, "Unrelated CA")
+	leaf, err := x509.ParseCertificate(issueTestCertificate(t, ca, caKey).Certificate[0])
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	serial := leaf.SerialNumber.Int64()
+
+	now := time.Now()
+	thisUpdate := now.Add(-time.Hour)
+	nextUpdate := now.Add(time.Hour)
+
+	testCases := []struct {
+		name     string
+		staple   []byte
+		issuer   *x509.Certificate
+		expected *bool
+	}{
+		{name: "good", staple: buildOCSPStaple(t, ca, caKey, serial, ocsp.Good, thisUpdate, nextUpdate), issuer: ca, expected: new(bool)},
+		{name: "revoked", staple: buildOCSPStaple(t, ca, caKey, serial, ocsp.Revoked, thisUpdate, nextUpdate), issuer: ca, expected: func() *bool { revoked := true; return &revoked }()},
+		{name: "unknown", staple: buildOCSPStaple(t, ca, caKey, serial, ocsp.Unknown, thisUpdate, nextUpdate), issuer: ca},
+		{name: "another serial", staple: buildOCSPStaple(t, ca, caKey, serial+1, ocsp.Revoked, thisUpdate, nextUpdate), issuer: ca},
+		{name: "signed by another CA", staple: buildOCSPStaple(t, otherCA, otherCAKey, serial, ocsp.Revoked, thisUpdate, nextUpdate), issuer: ca},
+		{name: "without issuer", staple: buildOCSPStaple(t, ca, caKey, serial, ocsp.Revoked, thisUpdate, nextUpdate)},
+		{name: "expired", staple: buildOCSPStaple(t, ca, caKey, serial, ocsp.Good, now.Add(-2*time.Hour), now.Add(-time.Hour)), issuer: ca},
+		{name: "not yet current", staple: buildOCSPStaple(t, ca, caKey, serial, ocsp.Good, now.Add(time.Hour), now.Add(2*time.Hour)), issuer: ca},
+		{name: "no staple", issuer: ca},
+		{name: "malformed", staple: []byte("not an ocsp response"), issuer: ca},
+	}
+
+	for _, testCase := range testCases {
+		got := stapledRevocation(testCase.staple, leaf, testCase.issuer, now)
+		switch {
+		case testCase.expected == nil && got != nil:
+			t.Fatalf("%s: expected no revocation status, got %v", testCase.name, *got)
+		case testCase.expected != nil && (got == nil || *got != *testCase.expected):
+			t.Fatalf("%s: expected revoked=%v, got %v", testCase.name, *testCase.expected, got)
+		}
+	}
+}
+
+func TestCrawlMonitoringSSLReportsStapledRevocation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name            string
+		status          int
+		noStaple        bool
+		expectedRevoked *bool
+		expectedValid   bool
+	}{
+		{name: "stapled good", status: ocsp.Good, expectedRevoked: new(bool), expectedValid: true},
+		{name: "stapled revoked", status: ocsp.Revoked, expectedRevoked: func() *bool { revoked := true; return &revoked }()},
+		{name: "no staple", noStaple: true, expectedValid: true},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			now := time.Now()
+			ca, caKey, _ := newTestCA(t, "WebGuard Test CA")
+			var staple []byte
+			if !testCase.noStaple {
+				staple = buildOCSPStaple(t, ca, caKey, 1, testCase.status, now.Add(-time.Hour), now.Add(time.Hour))
+			}
+			targetURL := startTLSServerWithOCSPStaple(t, ca, caKey, now.Add(-time.Hour), now.Add(30*24*time.Hour), staple)
+
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			r.rootCAs = x509.NewCertPool()
+			r.rootCAs.AddCert(ca)
+			payload := r.crawlMonitoringSSL(context.Background(), monitor.Monitoring{ID: "ocsp", Target: targetURL})
+
+			switch {
+			case testCase.expectedRevoked == nil && payload.Revoked != nil:
+				t.Fatalf("expected revoked to be unset, got %v", *payload.Revoked)
+			case testCase.expectedRevoked != nil && (payload.Revoked == nil || *payload.Revoked != *testCase.expectedRevoked):
+				t.Fatalf("expected revoked=%v, got %v", *testCase.expectedRevoked, payload.Revoked)
+			}
+			if payload.IsValid != testCase.expectedValid {
+				t.Fatalf("expected is_valid=%v, got %v (failure %v)", testCase.expectedValid, payload.IsValid, pointerStringValue(payload.FailureReason))
+			}
+			if !testCase.expectedValid && (payload.FailureReason == nil || *payload.FailureReason != monitor.SSLFailureRevoked) {
+				t.Fatalf("expected failure reason %s, got %v", monitor.SSLFailureRevoked, pointerStringValue(payload.FailureReason))
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"sync"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+// postedStatus is the status last posted for a monitoring and how many runs
+// have passed since.
+type postedStatus struct {
+	status    monitor.Status
+	runsSince int
+}
+
+// postedStore decides which response results POST_ON_CHANGE_ONLY lets
+// through. Like statusStore it lives only in memory, so the first result
+// after a restart is always posted.
+type postedStore struct {
+	mu       sync.Mutex
+	statuses map[string]postedStatus
+}
+
+func newPostedStore() *postedStore {
+	return &postedStore{statuses: make(map[string]postedStatus)}
+}
+
+// shouldPost reports whether a result with status should be posted. First
+// seen and changed statuses always are; an unchanged one is posted again
+// once heartbeatEvery runs have passed since the last post, and never when
+// heartbeatEvery is 0. It records nothing: markPosted does that once the post
+// went through, so a failed post is retried on the next run.
+func (s *postedStore) shouldPost(monitoringID string, status monitor.Status, heartbeatEvery int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last, seen := s.statuses[monitoringID]
+	if !seen || last.status != status {
+		return true
+	}
+	return heartbeatEvery > 0 && last.runsSince+1 >= heartbeatEvery
+}
+
+// markSkipped counts a run whose unchanged result was not posted.
+func (s *postedStore) markSkipped(monitoringID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, seen := s.statuses[monitoringID]; seen {
+		last.runsSince++
+		s.statuses[monitoringID] = last
+	}
+}
+
+// markPosted records status as the last one Core received.
+func (s *postedStore) markPosted(monitoringID string, status monitor.Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.statuses[monitoringID] = postedStatus{status: status}
+}
+
+// markResponsesPosted records the statuses of posted response results for
+// POST_ON_CHANGE_ONLY.
+func (r *Runner) markResponsesPosted(payloads ...monitor.MonitoringResponsePayload) {
+	if !r.cfg.PostOnChangeOnly {
+		return
+	}
+	for _, payload := range payloads {
+		r.posted.markPosted(payload.MonitoringID, payload.Status)
+	}
+}
@@ -0,0 +1,236 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+func TestPostedStoreShouldPost(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		heartbeatEvery int
+		statuses       []monitor.Status
+		expected       []bool
+	}{
+		{
+			name:           "first seen and changes post",
+			heartbeatEvery: 0,
+			statuses:       []monitor.Status{monitor.StatusUp, monitor.StatusUp, monitor.StatusDown, monitor.StatusDown, monitor.StatusUp},
+			expected:       []bool{true, false, true, false, true},
+		},
+		{
+			name:           "heartbeat every third run",
+			heartbeatEvery: 3,
+			statuses:       []monitor.Status{monitor.StatusUp, monitor.StatusUp, monitor.StatusUp, monitor.StatusUp, monitor.StatusUp, monitor.StatusUp, monitor.StatusUp},
+			expected:       []bool{true, false, false, true, false, false, true},
+		},
+		{
+			name:           "change resets heartbeat",
+			heartbeatEvery: 2,
+			statuses:       []monitor.Status{monitor.StatusUp, monitor.StatusUp, monitor.StatusDown, monitor.StatusDown, monitor.StatusDown},
+			expected:       []bool{true, false, true, false, true},
+		},
+		{
+			name:           "heartbeat of one posts every run",
+			heartbeatEvery: 1,
+			statuses:       []monitor.Status{monitor.StatusDown, monitor.StatusDown, monitor.StatusDown},
+			expected:       []bool{true, true, true},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := newPostedStore()
+			for index, status := range testCase.statuses {
+				got := store.shouldPost("mon-1", status, testCase.heartbeatEvery)
+				if got != testCase.expected[index] {
+					t.Fatalf("expected shouldPost %t for result %d (%s), got %t", testCase.expected[index], index, status, got)
+				}
+				if got {
+					store.markPosted("mon-1", status)
+				} else {
+					store.markSkipped("mon-1")
+				}
+			}
+		})
+	}
+}
+
+func TestRunResponsePostsUnchangedStatusOnlyAsHeartbeat(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{ID: "closed-port", Type: monitor.TypePort, Target: "127.0.0.1", Port: 1},
+		},
+	}
+	r := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1, PostOnChangeOnly: true, PostHeartbeatEveryRuns: 2}, slog.New(slog.DiscardHandler), nil)
+
+	for run := 1; run <= 3; run++ {
+		if err := r.runResponse(context.Background()); err != nil {
+			t.Fatalf("run %d failed: %v", run, err)
+		}
+		if run == 2 {
+			if responses := client.snapshotPostedResponses(); len(responses) != 1 {
+				t.Fatalf("expected the unchanged second result to be skipped, got %d posts", len(responses))
+			}
+		}
+	}
+
+	responses := client.snapshotPostedResponses()
+	if len(responses) != 2 {
+		t.Fatalf("expected one post plus one heartbeat, got %d posts", len(responses))
+	}
+	for _, response := range responses {
+		if response.Status != monitor.StatusDown {
+			t.Fatalf("expected down results, got %s", response.Status)
+		}
+	}
+}
+
+func TestRunResponsePostsStatusChangeWithPostOnChangeOnly(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to open listener: %v", err)
+	}
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{ID: "port", Type: monitor.TypePort, Target: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port},
+		},
+	}
+	r := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1, PostOnChangeOnly: true}, slog.New(slog.DiscardHandler), nil)
+
+	for run := 1; run <= 3; run++ {
+		if run == 3 {
+			_ = listener.Close()
+		}
+		if err := r.runResponse(context.Background()); err != nil {
+			t.Fatalf("run %d failed: %v", run, err)
+		}
+	}
+
+	responses := client.snapshotPostedResponses()
+	if len(responses) != 2 {
+		t.Fatalf("expected the first result and the change to be posted, got %d posts", len(responses))
+	}
+	if responses[0].Status != monitor.StatusUp || responses[1].Status != monitor.StatusDown {
+		t.Fatalf("expected up then down, got %s then %s", responses[0].Status, responses[1].Status)
+	}
+}
+
+// flakyPostClient fails the response posts whose 1-based number is listed in
+// failing.
+type flakyPostClient struct {
+	fakeCoreClient
+
+	mu      sync.Mutex
+	posts   int
+	failing map[int]bool
+}
+
+func (c *flakyPostClient) PostMonitoringResponse(ctx context.Context, payload monitor.MonitoringResponsePayload) error {
+	c.mu.Lock()
+	c.posts++
+	fail := c.failing[c.posts]
+	c.mu.Unlock()
+	if fail {
+		return errors.New("core unavailable")
+	}
+	return c.fakeCoreClient.PostMonitoringResponse(ctx, payload)
+}
+
+func TestRunResponseRetriesFailedStatusChangePost(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to open listener: %v", err)
+	}
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	client := &flakyPostClient{
+		fakeCoreClient: fakeCoreClient{
+			responseMonitorings: []monitor.Monitoring{
+				{ID: "port", Type: monitor.TypePort, Target: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port},
+			},
+		},
+		failing: map[int]bool{2: true},
+	}
+	r := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1, PostOnChangeOnly: true}, slog.New(slog.DiscardHandler), nil)
+
+	for run := 1; run <= 4; run++ {
+		if run == 2 {
+			_ = listener.Close()
+		}
+		if err := r.runResponse(context.Background()); err != nil {
+			t.Fatalf("run %d failed: %v", run, err)
+		}
+	}
+
+	responses := client.snapshotPostedResponses()
+	if len(responses) != 2 {
+		t.Fatalf("expected the first result and the retried change to be posted, got %d posts", len(responses))
+	}
+	if responses[0].Status != monitor.StatusUp || responses[1].Status != monitor.StatusDown {
+		t.Fatalf("expected up then down, got %s then %s", responses[0].Status, responses[1].Status)
+	}
+}
+
+func TestCollectMonitoringIgnoresPostOnChangeOnly(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{ID: "closed-port", Type: monitor.TypePort, Target: "127.0.0.1", Port: 1},
+		},
+	}
+	r := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1, PostOnChangeOnly: true}, slog.New(slog.DiscardHandler), nil)
+
+	for run := 1; run <= 2; run++ {
+		results, err := r.CollectMonitoring(context.Background(), "")
+		if err != nil {
+			t.Fatalf("collect %d failed: %v", run, err)
+		}
+		responses := 0
+		for _, result := range results {
+			if result.Type == string(monitor.TypePort) {
+				responses++
+			}
+		}
+		if responses != 1 {
+			t.Fatalf("expected the response result to be collected in run %d, got %+v", run, results)
+		}
+	}
+}
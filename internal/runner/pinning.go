@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sync"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+	"github.com/m-breuer/webguard-instance-v2/internal/target"
+)
+
+// ipSetStore remembers the IP set each pinned monitoring's target resolved
+// to on its last check. Like statusStore it lives only in memory, so the
+// first check after a restart establishes the baseline again.
+type ipSetStore struct {
+	mu   sync.Mutex
+	sets map[string][]string
+}
+
+func newIPSetStore() *ipSetStore {
+	return &ipSetStore{sets: make(map[string][]string)}
+}
+
+// observe records ips and returns the previous set when it differs. The
+// first observation never counts as a change.
+func (s *ipSetStore) observe(monitoringID string, ips []string) ([]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, seen := s.sets[monitoringID]
+	s.sets[monitoringID] = ips
+	if !seen || slices.Equal(previous, ips) {
+		return nil, false
+	}
+	return previous, true
+}
+
+// checkResolvedIPs resolves the target of a monitoring with pinning enabled
+// and reports whether its IP set changed since the previous check. It returns
+// nil when pinning is off or there is no set to compare, such as for IP
+// literal targets or failed lookups.
+func (r *Runner) checkResolvedIPs(ctx context.Context, monitoring monitor.Monitoring) *bool {
+	if !monitoring.PinResolvedIPs || !dialsTarget(monitoring.Type) {
+		return nil
+	}
+	host, err := target.Host(monitoring.Target)
+	if err != nil || net.ParseIP(host) != nil {
+		return nil
+	}
+
+	addresses, err := r.resolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addresses) == 0 {
+		r.logger.Warn("Failed to resolve pinned monitoring target", "monitoring_id", monitoring.ID, "host", host, "error", err)
+		return nil
+	}
+	ips := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		ips = append(ips, address.IP.String())
+	}
+	slices.Sort(ips)
+	ips = slices.Compact(ips)
+
+	previous, changed := r.resolvedIPs.observe(monitoring.ID, ips)
+	if changed {
+		r.logger.Warn(
+			"Resolved IP set of monitoring target changed",
+			"monitoring_id", monitoring.ID,
+			"host", host,
+			"previous_ips", previous,
+			"current_ips", ips,
+		)
+	}
+	return &changed
+}
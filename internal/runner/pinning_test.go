@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+func TestCheckResolvedIPsDetectsChangedIPSet(t *testing.T) {
+	t.Parallel()
+
+	var moved atomic.Bool
+	original := fakeDNSZone("service.example.test", 0, []fakeDNSAnswer{
+		{recordType: dnsTypeA, data: []byte{192, 0, 2, 10}},
+		{recordType: dnsTypeA, data: []byte{192, 0, 2, 11}},
+	}, dnsTypeA)
+	hijacked := fakeDNSZone("service.example.test", 0, []fakeDNSAnswer{
+		{recordType: dnsTypeA, data: []byte{203, 0, 113, 66}},
+	}, dnsTypeA)
+	address := startFakeDNSListener(t, func(name string, recordType uint16) (int, []fakeDNSAnswer, bool) {
+		if moved.Load() {
+			return hijacked(name, recordType)
+		}
+		return original(name, recordType)
+	})
+
+	r := New(nil, config.Config{DNSResolver: address}, slog.New(slog.DiscardHandler), nil)
+	monitoring := monitor.Monitoring{
+		ID:             "pinned",
+		Type:           monitor.TypePort,
+		Target:         "service.example.test",
+		Port:           443,
+		PinResolvedIPs: true,
+	}
+
+	steps := []struct {
+		name     string
+		move     bool
+		expected bool
+	}{
+		{name: "first run sets the baseline", expected: false},
+		{name: "same set", expected: false},
+		{name: "changed set", move: true, expected: true},
+		{name: "new set is the baseline", move: true, expected: false},
+	}
+	for _, step := range steps {
+		moved.Store(step.move)
+		changed := r.checkResolvedIPs(context.Background(), monitoring)
+		if changed == nil || *changed != step.expected {
+			t.Fatalf("%s: expected changed=%v, got %v", step.name, step.expected, pointerBoolValue(changed))
+		}
+	}
+}
+
+func TestCheckResolvedIPsSkipsUnpinnedAndLiteralTargets(t *testing.T) {
+	t.Parallel()
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	testCases := []struct {
+		name       string
+		monitoring monitor.Monitoring
+	}{
+		{name: "pinning disabled", monitoring: monitor.Monitoring{ID: "plain", Type: monitor.TypeHTTP, Target: "https://example.com"}},
+		{name: "ip literal", monitoring: monitor.Monitoring{ID: "literal", Type: monitor.TypePort, Target: "192.0.2.10", PinResolvedIPs: true}},
+		{name: "dns check", monitoring: monitor.Monitoring{ID: "dns", Type: monitor.TypeDNS, Target: "example.com", PinResolvedIPs: true}},
+	}
+
+	for _, testCase := range testCases {
+		if changed := r.checkResolvedIPs(context.Background(), testCase.monitoring); changed != nil {
+			t.Fatalf("%s: expected no pinning result, got %v", testCase.name, *changed)
+		}
+	}
+}
+
+func TestRunResponsePostsResolvedIPChangeAcrossRuns(t *testing.T) {
+	t.Parallel()
+
+	var moved atomic.Bool
+	original := fakeDNSZone("service.example.test", 0, []fakeDNSAnswer{
+		{recordType: dnsTypeA, data: []byte{127, 0, 0, 1}},
+	}, dnsTypeA)
+	movedZone := fakeDNSZone("service.example.test", 0, []fakeDNSAnswer{
+		{recordType: dnsTypeA, data: []byte{127, 0, 0, 2}},
+	}, dnsTypeA)
+	address := startFakeDNSListener(t, func(name string, recordType uint16) (int, []fakeDNSAnswer, bool) {
+		if moved.Load() {
+			return movedZone(name, recordType)
+		}
+		return original(name, recordType)
+	})
+
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{ID: "pinned", Type: monitor.TypePort, Target: "service.example.test", Port: 1, PinResolvedIPs: true},
+		},
+	}
+	r := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1, DNSResolver: address}, slog.New(slog.DiscardHandler), nil)
+
+	if err := r.runResponse(context.Background()); err != nil {
+		t.Fatalf("first run failed: %v", err)
+	}
+	moved.Store(true)
+	if err := r.runResponse(context.Background()); err != nil {
+		t.Fatalf("second run failed: %v", err)
+	}
+
+	responses := client.snapshotPostedResponses()
+	if len(responses) != 2 {
+		t.Fatalf("expected two posted responses, got %d", len(responses))
+	}
+	if changed := responses[0].ResolvedIPsChanged; changed == nil || *changed {
+		t.Fatalf("expected the first run to report an unchanged IP set, got %v", pointerBoolValue(changed))
+	}
+	if changed := responses[1].ResolvedIPsChanged; changed == nil || !*changed {
+		t.Fatalf("expected the second run to report a changed IP set, got %v", pointerBoolValue(changed))
+	}
+}
@@ -0,0 +1,27 @@
+package runner
+
+import (
+	"math"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+// maxProbeCount caps probe_count so a single monitoring cannot hold a worker
+// for many timeouts in a row.
+const maxProbeCount = 10
+
+// probeCount returns how many probes a ping, ICMP or TCP port check sends.
+func probeCount(monitoring monitor.Monitoring) int {
+	return min(max(monitoring.ProbeCount, 1), maxProbeCount)
+}
+
+// averageLatency returns the mean of latencies in milliseconds, rounded to
+// the precision of a single measurement.
+func averageLatency(latencies []float64) *float64 {
+	var total float64
+	for _, latency := range latencies {
+		total += latency
+	}
+	average := math.Round(total/float64(len(latencies))*1000) / 1000
+	return &average
+}
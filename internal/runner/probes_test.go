@@ -0,0 +1,214 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+func TestProbeCount(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		probeCount int
+		expected   int
+	}{
+		{probeCount: -1, expected: 1},
+		{probeCount: 0, expected: 1},
+		{probeCount: 1, expected: 1},
+		{probeCount: 5, expected: 5},
+		{probeCount: 50, expected: maxProbeCount},
+	}
+
+	for _, testCase := range testCases {
+		if got := probeCount(monitor.Monitoring{ProbeCount: testCase.probeCount}); got != testCase.expected {
+			t.Fatalf("expected %d probes for probe_count %d, got %d", testCase.expected, testCase.probeCount, got)
+		}
+	}
+}
+
+func TestHandlePingMonitoringAveragesProbes(t *testing.T) {
+	originalExecutor := pingExecutor
+	t.Cleanup(func() {
+		pingExecutor = originalExecutor
+	})
+
+	testCases := []struct {
+		name             string
+		failures         []bool
+		expectedStatus   monitor.Status
+		expectedResponse float64
+	}{
+		{name: "all succeed", failures: []bool{false, false, false}, expectedStatus: monitor.StatusUp, expectedResponse: 20},
+		{name: "failures discarded", failures: []bool{false, true, false}, expectedStatus: monitor.StatusUp, expectedResponse: 20},
+		{name: "all fail", failures: []bool{true, true, true}, expectedStatus: monitor.StatusDown, expectedResponse: 30},
+	}
+
+	for _, testCase := range testCases {
+		calls := 0
+		pingExecutor = func(_ context.Context, host string, _ int) ([]byte, error) {
+			failed := testCase.failures[calls]
+			calls++
+			output := []byte(fmt.Sprintf("64 bytes from %s: icmp_seq=1 ttl=57 time=%d ms", host, calls*10))
+			if failed {
+				return output, errors.New("exit status 1")
+			}
+			return output, nil
+		}
+
+		r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+		status, responseTime, _ := r.handlePingMonitoring(context.Background(), monitor.Monitoring{Target: "192.0.2.10", ProbeCount: 3})
+
+		if calls != 3 {
+			t.Fatalf("%s: expected 3 probes, got %d", testCase.name, calls)
+		}
+		if status != testCase.expectedStatus {
+			t.Fatalf("%s: expected %s, got %s", testCase.name, testCase.expectedStatus, status)
+		}
+		if responseTime == nil || *responseTime != testCase.expectedResponse {
+			t.Fatalf("%s: expected response time %v, got %v", testCase.name, testCase.expectedResponse, pointerFloat64Value(responseTime))
+		}
+	}
+}
+
+func TestHandleICMPMonitoringAveragesProbes(t *testing.T) {
+	originalEchoer := icmpEchoer
+	t.Cleanup(func() {
+		icmpEchoer = originalEchoer
+	})
+
+	latencies := []time.Duration{10 * time.Millisecond, 0, 40 * time.Millisecond}
+	calls := 0
+	icmpEchoer = func(context.Context, *net.Resolver, string, time.Duration) (time.Duration, error) {
+		latency := latencies[calls]
+		calls++
+		if latency == 0 {
+			return 0, errors.New("timeout")
+		}
+		return latency, nil
+	}
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	status, responseTime := r.handleICMPMonitoring(context.Background(), monitor.Monitoring{Target: "192.0.2.10", ProbeCount: 3})
+	if status != monitor.StatusUp {
+		t.Fatalf("expected up, got %s", status)
+	}
+	if responseTime == nil || *responseTime != 25 {
+		t.Fatalf("expected average of the successful probes 25, got %v", pointerFloat64Value(responseTime))
+	}
+
+	icmpEchoer = func(context.Context, *net.Resolver, string, time.Duration) (time.Duration, error) {
+		return 0, errors.New("timeout")
+	}
+	if status, _ := r.handleICMPMonitoring(context.Background(), monitor.Monitoring{Target: "192.0.2.10", ProbeCount: 3}); status != monitor.StatusDown {
+		t.Fatalf("expected down when every probe fails, got %s", status)
+	}
+}
+
+func TestHandlePortMonitoringDialsProbeCountTimes(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	var accepted atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			_ = conn.Close()
+		}
+	}()
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	status, responseTime, _ := r.handlePortMonitoring(context.Background(), monitor.Monitoring{
+		Target:     "127.0.0.1",
+		Port:       listener.Addr().(*net.TCPAddr).Port,
+		Timeout:    2,
+		ProbeCount: 3,
+	})
+	if status != monitor.StatusUp || responseTime == nil {
+		t.Fatalf("expected up with a response time, got %s", status)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for accepted.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := accepted.Load(); got != 3 {
+		t.Fatalf("expected 3 connections, got %d", got)
+	}
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	_ = closed.Close()
+
+	status, _, details := r.handlePortMonitoring(context.Background(), monitor.Monitoring{Target: "127.0.0.1", Port: closedPort, Timeout: 2, ProbeCount: 3})
+	if status != monitor.StatusDown {
+		t.Fatalf("expected down when every probe fails, got %s", status)
+	}
+	if details.FailureReason == nil || *details.FailureReason != monitor.PortFailureConnectionRefused {
+		t.Fatalf("expected failure reason %q, got %v", monitor.PortFailureConnectionRefused, pointerStringValue(details.FailureReason))
+	}
+}
+
+func TestHandlePortMonitoringStopsOnCancelledContext(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	var accepted atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			_ = conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	status, _, details := r.handlePortMonitoring(ctx, monitor.Monitoring{
+		Target:     "127.0.0.1",
+		Port:       listener.Addr().(*net.TCPAddr).Port,
+		Timeout:    2,
+		ProbeCount: 3,
+	})
+	if status != monitor.StatusDown {
+		t.Fatalf("expected down once the phase is cancelled, got %s", status)
+	}
+	if details.FailureReason == nil || *details.FailureReason == "" {
+		t.Fatalf("expected a failure reason, got %v", pointerStringValue(details.FailureReason))
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := accepted.Load(); got != 0 {
+		t.Fatalf("expected no probes after cancellation, got %d connections", got)
+	}
+}
@@ -0,0 +1,186 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const fixedDoHTimeoutSeconds = 10
+const maxDNSMessageBytes = 65535
+
+func newResolver(spec string) (*net.Resolver, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return net.DefaultResolver, nil
+	}
+
+	if strings.HasPrefix(spec, "https://") || strings.HasPrefix(spec, "http://") {
+		endpoint, err := url.Parse(spec)
+		if err != nil || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid DNS-over-HTTPS resolver %q", spec)
+		}
+		client := &http.Client{Timeout: fixedDoHTimeoutSeconds * time.Second}
+		return &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return &dohConn{ctx: ctx, client: client, endpoint: endpoint.String()}, nil
+			},
+		}, nil
+	}
+
+	address := spec
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(strings.Trim(spec, "[]"), "53")
+	}
+	if host, _, err := net.SplitHostPort(address); err != nil || host == "" {
+		return nil, fmt.Errorf("invalid DNS resolver %q", spec)
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, address)
+		},
+	}, nil
+}
+
+func resolveHostIP(ctx context.Context, resolver *net.Resolver, host string) (net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip, nil
+	}
+
+	addresses, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range addresses {
+		if address.IP.To4() != nil {
+			return address.IP, nil
+		}
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+	return addresses[0].IP, nil
+}
+
+// dohConn implements net.PacketConn so the Go resolver uses datagram framing:
+// each Write carries one DNS query, answered by one POST to the DoH endpoint.
+type dohConn struct {
+	ctx      context.Context
+	client   *http.Client
+	endpoint string
+
+	mu       sync.Mutex
+	deadline time.Time
+	pending  []byte
+}
+
+func (c *dohConn) Write(query []byte) (int, error) {
+	c.mu.Lock()
+	deadline := c.deadline
+	c.mu.Unlock()
+
+	ctx := c.ctx
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(query))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/dns-message")
+	request.Header.Set("Accept", "application/dns-message")
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("DNS-over-HTTPS resolver returned status %d", response.StatusCode)
+	}
+
+	answer, err := io.ReadAll(io.LimitReader(response.Body, maxDNSMessageBytes))
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.pending = answer
+	c.mu.Unlock()
+	return len(query), nil
+}
+
+func (c *dohConn) Read(buffer []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pending == nil {
+		return 0, io.EOF
+	}
+	n := copy(buffer, c.pending)
+	c.pending = nil
+	return n, nil
+}
+
+func (c *dohConn) ReadFrom(buffer []byte) (int, net.Addr, error) {
+	n, err := c.Read(buffer)
+	return n, c.RemoteAddr(), err
+}
+
+func (c *dohConn) WriteTo(query []byte, _ net.Addr) (int, error) {
+	return c.Write(query)
+}
+
+func (c *dohConn) Close() error {
+	return nil
+}
+
+func (c *dohConn) LocalAddr() net.Addr {
+	return dohAddr{}
+}
+
+func (c *dohConn) RemoteAddr() net.Addr {
+	return dohAddr{endpoint: c.endpoint}
+}
+
+func (c *dohConn) SetDeadline(deadline time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deadline = deadline
+	return nil
+}
+
+func (c *dohConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c *dohConn) SetWriteDeadline(deadline time.Time) error {
+	return c.SetDeadline(deadline)
+}
+
+type dohAddr struct {
+	endpoint string
+}
+
+func (a dohAddr) Network() string {
+	return "doh"
+}
+
+func (a dohAddr) String() string {
+	return a.endpoint
+}
@@ -0,0 +1,190 @@
+package runner
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+func TestNewResolver(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		spec         string
+		expectErr    bool
+		expectShared bool
+	}{
+		{name: "empty uses system resolver", spec: "", expectShared: true},
+		{name: "host without port", spec: "8.8.8.8"},
+		{name: "host with port", spec: "127.0.0.1:5353"},
+		{name: "ipv6 host", spec: "[2001:4860:4860::8888]:53"},
+		{name: "doh endpoint", spec: "https://dns.google/dns-query"},
+		{name: "doh without host", spec: "https://", expectErr: true},
+		{name: "missing host", spec: ":53", expectErr: true},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			resolver, err := newResolver(testCase.spec)
+			if testCase.expectErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", testCase.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if (resolver == net.DefaultResolver) != testCase.expectShared {
+				t.Fatalf("expected shared system resolver %v for %q", testCase.expectShared, testCase.spec)
+			}
+		})
+	}
+}
+
+func TestNewFallsBackToSystemResolverForInvalidSpec(t *testing.T) {
+	t.Parallel()
+
+	r := New(nil, config.Config{DNSResolver: "https://"}, slog.New(slog.DiscardHandler), nil)
+	if r.resolver != net.DefaultResolver {
+		t.Fatalf("expected system resolver fallback")
+	}
+	if r.customResolver {
+		t.Fatalf("expected custom resolver to be disabled")
+	}
+}
+
+func TestPortMonitoringUsesConfiguredResolver(t *testing.T) {
+	t.Parallel()
+
+	var queries atomic.Int32
+	zone := fakeDNSZone("service.example.test", 0, []fakeDNSAnswer{
+		{recordType: dnsTypeA, data: []byte{127, 0, 0, 1}},
+	}, dnsTypeA)
+	address := startFakeDNSListener(t, func(name string, recordType uint16) (int, []fakeDNSAnswer, bool) {
+		queries.Add(1)
+		return zone(name, recordType)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to open listener: %v", err)
+	}
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	r := New(nil, config.Config{DNSResolver: address}, slog.New(slog.DiscardHandler), nil)
+	status, _, _ := r.handlePortMonitoring(context.Background(), monitor.Monitoring{
+		Type:   monitor.TypePort,
+		Target: "service.example.test",
+		Port:   port,
+	})
+
+	if status != monitor.StatusUp {
+		t.Fatalf("expected up, got %s", status)
+	}
+	if queries.Load() == 0 {
+		t.Fatalf("expected configured resolver to be queried")
+	}
+}
+
+func TestHTTPMonitoringUsesDNSOverHTTPSResolver(t *testing.T) {
+	t.Parallel()
+
+	var queries atomic.Int32
+	zone := fakeDNSZone("service.example.test", 0, []fakeDNSAnswer{
+		{recordType: dnsTypeA, data: []byte{127, 0, 0, 1}},
+	}, dnsTypeA)
+	dohServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost || request.Header.Get("Content-Type") != "application/dns-message" {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		query, err := io.ReadAll(request.Body)
+		if err != nil {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		queries.Add(1)
+		writer.Header().Set("Content-Type", "application/dns-message")
+		_, _ = writer.Write(buildFakeDNSResponse(query, zone))
+	}))
+	defer dohServer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse server url: %v", err)
+	}
+
+	r := New(nil, config.Config{DNSResolver: dohServer.URL + "/dns-query"}, slog.New(slog.DiscardHandler), nil)
+	status, _, httpStatusCode, _ := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
+		Target:  "http://" + net.JoinHostPort("service.example.test", serverURL.Port()) + "/health",
+		Timeout: 2,
+	})
+
+	if status != monitor.StatusUp {
+		t.Fatalf("expected up, got %s", status)
+	}
+	if httpStatusCode == nil || *httpStatusCode != http.StatusOK {
+		t.Fatalf("expected http status code 200, got %v", pointerIntValue(httpStatusCode))
+	}
+	if queries.Load() == 0 {
+		t.Fatalf("expected DNS-over-HTTPS resolver to be queried")
+	}
+}
+
+func TestResolveTargetHostUsesCustomResolverOnly(t *testing.T) {
+	t.Parallel()
+
+	address := startFakeDNSListener(t, fakeDNSZone("service.example.test", 0, []fakeDNSAnswer{
+		{recordType: dnsTypeA, data: []byte{192, 0, 2, 10}},
+	}, dnsTypeA))
+
+	r := New(nil, config.Config{DNSResolver: address}, slog.New(slog.DiscardHandler), nil)
+	host, err := r.resolveTargetHost(context.Background(), "service.example.test")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if host != "192.0.2.10" {
+		t.Fatalf("expected 192.0.2.10, got %s", host)
+	}
+
+	host, err = r.resolveTargetHost(context.Background(), "192.0.2.20")
+	if err != nil || host != "192.0.2.20" {
+		t.Fatalf("expected literal IP to pass through, got %s (%v)", host, err)
+	}
+
+	system := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	host, err = system.resolveTargetHost(context.Background(), "service.example.test")
+	if err != nil || host != "service.example.test" {
+		t.Fatalf("expected hostname to pass through without custom resolver, got %s (%v)", host, err)
+	}
+}
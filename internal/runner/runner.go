@@ -2,45 +2,87 @@ package runner
 
 import (
 	"bytes"
+	"cmp"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"math"
+	"math/rand/v2"
+	"mime"
 	"net"
 	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptrace"
+	"net/url"
 	"os/exec"
 	"regexp"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/m-breuer/webguard-instance-v2/internal/clock"
 	"github.com/m-breuer/webguard-instance-v2/internal/config"
 	"github.com/m-breuer/webguard-instance-v2/internal/core"
 	"github.com/m-breuer/webguard-instance-v2/internal/domainlookup"
+	"github.com/m-breuer/webguard-instance-v2/internal/metrics"
 	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+	"github.com/m-breuer/webguard-instance-v2/internal/notify"
 	"github.com/m-breuer/webguard-instance-v2/internal/target"
 )
 
-const fixedHTTPRetryTimes = 1
-const fixedHTTPRetryDelay = 250 * time.Millisecond
 const fixedHTTPMaxRedirects = 5
+const fixedHTTPTimeoutSeconds = 30
+const defaultHTTPMaxBodyBytes = 5 * 1024 * 1024
 const fixedPingTimeoutSeconds = 5
+const fixedUDPTimeoutSeconds = 5
+const fixedPortTimeoutSeconds = 5
+
+const defaultUDPProbe = "\n"
+
+const runTypeSSL = "ssl"
 
 var pingLatencyPattern = regexp.MustCompile(`time[=<]([0-9]+(?:\.[0-9]+)?)\s*ms`)
 
+var pingAddressPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)ping[^(\n]*\(([0-9a-f.:]+)\)`),
+	regexp.MustCompile(`(?i)bytes from ([0-9a-f.:]+?):? `),
+}
+
 var pingExecutor = runPingCommand
 
+var retryJitter = rand.Float64
+
+var errRunnerShuttingDown = errors.New("runner is shutting down")
+
+var errHTTP3Unsupported = errors.New("http3 checks require a QUIC transport, which is not available")
+
+var errTLSHandshake = errors.New("tls handshake failed")
+
+var errSchemeDowngraded = errors.New("redirect downgraded from https to http")
+
 var responseMonitoringTypes = []monitor.Type{
 	monitor.TypeHTTP,
 	monitor.TypePing,
+	monitor.TypeICMP,
 	monitor.TypeKeyword,
 	monitor.TypePort,
+	monitor.TypeDNS,
+	monitor.TypeSMTP,
+	monitor.TypeWebSocket,
 }
 
 var sslMonitoringTypes = []monitor.Type{
@@ -57,7 +99,10 @@ type CoreClient interface {
 	GetMonitorings(ctx context.Context, location string, types []monitor.Type) ([]monitor.Monitoring, error)
 	PostMonitoringResponse(ctx context.Context, payload monitor.MonitoringResponsePayload) error
 	PostSSLResult(ctx context.Context, payload monitor.SSLResultPayload) error
+	PostMonitoringResponses(ctx context.Context, payloads []monitor.MonitoringResponsePayload) error
+	PostSSLResults(ctx context.Context, payloads []monitor.SSLResultPayload) error
 	PostDomainResult(ctx context.Context, payload monitor.DomainResultPayload) error
+	PostRunSummary(ctx context.Context, payload monitor.RunSummaryPayload) error
 }
 
 type DomainLookup interface {
@@ -65,155 +110,507 @@ type DomainLookup interface {
 }
 
 type Runner struct {
-	client       CoreClient
-	cfg          config.Config
-	logger       *log.Logger
-	domainLookup DomainLookup
+	client         CoreClient
+	sink           ResultSink
+	cfg            config.Config
+	logger         *slog.Logger
+	domainLookup   DomainLookup
+	resolver       *net.Resolver
+	customResolver bool
+	rootCAs        *x509.CertPool
+	metrics        *metrics.Registry
+	notifier       StatusNotifier
+	statuses       *statusStore
+	posted         *postedStore
+	resolvedIPs    *ipSetStore
+	validators     *validatorStore
+	transports     *transportPool
+	socks          *socksDialer
+	checkSlots     chan struct{}
+	clock          clock.Clock
+
+	runMu   sync.Mutex
+	runs    sync.WaitGroup
+	closing bool
+
+	paused atomic.Bool
 }
 
-func New(client CoreClient, cfg config.Config, logger *log.Logger) *Runner {
+func New(client CoreClient, cfg config.Config, logger *slog.Logger, registry *metrics.Registry) *Runner {
 	if logger == nil {
-		logger = log.New(io.Discard, "", 0)
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	resolver, err := newResolver(cfg.DNSResolver)
+	if err != nil {
+		logger.Warn("Falling back to the system DNS resolver", "dns_resolver", cfg.DNSResolver, "error", err)
+		resolver = net.DefaultResolver
+	}
+
+	var notifier StatusNotifier
+	if webhookURL := strings.TrimSpace(cfg.NotifyWebhookURL); webhookURL != "" {
+		notifier = notify.NewWebhook(webhookURL)
+	}
+
+	resultSink, err := newResultSink(client, cfg)
+	if err != nil {
+		logger.Error("Falling back to posting results to Core", "result_sink", cfg.ResultSink, "error", err)
+		resultSink = coreSink{client: client}
+	}
+
+	rootCAs, err := config.LoadCABundle(cfg.CABundlePath)
+	if err != nil {
+		logger.Error("Falling back to the system root certificates", "ca_bundle_path", cfg.CABundlePath, "error", err)
 	}
+
+	socksProxy, err := config.SOCKS5Proxy(cfg.SOCKS5Proxy)
+	if err != nil {
+		logger.Error("Connecting to targets directly", "error", err)
+	}
+
+	var checkSlots chan struct{}
+	if cfg.MaxConcurrentChecks > 0 {
+		checkSlots = make(chan struct{}, cfg.MaxConcurrentChecks)
+	}
+
 	return &Runner{
-		client:       client,
-		cfg:          cfg,
-		logger:       logger,
-		domainLookup: domainlookup.New(10 * time.Second),
+		client:         client,
+		sink:           resultSink,
+		cfg:            cfg,
+		logger:         logger,
+		domainLookup:   domainlookup.New(10 * time.Second),
+		resolver:       resolver,
+		customResolver: resolver != net.DefaultResolver,
+		rootCAs:        rootCAs,
+		metrics:        registry,
+		notifier:       notifier,
+		statuses:       newStatusStore(),
+		posted:         newPostedStore(),
+		resolvedIPs:    newIPSetStore(),
+		validators:     newValidatorStore(),
+		transports:     newTransportPool(),
+		socks:          newSOCKSDialer(socksProxy),
+		checkSlots:     checkSlots,
+		clock:          clock.Real{},
 	}
 }
 
+func (r *Runner) dialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{Timeout: timeout, Resolver: r.resolver, Control: r.dialControl()}
+}
+
+// dialControl enforces BLOCK_PRIVATE_TARGETS on the address a check
+// actually connects to, which also covers redirects and DNS answers that
+// change after targetAllowed looked.
+func (r *Runner) dialControl() func(network, address string, conn syscall.RawConn) error {
+	if !r.cfg.BlockPrivateTargets {
+		return nil
+	}
+	return target.DialControl
+}
+
+func (r *Runner) resolveTargetHost(ctx context.Context, host string) (string, error) {
+	if !r.customResolver || net.ParseIP(host) != nil {
+		return host, nil
+	}
+	ip, err := resolveHostIP(ctx, r.resolver, host)
+	if err != nil {
+		return "", err
+	}
+	return ip.String(), nil
+}
+
 func (r *Runner) runResponse(ctx context.Context) error {
-	r.logger.Println("Dispatching response monitoring jobs...")
+	return r.runResponseTypes(ctx, responseMonitoringTypes, r.newRunState())
+}
 
-	monitorings, err := r.client.GetMonitorings(ctx, r.cfg.WebGuardLocation, responseMonitoringTypes)
+func (r *Runner) runResponseTypes(ctx context.Context, types []monitor.Type, run *runState) error {
+	monitorings, err := r.fetchMonitorings(ctx, types)
 	if err != nil {
-		r.logFetchError(err)
 		return err
 	}
 
+	monitorings = r.limitMonitorings(r.enabledMonitorings(monitorings))
+	run.trackMonitorings(monitorings)
+	defer r.logSuspendedPosts(run)
+	return r.dispatchResponse(ctx, monitorings, run)
+}
+
+func (r *Runner) dispatchResponse(ctx context.Context, monitorings []monitor.Monitoring, run *runState) error {
+	r.logger.Debug("Dispatching response monitoring jobs")
+	start := time.Now()
+
 	if len(monitorings) == 0 {
-		r.logger.Println("No active response monitoring found.")
+		r.logger.Debug("No active response monitoring found")
 		return nil
 	}
 
 	dispatched := 0
 	skippedMaintenance := 0
 	skippedUnsupported := 0
+	undispatched := 0
+
+	phaseCtx, cancel := r.phaseContext(ctx)
+	defer cancel()
 
 	jobs := make(chan monitor.Monitoring)
 	var workers sync.WaitGroup
 
-	workerCount := max(1, r.cfg.QueueDefaultWorkers)
+	workerCount := r.workerCount(r.cfg.QueueResponseWorkers)
 	for i := 0; i < workerCount; i++ {
 		workers.Add(1)
 		go func() {
 			defer workers.Done()
 			for monitoring := range jobs {
-				status, responseTime, httpStatusCode := r.crawlResponseMonitoring(ctx, monitoring)
-				r.logger.Printf(
-					"Response monitoring result computed (monitoring_id=%s type=%s status=%s response_time=%v http_status_code=%v)",
-					monitoring.ID,
-					monitoring.Type,
-					status,
-					pointerFloat64Value(responseTime),
-					pointerIntValue(httpStatusCode),
+				release, err := r.acquireCheckSlot(phaseCtx)
+				if err != nil {
+					r.logAbortedCheck(monitoring)
+					continue
+				}
+				checkStart := time.Now()
+				status, responseTime, httpStatusCode, details := r.crawlResponseMonitoring(phaseCtx, monitoring)
+				release()
+				if phaseCtx.Err() != nil {
+					r.logAbortedCheck(monitoring)
+					continue
+				}
+				run.recordCheck(status)
+				run.recordResponseTime(responseTime)
+				r.logger.Info(
+					"Response monitoring result computed",
+					"monitoring_id", monitoring.ID,
+					"type", monitoring.Type,
+					"status", status,
+					"response_time", pointerFloat64Value(responseTime),
+					"http_status_code", pointerIntValue(httpStatusCode),
+					"failure_reason", pointerStringValue(details.FailureReason),
+					"resolved_ip", pointerStringValue(details.ResolvedIP),
+					"final_url", pointerStringValue(details.FinalURL),
+					"redirect_count", pointerIntValue(details.RedirectCount),
+					"scheme_downgraded", details.SchemeDowngraded,
+					"hop_status_codes", details.HopStatusCodes,
+					"resolved_ips_changed", pointerBoolValue(details.ResolvedIPsChanged),
+					"content_changed", pointerBoolValue(details.ContentChanged),
+					"duration_ms", time.Since(checkStart).Milliseconds(),
 				)
-				if err := r.client.PostMonitoringResponse(ctx, monitor.MonitoringResponsePayload{
+				if err := r.postMonitoringResponse(ctx, run, monitor.MonitoringResponsePayload{
 					MonitoringID:   monitoring.ID,
 					Status:         status,
 					ResponseTime:   responseTime,
 					HTTPStatusCode: httpStatusCode,
+					DNSMs:          details.DNSMs,
+					ConnectMs:      details.ConnectMs,
+					TLSMs:          details.TLSMs,
+					TTFBMs:         details.TTFBMs,
+					FailureReason:  details.FailureReason,
+					ResolvedIP:     details.ResolvedIP,
+					FinalURL:       details.FinalURL,
+					RedirectCount:  details.RedirectCount,
+
+					SchemeDowngraded:   details.SchemeDowngraded,
+					HopStatusCodes:     details.HopStatusCodes,
+					FirstHopStatusCode: firstHopStatusCode(details.HopStatusCodes),
+					FinalHopStatusCode: finalHopStatusCode(details.HopStatusCodes),
+					ResolvedIPsChanged: details.ResolvedIPsChanged,
+					ContentChanged:     details.ContentChanged,
 				}); err != nil {
-					r.logger.Printf("Failed to post response result (monitoring_id=%s): %v", monitoring.ID, err)
+					r.logPostError("Failed to post response result", monitoring.ID, err)
 				}
+				r.notifyStatusTransition(ctx, monitoring, status)
 			}
 		}()
 	}
 
-	for _, monitoring := range monitorings {
+dispatchLoop:
+	for index, monitoring := range monitorings {
 		if !supportsResponseChecks(monitoring.Type) {
 			skippedUnsupported++
-			r.logger.Printf(
-				"Skipping passive/unsupported response monitoring (monitoring_id=%s type=%s)",
-				monitoring.ID,
-				monitoring.Type,
+			r.logger.Info(
+				"Skipping passive/unsupported response monitoring",
+				"monitoring_id", monitoring.ID,
+				"type", monitoring.Type,
 			)
 			continue
 		}
 
 		if monitoring.MaintenanceActive {
 			skippedMaintenance++
-			if err := r.client.PostMonitoringResponse(ctx, monitor.MonitoringResponsePayload{
+			run.recordMaintenance()
+			if err := r.postMonitoringResponse(ctx, run, monitor.MonitoringResponsePayload{
 				MonitoringID:   monitoring.ID,
 				Status:         monitor.StatusUnknown,
 				ResponseTime:   nil,
 				HTTPStatusCode: nil,
 			}); err != nil {
-				r.logger.Printf("Failed to post maintenance response result (monitoring_id=%s): %v", monitoring.ID, err)
+				r.logPostError("Failed to post maintenance response result", monitoring.ID, err)
 			}
 			continue
 		}
 
-		dispatched++
-		jobs <- monitoring
+		select {
+		case jobs <- monitoring:
+			dispatched++
+		case <-phaseCtx.Done():
+			undispatched = len(monitorings) - index
+			break dispatchLoop
+		}
 	}
 	close(jobs)
 	workers.Wait()
-
-	r.logger.Printf(
-		"Response monitoring dispatch done. total=%d dispatched=%d skipped_maintenance=%d skipped_unsupported=%d",
-		len(monitorings),
-		dispatched,
-		skippedMaintenance,
-		skippedUnsupported,
+	r.flushResults(ctx, run)
+
+	r.logger.Info(
+		"Response monitoring dispatch done",
+		"total", len(monitorings),
+		"dispatched", dispatched,
+		"skipped_maintenance", skippedMaintenance,
+		"skipped_unsupported", skippedUnsupported,
+		"undispatched", undispatched,
+		"duration_ms", time.Since(start).Milliseconds(),
 	)
 
-	return nil
+	return phaseError(ctx, phaseCtx, undispatched)
+}
+
+func (r *Runner) workerCount(phaseWorkers int) int {
+	if phaseWorkers > 0 {
+		return phaseWorkers
+	}
+	return max(1, r.cfg.QueueDefaultWorkers)
+}
+
+// acquireCheckSlot limits how many checks run at once across all phases, as
+// each phase has its own worker pool. The returned func frees the slot.
+func (r *Runner) acquireCheckSlot(ctx context.Context) (func(), error) {
+	if r.checkSlots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case r.checkSlots <- struct{}{}:
+		return func() { <-r.checkSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// phaseContext bounds the checks of one phase so a run cannot overrun the
+// scheduler interval. PHASE_TIMEOUT_SECONDS wins; otherwise the monitoring
+// interval is used.
+func (r *Runner) phaseContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeoutSeconds := r.cfg.PhaseTimeoutSeconds
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = r.cfg.MonitoringIntervalSeconds
+	}
+	if timeoutSeconds <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+}
+
+// phaseError reports a phase that hit its own deadline. Cancellation of the
+// parent context (shutdown) is not treated as a failure.
+func phaseError(ctx, phaseCtx context.Context, undispatched int) error {
+	if ctx.Err() != nil || !errors.Is(phaseCtx.Err(), context.DeadlineExceeded) {
+		return nil
+	}
+	return fmt.Errorf("phase timed out with %d monitorings not dispatched: %w", undispatched, context.DeadlineExceeded)
+}
+
+func (r *Runner) postMonitoringResponse(ctx context.Context, run *runState, payload monitor.MonitoringResponsePayload) error {
+	if run.collecting() {
+		run.results.addResponse(payload)
+		return nil
+	}
+	if r.cfg.PostOnChangeOnly && !r.posted.shouldPost(payload.MonitoringID, payload.Status, r.cfg.PostHeartbeatEveryRuns) {
+		r.posted.markSkipped(payload.MonitoringID)
+		r.logger.Info("Skipping unchanged response result", "monitoring_id", payload.MonitoringID, "status", payload.Status)
+		return nil
+	}
+	if r.cfg.DryRun {
+		r.logDryRun("monitoring_response", payload.MonitoringID, payload)
+		r.markResponsesPosted(payload)
+		return nil
+	}
+	if batch := run.responseBatch(); batch != nil {
+		if chunk := batch.add(payload); chunk != nil {
+			r.postMonitoringResponseBatch(ctx, run, chunk)
+		}
+		return nil
+	}
+	err := run.breaker().do(func() error {
+		return r.sink.EmitResponse(ctx, payload)
+	})
+	if err == nil {
+		r.markResponsesPosted(payload)
+	}
+	return err
+}
+
+func (r *Runner) postSSLResult(ctx context.Context, run *runState, payload monitor.SSLResultPayload) error {
+	if run.collecting() {
+		run.results.addSSL(payload)
+		return nil
+	}
+	if r.cfg.DryRun {
+		r.logDryRun("ssl_result", payload.MonitoringID, payload)
+		return nil
+	}
+	if batch := run.sslBatch(); batch != nil {
+		if chunk := batch.add(payload); chunk != nil {
+			r.postSSLResultBatch(ctx, run, chunk)
+		}
+		return nil
+	}
+	return run.breaker().do(func() error {
+		return r.sink.EmitSSL(ctx, payload)
+	})
 }
 
-func (r *Runner) runSSL(ctx context.Context) error {
-	r.logger.Println("Dispatching SSL monitoring jobs...")
+func (r *Runner) postDomainResult(ctx context.Context, run *runState, payload monitor.DomainResultPayload) error {
+	// The response result posted next to it already carries the status.
+	if run.collecting() {
+		return nil
+	}
+	if r.cfg.DryRun {
+		r.logDryRun("domain_result", payload.MonitoringID, payload)
+		return nil
+	}
+	return run.breaker().do(func() error {
+		return r.sink.EmitDomain(ctx, payload)
+	})
+}
 
-	monitorings, err := r.client.GetMonitorings(ctx, r.cfg.WebGuardLocation, sslMonitoringTypes)
+func (r *Runner) postRunSummary(ctx context.Context, run *runState, payload monitor.RunSummaryPayload) error {
+	if run.collecting() {
+		return nil
+	}
+	if r.cfg.DryRun {
+		r.logDryRun("run_summary", "", payload)
+		return nil
+	}
+	return run.breaker().do(func() error {
+		return r.client.PostRunSummary(ctx, payload)
+	})
+}
+
+func (r *Runner) logPostError(message, monitoringID string, err error) {
+	if errors.Is(err, errCorePostsSuspended) {
+		return
+	}
+	r.logger.Error(message, "monitoring_id", monitoringID, "error", err)
+}
+
+func (r *Runner) logSuspendedPosts(run *runState) {
+	breaker := run.breaker()
+	if skipped := breaker.skippedPosts(); skipped > 0 {
+		r.logger.Warn(
+			"Skipped posting results after repeated Core API failures",
+			"failure_threshold", breaker.threshold,
+			"skipped_posts", skipped,
+		)
+	}
+}
+
+func (r *Runner) logAbortedCheck(monitoring monitor.Monitoring) {
+	r.logger.Warn(
+		"Discarding monitoring result after phase deadline",
+		"monitoring_id", monitoring.ID,
+		"type", monitoring.Type,
+	)
+}
+
+func (r *Runner) logDryRun(kind, monitoringID string, payload any) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		r.logger.Error("Failed to encode dry run payload", "monitoring_id", monitoringID, "kind", kind, "error", err)
+		return
+	}
+	r.logger.Info("Dry run: skipping result post", "monitoring_id", monitoringID, "kind", kind, "payload", string(encoded))
+}
+
+func (r *Runner) runSSL(ctx context.Context, run *runState) error {
+	monitorings, err := r.fetchMonitorings(ctx, sslMonitoringTypes)
 	if err != nil {
-		r.logFetchError(err)
 		return err
 	}
 
+	monitorings = r.limitMonitorings(r.enabledMonitorings(monitorings))
+	run.trackMonitorings(monitorings)
+	defer r.logSuspendedPosts(run)
+	return r.dispatchSSL(ctx, monitorings, run)
+}
+
+func (r *Runner) dispatchSSL(ctx context.Context, monitorings []monitor.Monitoring, run *runState) error {
+	r.logger.Debug("Dispatching SSL monitoring jobs")
+	start := time.Now()
+
 	if len(monitorings) == 0 {
-		r.logger.Println("No active SSL monitoring found.")
+		r.logger.Debug("No active SSL monitoring found")
 		return nil
 	}
 
 	dispatched := 0
 	skippedMaintenance := 0
 	skippedUnsupported := 0
+	undispatched := 0
+
+	phaseCtx, cancel := r.phaseContext(ctx)
+	defer cancel()
 
 	jobs := make(chan monitor.Monitoring)
 	var workers sync.WaitGroup
 
-	workerCount := max(1, r.cfg.QueueDefaultWorkers)
+	workerCount := r.workerCount(r.cfg.QueueSSLWorkers)
 	for i := 0; i < workerCount; i++ {
 		workers.Add(1)
 		go func() {
 			defer workers.Done()
 			for monitoring := range jobs {
-				payload := r.crawlMonitoringSSL(monitoring)
-				if err := r.client.PostSSLResult(ctx, payload); err != nil {
-					r.logger.Printf("Failed to post SSL result (monitoring_id=%s): %v", monitoring.ID, err)
+				release, err := r.acquireCheckSlot(phaseCtx)
+				if err != nil {
+					r.logAbortedCheck(monitoring)
+					continue
+				}
+				checkStart := time.Now()
+				var payload monitor.SSLResultPayload
+				if r.targetAllowed(phaseCtx, monitoring) {
+					payload = r.crawlMonitoringSSL(phaseCtx, monitoring)
+				} else {
+					payload = sslFailure(monitor.SSLResultPayload{MonitoringID: monitoring.ID}, monitor.FailureTargetBlocked)
+				}
+				release()
+				if phaseCtx.Err() != nil {
+					r.logAbortedCheck(monitoring)
+					continue
+				}
+				if payload.IsValid {
+					run.recordCheck(monitor.StatusUp)
+				} else {
+					run.recordCheck(monitor.StatusDown)
+				}
+				r.logger.Info(
+					"SSL monitoring result computed",
+					"monitoring_id", monitoring.ID,
+					"type", monitoring.Type,
+					"is_valid", payload.IsValid,
+					"duration_ms", time.Since(checkStart).Milliseconds(),
+				)
+				if err := r.postSSLResult(ctx, run, payload); err != nil {
+					r.logPostError("Failed to post SSL result", monitoring.ID, err)
 				}
 			}
 		}()
 	}
 
-	for _, monitoring := range monitorings {
+dispatchLoop:
+	for index, monitoring := range monitorings {
 		if !supportsSSLChecks(monitoring.Type) {
 			skippedUnsupported++
-			r.logger.Printf(
-				"Skipping passive/unsupported SSL monitoring (monitoring_id=%s type=%s)",
-				monitoring.ID,
-				monitoring.Type,
+			r.logger.Info(
+				"Skipping passive/unsupported SSL monitoring",
+				"monitoring_id", monitoring.ID,
+				"type", monitoring.Type,
 			)
 			continue
 		}
@@ -222,116 +619,184 @@ func (r *Runner) runSSL(ctx context.Context) error {
 			skippedMaintenance++
 			continue
 		}
-		dispatched++
-		jobs <- monitoring
+		select {
+		case jobs <- monitoring:
+			dispatched++
+		case <-phaseCtx.Done():
+			undispatched = len(monitorings) - index
+			break dispatchLoop
+		}
 	}
 	close(jobs)
 	workers.Wait()
-
-	r.logger.Printf(
-		"SSL monitoring dispatch done. total=%d dispatched=%d skipped_maintenance=%d skipped_unsupported=%d",
-		len(monitorings),
-		dispatched,
-		skippedMaintenance,
-		skippedUnsupported,
+	r.flushResults(ctx, run)
+
+	r.logger.Info(
+		"SSL monitoring dispatch done",
+		"total", len(monitorings),
+		"dispatched", dispatched,
+		"skipped_maintenance", skippedMaintenance,
+		"skipped_unsupported", skippedUnsupported,
+		"undispatched", undispatched,
+		"duration_ms", time.Since(start).Milliseconds(),
 	)
 
-	return nil
+	return phaseError(ctx, phaseCtx, undispatched)
 }
 
-func (r *Runner) runDomainExpiration(ctx context.Context) error {
-	r.logger.Println("Dispatching domain expiration monitoring jobs...")
-
-	monitorings, err := r.client.GetMonitorings(ctx, r.cfg.WebGuardLocation, domainExpirationMonitoringTypes)
+func (r *Runner) runDomainExpiration(ctx context.Context, run *runState) error {
+	monitorings, err := r.fetchMonitorings(ctx, domainExpirationMonitoringTypes)
 	if err != nil {
-		r.logFetchError(err)
 		return err
 	}
 
+	monitorings = r.limitMonitorings(r.enabledMonitorings(monitorings))
+	run.trackMonitorings(monitorings)
+	defer r.logSuspendedPosts(run)
+	return r.dispatchDomainExpiration(ctx, monitorings, run)
+}
+
+func (r *Runner) dispatchDomainExpiration(ctx context.Context, monitorings []monitor.Monitoring, run *runState) error {
+	r.logger.Debug("Dispatching domain expiration monitoring jobs")
+	start := time.Now()
+
 	if len(monitorings) == 0 {
-		r.logger.Println("No active domain expiration monitoring found.")
+		r.logger.Debug("No active domain expiration monitoring found")
 		return nil
 	}
 
 	dispatched := 0
 	skippedMaintenance := 0
 	skippedUnsupported := 0
+	undispatched := 0
+
+	phaseCtx, cancel := r.phaseContext(ctx)
+	defer cancel()
 
 	jobs := make(chan monitor.Monitoring)
 	var workers sync.WaitGroup
 
-	workerCount := max(1, r.cfg.QueueDefaultWorkers)
+	workerCount := r.workerCount(0)
 	for i := 0; i < workerCount; i++ {
 		workers.Add(1)
 		go func() {
 			defer workers.Done()
 			for monitoring := range jobs {
-				status, domainPayload, hasDomainPayload := r.crawlDomainExpiration(ctx, monitoring)
-				r.logger.Printf(
-					"Domain expiration monitoring result computed (monitoring_id=%s status=%s)",
-					monitoring.ID,
-					status,
+				release, err := r.acquireCheckSlot(phaseCtx)
+				if err != nil {
+					r.logAbortedCheck(monitoring)
+					continue
+				}
+				checkStart := time.Now()
+				status, domainPayload, hasDomainPayload := r.crawlDomainExpiration(phaseCtx, monitoring)
+				release()
+				if phaseCtx.Err() != nil {
+					r.logAbortedCheck(monitoring)
+					continue
+				}
+				run.recordCheck(status)
+				r.logger.Info(
+					"Domain expiration monitoring result computed",
+					"monitoring_id", monitoring.ID,
+					"status", status,
+					"duration_ms", time.Since(checkStart).Milliseconds(),
 				)
-				if err := r.client.PostMonitoringResponse(ctx, monitor.MonitoringResponsePayload{
+				if err := r.postMonitoringResponse(ctx, run, monitor.MonitoringResponsePayload{
 					MonitoringID:   monitoring.ID,
 					Status:         status,
 					ResponseTime:   nil,
 					HTTPStatusCode: nil,
 				}); err != nil {
-					r.logger.Printf("Failed to post domain expiration response result (monitoring_id=%s): %v", monitoring.ID, err)
+					r.logPostError("Failed to post domain expiration response result", monitoring.ID, err)
 				}
 				if hasDomainPayload {
-					if err := r.client.PostDomainResult(ctx, domainPayload); err != nil {
-						r.logger.Printf("Failed to post domain expiration result (monitoring_id=%s): %v", monitoring.ID, err)
+					if err := r.postDomainResult(ctx, run, domainPayload); err != nil {
+						r.logPostError("Failed to post domain expiration result", monitoring.ID, err)
 					}
 				}
 			}
 		}()
 	}
 
-	for _, monitoring := range monitorings {
+dispatchLoop:
+	for index, monitoring := range monitorings {
 		if monitoring.Type != monitor.TypeDomainExpiration {
 			skippedUnsupported++
-			r.logger.Printf(
-				"Skipping unsupported domain expiration monitoring (monitoring_id=%s type=%s)",
-				monitoring.ID,
-				monitoring.Type,
+			r.logger.Info(
+				"Skipping unsupported domain expiration monitoring",
+				"monitoring_id", monitoring.ID,
+				"type", monitoring.Type,
 			)
 			continue
 		}
 
 		if monitoring.MaintenanceActive {
 			skippedMaintenance++
-			if err := r.client.PostMonitoringResponse(ctx, monitor.MonitoringResponsePayload{
+			run.recordMaintenance()
+			if err := r.postMonitoringResponse(ctx, run, monitor.MonitoringResponsePayload{
 				MonitoringID:   monitoring.ID,
 				Status:         monitor.StatusUnknown,
 				ResponseTime:   nil,
 				HTTPStatusCode: nil,
 			}); err != nil {
-				r.logger.Printf("Failed to post maintenance domain expiration response result (monitoring_id=%s): %v", monitoring.ID, err)
+				r.logPostError("Failed to post maintenance domain expiration response result", monitoring.ID, err)
 			}
 			continue
 		}
 
-		dispatched++
-		jobs <- monitoring
+		select {
+		case jobs <- monitoring:
+			dispatched++
+		case <-phaseCtx.Done():
+			undispatched = len(monitorings) - index
+			break dispatchLoop
+		}
 	}
 	close(jobs)
 	workers.Wait()
-
-	r.logger.Printf(
-		"Domain expiration monitoring dispatch done. total=%d dispatched=%d skipped_maintenance=%d skipped_unsupported=%d",
-		len(monitorings),
-		dispatched,
-		skippedMaintenance,
-		skippedUnsupported,
+	r.flushResults(ctx, run)
+
+	r.logger.Info(
+		"Domain expiration monitoring dispatch done",
+		"total", len(monitorings),
+		"dispatched", dispatched,
+		"skipped_maintenance", skippedMaintenance,
+		"skipped_unsupported", skippedUnsupported,
+		"undispatched", undispatched,
+		"duration_ms", time.Since(start).Milliseconds(),
 	)
 
-	return nil
+	return phaseError(ctx, phaseCtx, undispatched)
 }
 
 func (r *Runner) RunMonitoring(ctx context.Context) error {
-	r.logger.Println("Dispatching all monitoring jobs...")
+	if err := r.beginRun(); err != nil {
+		return err
+	}
+	defer r.runs.Done()
+
+	if r.Paused() {
+		r.logger.Info("Monitoring is paused, skipping run")
+		return nil
+	}
+
+	return r.runAll(ctx, r.newRunState())
+}
+
+// runAll fetches every monitoring once and runs the response, SSL and domain
+// expiration phases in parallel.
+func (r *Runner) runAll(ctx context.Context, run *runState) error {
+	r.logger.Debug("Dispatching all monitoring jobs")
+	startedAt := r.clock.Now()
+	start := time.Now()
+
+	monitorings, err := r.fetchMonitorings(ctx, monitor.CheckedTypes)
+	if err != nil {
+		return fmt.Errorf("fetch monitorings: %w", err)
+	}
+	monitorings = r.limitMonitorings(r.enabledMonitorings(monitorings))
+	run.trackMonitorings(monitorings)
+	responseMonitorings, sslMonitorings, domainMonitorings := routeMonitorings(monitorings)
 
 	type phaseResult struct {
 		name string
@@ -344,63 +809,322 @@ func (r *Runner) RunMonitoring(ctx context.Context) error {
 
 	go func() {
 		defer phases.Done()
-		results <- phaseResult{name: "response", err: r.runResponse(ctx)}
+		results <- phaseResult{name: "response", err: r.dispatchResponse(ctx, responseMonitorings, run)}
 	}()
 
 	go func() {
 		defer phases.Done()
-		results <- phaseResult{name: "SSL", err: r.runSSL(ctx)}
+		results <- phaseResult{name: "SSL", err: r.dispatchSSL(ctx, sslMonitorings, run)}
 	}()
 
 	go func() {
 		defer phases.Done()
-		results <- phaseResult{name: "domain expiration", err: r.runDomainExpiration(ctx)}
+		results <- phaseResult{name: "domain expiration", err: r.dispatchDomainExpiration(ctx, domainMonitorings, run)}
 	}()
 
 	phases.Wait()
 	close(results)
 
+	var phaseErrs []error
 	for result := range results {
 		if result.err != nil {
-			r.logger.Printf("%s monitoring phase failed: %v", result.name, result.err)
+			r.logger.Error("Monitoring phase failed", "phase", result.name, "error", result.err)
+			phaseErrs = append(phaseErrs, fmt.Errorf("%s phase: %w", result.name, result.err))
 		}
 	}
 
-	r.logger.Println("All monitoring jobs have been dispatched successfully.")
+	// A run cut off by the scheduler's RUN_MAX_DURATION_SECONDS deadline
+	// cannot post its summary anymore and must not count as a finished run.
+	if err := ctx.Err(); errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("monitoring run exceeded its maximum duration: %w", err)
+	}
+	// A full run checked every monitoring, so transports it did not use
+	// belong to monitorings that changed or are gone.
+	r.transports.evictUnused()
+
+	summary := run.summary(startedAt, time.Since(start))
+	if err := r.postRunSummary(ctx, run, summary); err != nil && !errors.Is(err, errCorePostsSuspended) {
+		r.logger.Error("Failed to post run summary", "error", err)
+	}
+	r.logSuspendedPosts(run)
+	r.metrics.SetLastRun(r.clock.Now())
+	r.logger.Info(
+		"All monitoring jobs have been dispatched successfully",
+		"duration_ms", summary.DurationMs,
+		"checks", summary.Checks,
+		"up", summary.Up,
+		"down", summary.Down,
+		"degraded", summary.Degraded,
+		"unknown", summary.Unknown,
+		"response_time_p50", pointerFloat64Value(summary.ResponseTimeP50),
+		"response_time_p95", pointerFloat64Value(summary.ResponseTimeP95),
+		"response_time_p99", pointerFloat64Value(summary.ResponseTimeP99),
+		"response_time_max", pointerFloat64Value(summary.ResponseTimeMax),
+	)
+	return errors.Join(phaseErrs...)
+}
+
+// enabledMonitorings drops monitorings Core has disabled. Nothing is posted
+// for them, so they neither count in the run summary nor report unknown the
+// way monitorings in maintenance do.
+func (r *Runner) enabledMonitorings(monitorings []monitor.Monitoring) []monitor.Monitoring {
+	if !slices.ContainsFunc(monitorings, func(monitoring monitor.Monitoring) bool { return !monitoring.IsEnabled() }) {
+		return monitorings
+	}
+
+	enabled := make([]monitor.Monitoring, 0, len(monitorings))
+	for _, monitoring := range monitorings {
+		if !monitoring.IsEnabled() {
+			r.logger.Info("Skipping disabled monitoring", "monitoring_id", monitoring.ID, "type", monitoring.Type)
+			continue
+		}
+		enabled = append(enabled, monitoring)
+	}
+	return enabled
+}
+
+// limitMonitorings applies MAX_MONITORINGS_PER_RUN. Monitorings beyond the
+// cap are dropped by ID order, so repeated runs skip the same ones.
+func (r *Runner) limitMonitorings(monitorings []monitor.Monitoring) []monitor.Monitoring {
+	limit := r.cfg.MaxMonitoringsPerRun
+	if limit <= 0 || len(monitorings) <= limit {
+		return monitorings
+	}
+
+	sorted := slices.Clone(monitorings)
+	slices.SortStableFunc(sorted, func(a, b monitor.Monitoring) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	r.logger.Warn(
+		"Skipping monitorings beyond MAX_MONITORINGS_PER_RUN",
+		"limit", limit,
+		"fetched", len(monitorings),
+		"skipped", len(monitorings)-limit,
+	)
+	return sorted[:limit]
+}
+
+func routeMonitorings(monitorings []monitor.Monitoring) (response, ssl, domain []monitor.Monitoring) {
+	for _, monitoring := range monitorings {
+		if monitoring.Type == monitor.TypeDomainExpiration {
+			domain = append(domain, monitoring)
+			continue
+		}
+
+		response = append(response, monitoring)
+		if slices.Contains(sslMonitoringTypes, monitoring.Type) {
+			ssl = append(ssl, monitoring)
+		}
+	}
+	return response, ssl, domain
+}
+
+func IsRunnableType(name string) bool {
+	switch name {
+	case runTypeSSL, string(monitor.TypeDomainExpiration):
+		return true
+	}
+	return slices.Contains(responseMonitoringTypes, monitor.Type(name))
+}
+
+func (r *Runner) RunMonitoringType(ctx context.Context, name string) error {
+	if !IsRunnableType(name) {
+		return fmt.Errorf("unsupported monitoring type %q", name)
+	}
+
+	if err := r.beginRun(); err != nil {
+		return err
+	}
+	defer r.runs.Done()
+
+	return r.runType(ctx, name, r.newRunState())
+}
+
+func (r *Runner) runType(ctx context.Context, name string, run *runState) error {
+	switch name {
+	case runTypeSSL:
+		return r.runSSL(ctx, run)
+	case string(monitor.TypeDomainExpiration):
+		return r.runDomainExpiration(ctx, run)
+	default:
+		return r.runResponseTypes(ctx, []monitor.Type{monitor.Type(name)}, run)
+	}
+}
+
+func (r *Runner) beginRun() error {
+	r.runMu.Lock()
+	defer r.runMu.Unlock()
+
+	if r.closing {
+		return errRunnerShuttingDown
+	}
+	r.runs.Add(1)
 	return nil
 }
 
-func (r *Runner) logFetchError(err error) {
-	r.logger.Println("Failed to fetch monitorings from the Core API.")
+// Pause makes scheduled runs skip dispatching until Resume is called. A run
+// already in progress finishes normally.
+func (r *Runner) Pause() {
+	if !r.paused.Swap(true) {
+		r.logger.Info("Monitoring paused")
+	}
+}
+
+func (r *Runner) Resume() {
+	if r.paused.Swap(false) {
+		r.logger.Info("Monitoring resumed")
+	}
+}
+
+func (r *Runner) Paused() bool {
+	return r.paused.Load()
+}
+
+func (r *Runner) Shutdown(ctx context.Context) error {
+	r.runMu.Lock()
+	r.closing = true
+	r.runMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		r.runs.Wait()
+		r.transports.closeIdleConnections()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
+func (r *Runner) logFetchError(err error) {
 	var statusError *core.HTTPStatusError
 	if errors.As(err, &statusError) && strings.TrimSpace(statusError.Body) != "" {
-		r.logger.Println(statusError.Body)
+		r.logger.Error(
+			"Failed to fetch monitorings from the Core API",
+			"error", err,
+			"status_code", statusError.StatusCode,
+			"body", statusError.Body,
+		)
+		return
+	}
+
+	r.logger.Error("Failed to fetch monitorings from the Core API", "error", err)
+}
+
+// checkDetails carries the optional per-check data that ends up in the
+// response payload next to the status.
+type checkDetails struct {
+	httpTimings
+	FailureReason      *string
+	ResolvedIPsChanged *bool
+	ContentChanged     *bool
+}
+
+func (r *Runner) crawlResponseMonitoring(ctx context.Context, monitoring monitor.Monitoring) (monitor.Status, *float64, *int, checkDetails) {
+	start := time.Now()
+	status, responseTime, statusCode, details := r.checkResponseMonitoring(ctx, monitoring)
+	status = degradedStatus(status, responseTime, monitoring.DegradedThresholdMs)
+	details.ResolvedIPsChanged = r.checkResolvedIPs(ctx, monitoring)
+	r.metrics.ObserveCheck(string(monitoring.Type), string(status), time.Since(start))
+	return status, responseTime, statusCode, details
+}
+
+// degradedStatus turns an up result slower than thresholdMs into degraded.
+// Checks without a response time and a threshold of zero stay as they are.
+func degradedStatus(status monitor.Status, responseTime *float64, thresholdMs int) monitor.Status {
+	if status != monitor.StatusUp || thresholdMs <= 0 || responseTime == nil || *responseTime <= float64(thresholdMs) {
+		return status
 	}
+	return monitor.StatusDegraded
 }
 
-func (r *Runner) crawlResponseMonitoring(ctx context.Context, monitoring monitor.Monitoring) (monitor.Status, *float64, *int) {
+// dialsTarget reports whether a check connects to its target. DNS checks
+// only query resolvers and heartbeats are passive.
+func dialsTarget(monitoringType monitor.Type) bool {
+	switch monitoringType {
+	case monitor.TypeHTTP, monitor.TypeKeyword, monitor.TypePing, monitor.TypeICMP, monitor.TypePort, monitor.TypeSMTP, monitor.TypeWebSocket:
+		return true
+	default:
+		return false
+	}
+}
+
+// targetAllowed applies BLOCK_PRIVATE_TARGETS before a check dials its
+// target.
+func (r *Runner) targetAllowed(ctx context.Context, monitoring monitor.Monitoring) bool {
+	if !r.cfg.BlockPrivateTargets {
+		return true
+	}
+	host, err := target.Host(monitoring.Target)
+	if err != nil {
+		return true
+	}
+	if err := target.IsAllowed(ctx, host, r.resolver); err != nil {
+		r.logger.Warn("Blocked private monitoring target", "monitoring_id", monitoring.ID, "type", monitoring.Type, "error", err)
+		return false
+	}
+	return true
+}
+
+func (r *Runner) checkResponseMonitoring(ctx context.Context, monitoring monitor.Monitoring) (monitor.Status, *float64, *int, checkDetails) {
+	if err := target.Validate(monitoring.Target, monitoring.Type); err != nil {
+		r.logger.Warn("Invalid monitoring target", "monitoring_id", monitoring.ID, "type", monitoring.Type, "error", err)
+		return monitor.StatusDown, nil, nil, checkDetails{}
+	}
+	if monitoring.SRVTarget && resolvesSRV(monitoring.Type) {
+		resolved, ok := r.resolveSRVTarget(ctx, monitoring)
+		if !ok {
+			reason := monitor.FailureSRVNotFound
+			return monitor.StatusDown, nil, nil, checkDetails{FailureReason: &reason}
+		}
+		monitoring = resolved
+	}
+	if dialsTarget(monitoring.Type) && !r.targetAllowed(ctx, monitoring) {
+		reason := monitor.FailureTargetBlocked
+		return monitor.StatusDown, nil, nil, checkDetails{FailureReason: &reason}
+	}
+	if dialsTarget(monitoring.Type) && !r.ipVersionAvailable(ctx, monitoring) {
+		reason := monitor.FailureIPVersionUnavailable
+		return monitor.StatusDown, nil, nil, checkDetails{FailureReason: &reason}
+	}
+
 	switch monitoring.Type {
 	case monitor.TypeHTTP:
 		return r.handleHTTPMonitoring(ctx, monitoring)
 	case monitor.TypePing:
-		status, responseTime := handlePingMonitoring(monitoring)
-		return status, responseTime, nil
+		status, responseTime, resolvedIP := r.handlePingMonitoring(ctx, monitoring)
+		return status, responseTime, nil, checkDetails{httpTimings: httpTimings{ResolvedIP: resolvedIP}}
+	case monitor.TypeICMP:
+		status, responseTime := r.handleICMPMonitoring(ctx, monitoring)
+		return status, responseTime, nil, checkDetails{}
 	case monitor.TypeKeyword:
 		return r.handleKeywordMonitoring(ctx, monitoring)
 	case monitor.TypePort:
-		status, responseTime := handlePortMonitoring(monitoring)
-		return status, responseTime, nil
+		status, responseTime, details := r.handlePortMonitoring(ctx, monitoring)
+		return status, responseTime, nil, details
+	case monitor.TypeDNS:
+		status, responseTime := r.handleDNSMonitoring(ctx, monitoring)
+		return status, responseTime, nil, checkDetails{}
+	case monitor.TypeSMTP:
+		status, responseTime := r.handleSMTPMonitoring(ctx, monitoring)
+		return status, responseTime, nil, checkDetails{}
+	case monitor.TypeWebSocket:
+		status, responseTime := r.handleWebSocketMonitoring(ctx, monitoring)
+		return status, responseTime, nil, checkDetails{}
 	case monitor.TypeHeartbeat:
-		return monitor.StatusUnknown, nil, nil
+		return monitor.StatusUnknown, nil, nil, checkDetails{}
 	default:
-		return monitor.StatusUnknown, nil, nil
+		return monitor.StatusUnknown, nil, nil, checkDetails{}
 	}
 }
 
 func supportsResponseChecks(monitoringType monitor.Type) bool {
 	switch monitoringType {
-	case monitor.TypeHTTP, monitor.TypePing, monitor.TypeKeyword, monitor.TypePort:
+	case monitor.TypeHTTP, monitor.TypePing, monitor.TypeICMP, monitor.TypeKeyword, monitor.TypePort, monitor.TypeDNS, monitor.TypeSMTP, monitor.TypeWebSocket:
 		return true
 	default:
 		return false
@@ -414,59 +1138,230 @@ func supportsSSLChecks(monitoringType monitor.Type) bool {
 	default:
 		return false
 	}
-}
+}
+
+func (r *Runner) handleHTTPMonitoring(ctx context.Context, monitoring monitor.Monitoring) (monitor.Status, *float64, *int, checkDetails) {
+	start := time.Now()
+	statusCode, body, timings, err := r.performHTTPRequest(ctx, monitoring)
+	if err != nil {
+		reason := httpFailureReason(err)
+		return monitor.StatusDown, nil, nil, checkDetails{FailureReason: &reason}
+	}
+	httpStatusCode := intPointer(statusCode)
+	if monitoring.ConditionalRequests && statusCode == http.StatusNotModified {
+		responseTime := roundMilliseconds(time.Since(start))
+		unchanged := false
+		return monitor.StatusUp, &responseTime, httpStatusCode, checkDetails{httpTimings: timings, ContentChanged: &unchanged}
+	}
+	if isExpectedStatusCode(monitoring, statusCode) && !r.hasExpectedContentType(monitoring, timings) {
+		reason := monitor.HTTPFailureUnexpectedContent
+		return monitor.StatusDown, nil, httpStatusCode, checkDetails{httpTimings: timings, FailureReason: &reason}
+	}
+	if monitoring.ExpectedBodyHash != "" && isExpectedStatusCode(monitoring, statusCode) {
+		if hash := bodyHash(body, monitoring.BodyHashIgnoreWhitespace); hash != monitoring.ExpectedBodyHash {
+			r.logger.Info("Response body hash changed", "monitoring_id", monitoring.ID, "expected_hash", monitoring.ExpectedBodyHash, "hash", hash)
+			reason := monitor.HTTPFailureBodyHashMismatch
+			return monitor.StatusDown, nil, httpStatusCode, checkDetails{httpTimings: timings, FailureReason: &reason}
+		}
+	}
+	if isExpectedStatusCode(monitoring, statusCode) {
+		responseTime := roundMilliseconds(time.Since(start))
+		return monitor.StatusUp, &responseTime, httpStatusCode, checkDetails{httpTimings: timings, ContentChanged: r.checkContentChanged(monitoring, timings)}
+	}
+	return monitor.StatusDown, nil, httpStatusCode, checkDetails{httpTimings: timings}
+}
+
+// bypassCache asks caches and CDNs in front of the target to forward the
+// request to the origin: it sends no-cache headers and appends a unique _wg
+// query parameter, leaving any existing query string as it is.
+func bypassCache(request *http.Request) {
+	request.Header.Set("Cache-Control", "no-cache")
+	request.Header.Set("Pragma", "no-cache")
+
+	parameter := "_wg=" + newNonce()
+	if request.URL.RawQuery == "" {
+		request.URL.RawQuery = parameter
+	} else {
+		request.URL.RawQuery += "&" + parameter
+	}
+}
+
+// bodyHash returns the hex SHA-256 of body, optionally ignoring whitespace so
+// reformatted markup does not count as a change.
+func bodyHash(body string, ignoreWhitespace bool) string {
+	if ignoreWhitespace {
+		body = strings.Join(strings.Fields(body), "")
+	}
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// hasExpectedContentType compares the media type of the response with
+// expected_content_type, ignoring parameters such as charset. A type/*
+// pattern matches any subtype. Monitorings without one always match.
+func (r *Runner) hasExpectedContentType(monitoring monitor.Monitoring, timings httpTimings) bool {
+	if monitoring.ExpectedContentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(timings.ContentType)
+	if err != nil {
+		mediaType = ""
+	}
+	expected, _, _ := strings.Cut(monitoring.ExpectedContentType, ";")
+	expectedType, expectedSubtype, _ := strings.Cut(strings.TrimSpace(expected), "/")
+	actualType, actualSubtype, _ := strings.Cut(mediaType, "/")
+	if actualType == expectedType && (expectedSubtype == "*" || actualSubtype == expectedSubtype) {
+		return true
+	}
+	r.logger.Info("Unexpected response content type", "monitoring_id", monitoring.ID, "expected_content_type", monitoring.ExpectedContentType, "content_type", timings.ContentType)
+	return false
+}
+
+func isExpectedStatusCode(monitoring monitor.Monitoring, statusCode int) bool {
+	if len(monitoring.ExpectedStatusCodes) > 0 {
+		return monitoring.ExpectedStatusCodes.Contains(statusCode)
+	}
+	return statusCode >= http.StatusOK && statusCode < http.StatusBadRequest
+}
+
+func (r *Runner) handleKeywordMonitoring(ctx context.Context, monitoring monitor.Monitoring) (monitor.Status, *float64, *int, checkDetails) {
+	start := time.Now()
+	scanner := newKeywordScanner(monitoring)
+	statusCode, body, timings, err := r.performHTTPRequestWithScanner(ctx, monitoring, scanner)
+	if err != nil {
+		reason := httpFailureReason(err)
+		return monitor.StatusDown, nil, nil, checkDetails{FailureReason: &reason}
+	}
+	httpStatusCode := intPointer(statusCode)
+	expectedStatus := isExpectedStatusCode(monitoring, statusCode)
+	if expectedStatus && !r.hasExpectedContentType(monitoring, timings) {
+		reason := monitor.HTTPFailureUnexpectedContent
+		return monitor.StatusDown, nil, httpStatusCode, checkDetails{httpTimings: timings, FailureReason: &reason}
+	}
+	var matched bool
+	if scanner != nil && scanner.streamed {
+		matched = scanner.matched(monitoring.KeywordMode)
+	} else {
+		matched, err = matchKeyword(body, monitoring)
+	}
+	if err != nil {
+		r.logger.Warn("Invalid keyword pattern", "monitoring_id", monitoring.ID, "keyword", monitoring.Keyword, "error", err)
+		return monitor.StatusDown, nil, httpStatusCode, checkDetails{httpTimings: timings}
+	}
+	if matched {
+		responseTime := roundMilliseconds(time.Since(start))
+		return monitor.StatusUp, &responseTime, httpStatusCode, checkDetails{httpTimings: timings}
+	}
+	// A successful status with the wrong body is what a captive portal or an
+	// error page served as 200 looks like.
+	if expectedStatus {
+		reason := monitor.HTTPFailureUnexpectedContent
+		return monitor.StatusDown, nil, httpStatusCode, checkDetails{httpTimings: timings, FailureReason: &reason}
+	}
+	return monitor.StatusDown, nil, httpStatusCode, checkDetails{httpTimings: timings}
+}
+
+func matchKeyword(body string, monitoring monitor.Monitoring) (bool, error) {
+	keyword := monitoring.Keyword
+	if monitoring.KeywordCaseInsensitive && monitoring.KeywordMode != monitor.KeywordModeRegex {
+		body = strings.ToLower(body)
+		keyword = strings.ToLower(keyword)
+	}
+
+	switch monitoring.KeywordMode {
+	case monitor.KeywordModeAbsent:
+		return !strings.Contains(body, keyword), nil
+	case monitor.KeywordModeRegex:
+		if monitoring.KeywordCaseInsensitive {
+			keyword = "(?i)" + keyword
+		}
+		pattern, err := regexp.Compile(keyword)
+		if err != nil {
+			return false, err
+		}
+		return pattern.MatchString(body), nil
+	default:
+		return strings.Contains(body, keyword), nil
+	}
+}
+
+func (r *Runner) handlePingMonitoring(ctx context.Context, monitoring monitor.Monitoring) (monitor.Status, *float64, *string) {
+	host, err := target.Host(monitoring.Target)
+	if err != nil {
+		return monitor.StatusDown, nil, nil
+	}
+
+	timeoutSeconds := fixedPingTimeoutSeconds
+	if monitoring.Timeout > 0 {
+		timeoutSeconds = monitoring.Timeout
+	}
 
-func (r *Runner) handleHTTPMonitoring(ctx context.Context, monitoring monitor.Monitoring) (monitor.Status, *float64, *int) {
-	start := time.Now()
-	statusCode, _, err := r.performHTTPRequest(ctx, monitoring)
+	host, err = r.resolveTargetHostVersion(ctx, host, monitoring.IPVersion)
 	if err != nil {
 		return monitor.StatusDown, nil, nil
 	}
-	httpStatusCode := intPointer(statusCode)
-	if statusCode >= http.StatusOK && statusCode < http.StatusBadRequest {
-		responseTime := roundMilliseconds(time.Since(start))
-		return monitor.StatusUp, &responseTime, httpStatusCode
-	}
-	return monitor.StatusDown, nil, httpStatusCode
-}
 
-func (r *Runner) handleKeywordMonitoring(ctx context.Context, monitoring monitor.Monitoring) (monitor.Status, *float64, *int) {
-	start := time.Now()
-	statusCode, body, err := r.performHTTPRequest(ctx, monitoring)
-	if err != nil {
-		return monitor.StatusDown, nil, nil
+	var latencies []float64
+	var failedResponseTime *float64
+	var resolvedIP *string
+	for range probeCount(monitoring) {
+		if ctx.Err() != nil {
+			break
+		}
+		start := time.Now()
+		output, err := pingExecutor(ctx, host, timeoutSeconds)
+		responseTime := parsePingLatency(output)
+		if responseTime == nil {
+			elapsed := roundMilliseconds(time.Since(start))
+			responseTime = &elapsed
+		}
+		if address := parsePingAddress(output); address != nil {
+			resolvedIP = address
+		}
+		if err != nil {
+			failedResponseTime = responseTime
+			continue
+		}
+		latencies = append(latencies, *responseTime)
 	}
-	httpStatusCode := intPointer(statusCode)
-	if strings.Contains(body, monitoring.Keyword) {
-		responseTime := roundMilliseconds(time.Since(start))
-		return monitor.StatusUp, &responseTime, httpStatusCode
+	if len(latencies) == 0 {
+		return monitor.StatusDown, failedResponseTime, resolvedIP
 	}
-	return monitor.StatusDown, nil, httpStatusCode
+
+	return monitor.StatusUp, averageLatency(latencies), resolvedIP
 }
 
-func handlePingMonitoring(monitoring monitor.Monitoring) (monitor.Status, *float64) {
+func (r *Runner) handleICMPMonitoring(ctx context.Context, monitoring monitor.Monitoring) (monitor.Status, *float64) {
 	host, err := target.Host(monitoring.Target)
 	if err != nil {
 		return monitor.StatusDown, nil
 	}
+	host, err = r.resolveTargetHostVersion(ctx, host, monitoring.IPVersion)
+	if err != nil {
+		return monitor.StatusDown, nil
+	}
 
 	timeoutSeconds := fixedPingTimeoutSeconds
 	if monitoring.Timeout > 0 {
 		timeoutSeconds = monitoring.Timeout
 	}
 
-	start := time.Now()
-	output, err := pingExecutor(context.Background(), host, timeoutSeconds)
-	responseTime := parsePingLatency(output)
-	if responseTime == nil {
-		elapsed := roundMilliseconds(time.Since(start))
-		responseTime = &elapsed
+	var latencies []float64
+	for range probeCount(monitoring) {
+		if ctx.Err() != nil {
+			break
+		}
+		latency, err := icmpEchoer(ctx, r.resolver, host, time.Duration(timeoutSeconds)*time.Second)
+		if err != nil {
+			continue
+		}
+		latencies = append(latencies, roundMilliseconds(latency))
 	}
-	if err != nil {
-		return monitor.StatusDown, responseTime
+	if len(latencies) == 0 {
+		return monitor.StatusDown, nil
 	}
 
-	return monitor.StatusUp, responseTime
+	return monitor.StatusUp, averageLatency(latencies)
 }
 
 func runPingCommand(ctx context.Context, host string, timeoutSeconds int) ([]byte, error) {
@@ -512,110 +1407,544 @@ func parsePingLatency(output []byte) *float64 {
 	return &rounded
 }
 
-func handlePortMonitoring(monitoring monitor.Monitoring) (monitor.Status, *float64) {
+// parsePingAddress extracts the IP the ping command actually reached, either
+// from the "PING host (ip)" header or from a "bytes from ip:" reply line.
+func parsePingAddress(output []byte) *string {
+	for _, pattern := range pingAddressPatterns {
+		matches := pattern.FindSubmatch(output)
+		if len(matches) < 2 {
+			continue
+		}
+		if ip := net.ParseIP(string(matches[1])); ip != nil {
+			address := ip.String()
+			return &address
+		}
+	}
+	return nil
+}
+
+// handlePortMonitoring reports how long a failed TCP dial took and why it
+// failed, so a refused (closed) port can be told apart from a filtered one.
+func (r *Runner) handlePortMonitoring(ctx context.Context, monitoring monitor.Monitoring) (monitor.Status, *float64, checkDetails) {
 	if monitoring.Port <= 0 {
-		return monitor.StatusDown, nil
+		return monitor.StatusDown, nil, checkDetails{}
 	}
 
 	address, err := target.TCPAddress(monitoring.Target, monitoring.Port)
 	if err != nil {
-		return monitor.StatusDown, nil
+		return monitor.StatusDown, nil, checkDetails{}
+	}
+
+	if monitoring.Protocol == monitor.ProtocolUDP {
+		status, responseTime := r.handleUDPPortMonitoring(ctx, monitoring, address)
+		return status, responseTime, checkDetails{}
+	}
+
+	timeoutSeconds := fixedPortTimeoutSeconds
+	if monitoring.Timeout > 0 {
+		timeoutSeconds = monitoring.Timeout
+	}
+
+	dialer := r.dialer(time.Duration(timeoutSeconds) * time.Second)
+	var latencies []float64
+	var failedResponseTime float64
+	var failureReason string
+	var resolvedIP *string
+	for range probeCount(monitoring) {
+		if err := ctx.Err(); err != nil {
+			if failureReason == "" {
+				failureReason = portFailureReason(err)
+			}
+			break
+		}
+		start := time.Now()
+		conn, err := r.dialTCP(ctx, dialer, ipNetwork("tcp", monitoring.IPVersion), address)
+		responseTime := roundMilliseconds(time.Since(start))
+		if err != nil {
+			failedResponseTime = responseTime
+			failureReason = portFailureReason(err)
+			continue
+		}
+		resolvedIP = remoteIP(conn.RemoteAddr())
+		if tcpConn, ok := conn.(*net.TCPConn); ok && monitoring.PortProbeMode == monitor.PortProbeModeReset {
+			_ = tcpConn.SetLinger(0)
+		}
+		_ = conn.Close()
+		latencies = append(latencies, responseTime)
+	}
+	if len(latencies) == 0 {
+		return monitor.StatusDown, &failedResponseTime, checkDetails{FailureReason: &failureReason}
+	}
+
+	return monitor.StatusUp, averageLatency(latencies), checkDetails{httpTimings: httpTimings{ResolvedIP: resolvedIP}}
+}
+
+// httpFailureReason classifies why an HTTP request got no response, so a
+// dashboard can tell a DNS problem from a closed port or a broken
+// certificate.
+func httpFailureReason(err error) string {
+	var dnsErr *net.DNSError
+	var recordHeaderErr tls.RecordHeaderError
+	var certificateErr *tls.CertificateVerificationError
+	var alertErr tls.AlertError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, target.ErrPrivateAddress):
+		return monitor.FailureTargetBlocked
+	case errors.As(err, &dnsErr):
+		return monitor.HTTPFailureDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return monitor.HTTPFailureConnectionRefused
+	case errors.Is(err, syscall.ECONNRESET):
+		return monitor.HTTPFailureConnectionReset
+	case errors.As(err, &recordHeaderErr), errors.As(err, &certificateErr), errors.As(err, &alertErr):
+		return monitor.HTTPFailureTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return monitor.HTTPFailureTimeout
+	case errors.Is(err, errTLSHandshake):
+		return monitor.HTTPFailureTLS
+	case errors.Is(err, errSchemeDowngraded):
+		return monitor.HTTPFailureSchemeDowngraded
+	default:
+		return monitor.HTTPFailureRequest
+	}
+}
+
+func portFailureReason(err error) string {
+	if errors.Is(err, target.ErrPrivateAddress) {
+		return monitor.FailureTargetBlocked
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return monitor.PortFailureConnectionRefused
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return monitor.PortFailureTimeout
+	}
+	return monitor.PortFailureUnreachable
+}
+
+func (r *Runner) handleUDPPortMonitoring(ctx context.Context, monitoring monitor.Monitoring, address string) (monitor.Status, *float64) {
+	timeout := time.Duration(fixedUDPTimeoutSeconds) * time.Second
+	if monitoring.Timeout > 0 {
+		timeout = time.Duration(monitoring.Timeout) * time.Second
+	}
+
+	probe := []byte(monitoring.ProbePayload)
+	if len(probe) == 0 {
+		probe = []byte(defaultUDPProbe)
 	}
 
 	start := time.Now()
-	conn, err := net.DialTimeout("tcp", address, 5*time.Second)
+	conn, err := r.dialer(timeout).DialContext(ctx, ipNetwork("udp", monitoring.IPVersion), address)
 	if err != nil {
 		return monitor.StatusDown, nil
 	}
-	_ = conn.Close()
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := conn.SetDeadline(start.Add(timeout)); err != nil {
+		return monitor.StatusDown, nil
+	}
+	if _, err := conn.Write(probe); err != nil {
+		return monitor.StatusDown, nil
+	}
+
+	reply := make([]byte, 1500)
+	if _, err := conn.Read(reply); err != nil {
+		return monitor.StatusDown, nil
+	}
 
 	responseTime := roundMilliseconds(time.Since(start))
 	return monitor.StatusUp, &responseTime
 }
 
-func (r *Runner) performHTTPRequest(ctx context.Context, monitoring monitor.Monitoring) (int, string, error) {
-	targetURL := strings.TrimSpace(monitoring.Target)
+// httpProxy picks the proxy for HTTP checks: the monitoring's own proxy_url
+// wins over HTTP_PROXY_URL, and without either the standard HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY variables apply.
+func (r *Runner) httpProxy(monitoring monitor.Monitoring) (func(*http.Request) (*url.URL, error), error) {
+	rawProxy := monitoring.ProxyURL
+	if rawProxy == "" {
+		rawProxy = strings.TrimSpace(r.cfg.HTTPProxyURL)
+	}
+	if rawProxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	proxyURL, err := url.Parse(rawProxy)
+	if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy url %q", rawProxy)
+	}
+	return http.ProxyURL(proxyURL), nil
+}
+
+func (r *Runner) performHTTPRequest(ctx context.Context, monitoring monitor.Monitoring) (int, string, httpTimings, error) {
+	return r.performHTTPRequestWithScanner(ctx, monitoring, nil)
+}
+
+// performHTTPRequestWithScanner hands an uncompressed response body to
+// scanner instead of buffering it, so reading stops once the keyword is
+// found. The returned body is empty when the scanner consumed it.
+func (r *Runner) performHTTPRequestWithScanner(ctx context.Context, monitoring monitor.Monitoring, scanner *keywordScanner) (int, string, httpTimings, error) {
+	targetURL, credentials := target.SplitUserinfo(target.HTTPURL(monitoring.Target))
 	if targetURL == "" {
-		return 0, "", fmt.Errorf("monitoring target is empty")
+		return 0, "", httpTimings{}, fmt.Errorf("monitoring target is empty")
 	}
 
 	method := strings.ToLower(strings.TrimSpace(string(monitoring.HTTPMethod)))
-	if method == "" || !slices.Contains([]string{"get", "post", "put", "patch", "delete"}, method) {
+	if method == "" || !slices.Contains([]string{"get", "post", "put", "patch", "delete", "head", "options"}, method) {
 		method = string(monitor.HTTPMethodGet)
 	}
 
-	headers := normalizeHeaders(monitoring.HTTPHeaders)
-	body := normalizeBody(monitoring.HTTPBody)
-	if method == "get" || method == "delete" {
-		body = nil
+	sendsBody := method != "get" && method != "delete" && method != "head" && method != "options"
+
+	transport, err := r.httpTransport(monitoring)
+	if err != nil {
+		return 0, "", httpTimings{}, err
+	}
+
+	// HTTP/3 needs a QUIC transport, which this build does not ship.
+	if monitoring.HTTPProtocol == monitor.HTTPProtocolHTTP3 {
+		r.logger.Warn("HTTP/3 checks are not supported", "monitoring_id", monitoring.ID)
+		return 0, "", httpTimings{}, errHTTP3Unsupported
+	}
+
+	// A fresh jar per check lets login redirects carry their session cookie
+	// without leaking cookies into other checks.
+	var jar http.CookieJar
+	if monitoring.UseCookieJar {
+		jar, err = cookiejar.New(nil)
+		if err != nil {
+			return 0, "", httpTimings{}, err
+		}
 	}
-	if len(body) > 0 && headers["Content-Type"] == "" && headers["content-type"] == "" {
-		headers["Content-Type"] = "application/json"
+
+	maxRedirects := fixedHTTPMaxRedirects
+	if monitoring.MaxRedirects != nil && *monitoring.MaxRedirects >= 0 {
+		maxRedirects = *monitoring.MaxRedirects
 	}
 
 	httpClient := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true, //nolint:gosec // Keep PHP compatibility (withoutVerifying)
-			},
-		},
-		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
-			if len(via) >= fixedHTTPMaxRedirects {
-				return fmt.Errorf("stopped after %d redirects", fixedHTTPMaxRedirects)
+		Jar:       jar,
+		Transport: transport,
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if maxRedirects == 0 {
+				return http.ErrUseLastResponse
+			}
+			if len(via) > maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if monitoring.FailOnSchemeDowngrade && schemeDowngraded(via[len(via)-1].URL, request.URL) {
+				return errSchemeDowngraded
+			}
+			if r.cfg.BlockPrivateTargets {
+				if err := target.IsAllowed(request.Context(), request.URL.Hostname(), r.resolver); err != nil {
+					return err
+				}
 			}
 			return nil
 		},
 	}
+	// The monitoring timeout bounds the whole check: every attempt, redirect
+	// and backoff sleep shares one deadline.
+	timeoutSeconds := fixedHTTPTimeoutSeconds
 	if monitoring.Timeout > 0 {
-		httpClient.Timeout = time.Duration(monitoring.Timeout) * time.Second
+		timeoutSeconds = monitoring.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+	retryDeadline, _ := ctx.Deadline()
+
+	retryTimes := max(0, r.cfg.HTTPRetryTimes)
+	// A repeated POST, PUT or PATCH may create or change something twice,
+	// so only idempotent methods are retried unless the monitoring opts in.
+	if !idempotentMethod(method) && !monitoring.RetryUnsafeMethods {
+		retryTimes = 0
 	}
-
-	retryTimes := fixedHTTPRetryTimes
 	attempts := retryTimes + 1
-	delay := fixedHTTPRetryDelay
+	baseDelay := time.Duration(max(0, r.cfg.HTTPRetryBaseDelayMS)) * time.Millisecond
 
 	var lastErr error
 	for attempt := 0; attempt < attempts; attempt++ {
+		// Template variables are expanded per attempt so retries do not
+		// resend a stale timestamp or nonce.
+		variables := newTemplateVariables(r.clock.Now())
+		headers := normalizeHeaders(monitoring.HTTPHeaders, variables)
+		var body []byte
+		if sendsBody {
+			body = normalizeBody(monitoring.HTTPBody, variables)
+		}
+		if len(body) > 0 && headers["Content-Type"] == "" && headers["content-type"] == "" {
+			headers["Content-Type"] = "application/json"
+		}
+
 		var requestBody io.Reader
 		if len(body) > 0 {
 			requestBody = bytes.NewReader(body)
 		}
 
-		request, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), targetURL, requestBody)
+		tracer := newHTTPTimingTracer(time.Now())
+		request, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, tracer.clientTrace()), strings.ToUpper(method), targetURL, requestBody)
 		if err != nil {
-			return 0, "", err
+			return 0, "", httpTimings{}, err
 		}
 
+		// Monitoring headers are applied afterwards so their User-Agent,
+		// Accept-Language and cache headers win.
+		if r.cfg.HTTPUserAgent != "" {
+			request.Header.Set("User-Agent", r.cfg.HTTPUserAgent)
+		}
+		if language := cmp.Or(monitoring.AcceptLanguage, r.cfg.HTTPAcceptLanguage); language != "" {
+			request.Header.Set("Accept-Language", language)
+		}
+		if r.cfg.BypassCache || monitoring.BypassCache {
+			bypassCache(request)
+		}
+		r.setConditionalHeaders(request, monitoring)
 		for key, value := range headers {
 			request.Header.Set(key, value)
 		}
-		if monitoring.AuthUsername != "" && monitoring.AuthPassword != "" {
-			request.SetBasicAuth(monitoring.AuthUsername, monitoring.AuthPassword)
-		}
+		setAuthorization(request, monitoring, credentials)
 
 		response, err := httpClient.Do(request)
 		if err != nil {
+			// Handshake failures such as a protocol version below
+			// TLS_MIN_VERSION carry no typed error of their own.
+			if tracer.handshakeFailed() {
+				err = fmt.Errorf("%w: %w", errTLSHandshake, err)
+			}
+			// Retrying cannot change where the target redirects to.
+			if errors.Is(err, errSchemeDowngraded) || errors.Is(err, target.ErrPrivateAddress) {
+				return 0, "", httpTimings{}, err
+			}
 			lastErr = err
-			if attempt < attempts-1 {
-				time.Sleep(delay)
+			if attempt == attempts-1 {
+				return 0, "", httpTimings{}, lastErr
+			}
+
+			delay := retryDelay(baseDelay, attempt)
+			if !retryDeadline.IsZero() && time.Now().Add(delay).After(retryDeadline) {
+				return 0, "", httpTimings{}, lastErr
+			}
+			if err := sleepContext(ctx, delay); err != nil {
+				return 0, "", httpTimings{}, lastErr
+			}
+			continue
+		}
+
+		tracer.observeResponse(response, monitoring.RecordHopStatuses)
+
+		if monitoring.HTTPProtocol == monitor.HTTPProtocolHTTP2 && response.ProtoMajor != 2 {
+			_ = response.Body.Close()
+			return 0, "", httpTimings{}, fmt.Errorf("expected HTTP/2, negotiated %s", response.Proto)
+		}
+
+		if isRetryableStatus(response.StatusCode) && attempt < attempts-1 {
+			delay, ok := parseRetryAfter(response.Header.Get("Retry-After"), time.Now())
+			if !ok {
+				delay = retryDelay(baseDelay, attempt)
+			}
+			if retryDeadline.IsZero() || !time.Now().Add(delay).After(retryDeadline) {
+				_, _ = io.Copy(io.Discard, response.Body)
+				_ = response.Body.Close()
+				if err := sleepContext(ctx, delay); err != nil {
+					return response.StatusCode, "", tracer.result(), nil
+				}
 				continue
 			}
-			return 0, "", lastErr
 		}
 
-		payload, err := io.ReadAll(response.Body)
+		if method == "head" {
+			_ = response.Body.Close()
+			return response.StatusCode, "", tracer.result(), nil
+		}
+
+		maxBodyBytes := r.cfg.HTTPMaxBodyBytes
+		if maxBodyBytes <= 0 {
+			maxBodyBytes = defaultHTTPMaxBodyBytes
+		}
+
+		// Closing the body before EOF drops the connection, which ends the
+		// download as soon as the scanner has its answer.
+		encoding := response.Header.Get("Content-Encoding")
+		if scanner != nil && (encoding == "" || response.Uncompressed) {
+			consumed, err := scanner.scan(io.LimitReader(response.Body, int64(maxBodyBytes)))
+			if err == nil && !scanner.found && consumed == maxBodyBytes {
+				if n, _ := response.Body.Read(make([]byte, 1)); n > 0 {
+					r.logger.Warn(
+						"HTTP response body truncated",
+						"monitoring_id", monitoring.ID,
+						"limit_bytes", maxBodyBytes,
+					)
+				}
+			}
+			_ = response.Body.Close()
+			if err != nil {
+				return 0, "", httpTimings{}, err
+			}
+			return response.StatusCode, "", tracer.result(), nil
+		}
+
+		payload, err := io.ReadAll(io.LimitReader(response.Body, int64(maxBodyBytes)+1))
 		_ = response.Body.Close()
 		if err != nil {
-			return 0, "", err
+			return 0, "", httpTimings{}, err
+		}
+		if len(payload) > maxBodyBytes {
+			payload = payload[:maxBodyBytes]
+			r.logger.Warn(
+				"HTTP response body truncated",
+				"monitoring_id", monitoring.ID,
+				"limit_bytes", maxBodyBytes,
+			)
+		}
+
+		// The transport only decompresses when it negotiated the encoding
+		// itself; a monitoring sending its own Accept-Encoding gets raw bytes.
+		if encoding != "" && !response.Uncompressed {
+			decoded, err := decompressBody(encoding, payload, maxBodyBytes)
+			if err != nil {
+				r.logger.Warn(
+					"Failed to decompress HTTP response body",
+					"monitoring_id", monitoring.ID,
+					"content_encoding", encoding,
+					"error", err,
+				)
+			} else {
+				payload = decoded
+			}
+		}
+
+		return response.StatusCode, string(payload), tracer.result(), nil
+	}
+
+	return 0, "", httpTimings{}, lastErr
+}
+
+// decompressBody decodes gzip and deflate bodies, keeping at most limit
+// bytes. Other encodings are returned unchanged, and a stream cut short by
+// the body limit still yields what could be decoded.
+func decompressBody(encoding string, payload []byte, limit int) ([]byte, error) {
+	var reader io.ReadCloser
+	var err error
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(payload))
+	case "deflate":
+		reader, err = zlib.NewReader(bytes.NewReader(payload))
+	default:
+		return payload, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	decoded, err := io.ReadAll(io.LimitReader(reader, int64(limit)))
+	if err != nil && len(decoded) == 0 {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+func loadClientCertificate(monitoring monitor.Monitoring) (tls.Certificate, bool, error) {
+	certPEM := strings.TrimSpace(monitoring.ClientCertPEM)
+	keyPEM := strings.TrimSpace(monitoring.ClientKeyPEM)
+	if certPEM == "" && keyPEM == "" {
+		return tls.Certificate{}, false, nil
+	}
+	if certPEM == "" || keyPEM == "" {
+		return tls.Certificate{}, false, fmt.Errorf("client certificate and key must both be set")
+	}
+
+	certBlock, err := decodePEMOrBase64(certPEM)
+	if err != nil {
+		return tls.Certificate{}, false, fmt.Errorf("invalid client certificate: %w", err)
+	}
+	keyBlock, err := decodePEMOrBase64(keyPEM)
+	if err != nil {
+		return tls.Certificate{}, false, fmt.Errorf("invalid client key: %w", err)
+	}
+
+	certificate, err := tls.X509KeyPair(certBlock, keyBlock)
+	if err != nil {
+		return tls.Certificate{}, false, fmt.Errorf("invalid client certificate pair: %w", err)
+	}
+	return certificate, true, nil
+}
+
+func decodePEMOrBase64(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return base64.StdEncoding.DecodeString(value)
+}
+
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
 		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	retryAt, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(0, retryAt.Sub(now)), true
+}
+
+func retryDelay(baseDelay time.Duration, attempt int) time.Duration {
+	if baseDelay <= 0 {
+		return 0
+	}
+	delay := baseDelay << attempt
+	jitter := time.Duration(retryJitter() * float64(delay) / 2)
+	return delay + jitter
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
 
-		return response.StatusCode, string(payload), nil
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (r *Runner) crawlMonitoringSSL(ctx context.Context, monitoring monitor.Monitoring) monitor.SSLResultPayload {
+	start := time.Now()
+	payload := r.inspectCertificate(ctx, monitoring)
+
+	status := "invalid"
+	if payload.IsValid {
+		status = "valid"
 	}
+	r.metrics.ObserveCheck("ssl", status, time.Since(start))
 
-	return 0, "", lastErr
+	return payload
 }
 
-func (r *Runner) crawlMonitoringSSL(monitoring monitor.Monitoring) monitor.SSLResultPayload {
+func (r *Runner) inspectCertificate(ctx context.Context, monitoring monitor.Monitoring) monitor.SSLResultPayload {
 	payload := monitor.SSLResultPayload{
 		MonitoringID: monitoring.ID,
 		IsValid:      false,
@@ -623,30 +1952,73 @@ func (r *Runner) crawlMonitoringSSL(monitoring monitor.Monitoring) monitor.SSLRe
 
 	address, serverName, err := target.SSLAddressAndServerName(monitoring.Target)
 	if err != nil {
-		return payload
+		return sslFailure(payload, monitor.SSLFailureConnectionFailed)
+	}
+	if monitoring.TLSServerName != "" {
+		serverName = monitoring.TLSServerName
 	}
 
-	connection, err := tls.DialWithDialer(&net.Dialer{Timeout: 10 * time.Second}, "tcp", address, &tls.Config{
-		ServerName:         serverName,
-		InsecureSkipVerify: true, //nolint:gosec // Needed to inspect certificate even when invalid.
-	})
+	timeout := 10 * time.Second
+	conn, err := r.dialTCP(ctx, r.dialer(timeout), "tcp", address)
+	if errors.Is(err, target.ErrPrivateAddress) {
+		return sslFailure(payload, monitor.FailureTargetBlocked)
+	}
 	if err != nil {
-		return payload
+		return sslFailure(payload, monitor.SSLFailureConnectionFailed)
 	}
+	connection := tls.Client(conn, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,             //nolint:gosec // Needed to inspect certificate even when invalid.
+		MinVersion:         tls.VersionTLS10, //nolint:gosec // Accept legacy versions so they can be reported as deprecated.
+	})
 	defer connection.Close()
+	handshakeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := connection.HandshakeContext(handshakeCtx); err != nil {
+		return sslFailure(payload, monitor.SSLFailureConnectionFailed)
+	}
 
-	peerCertificates := connection.ConnectionState().PeerCertificates
+	state := connection.ConnectionState()
+	tlsVersion := tls.VersionName(state.Version)
+	cipherSuite := tls.CipherSuiteName(state.CipherSuite)
+	payload.TLSVersion = &tlsVersion
+	payload.CipherSuite = &cipherSuite
+	payload.Deprecated = state.Version < tls.VersionTLS12
+
+	peerCertificates := state.PeerCertificates
 	if len(peerCertificates) == 0 {
-		return payload
+		return sslFailure(payload, monitor.SSLFailureConnectionFailed)
 	}
 
 	certificate := peerCertificates[0]
-	now := time.Now()
-	if now.Before(certificate.NotBefore) || now.After(certificate.NotAfter) {
-		return payload
+	payload.ChainLength = len(peerCertificates)
+	payload.SANs = subjectAlternativeNames(certificate)
+	payload.SerialNumber, payload.Fingerprint = certificateIdentity(certificate)
+
+	now := r.clock.Now()
+	if len(peerCertificates) > 1 {
+		payload.Revoked = stapledRevocation(state.OCSPResponse, certificate, peerCertificates[1], now)
+	}
+	if now.Before(certificate.NotBefore) {
+		return sslFailure(payload, monitor.SSLFailureNotYetValid)
+	}
+	if now.After(certificate.NotAfter) {
+		return sslFailure(payload, monitor.SSLFailureExpired)
 	}
 	if err := certificate.VerifyHostname(serverName); err != nil {
-		return payload
+		return sslFailure(payload, monitor.SSLFailureHostnameMismatch)
+	}
+	if payload.Revoked != nil && *payload.Revoked {
+		return sslFailure(payload, monitor.SSLFailureRevoked)
+	}
+	// The chain is verified against CA_BUNDLE_PATH, or the system pool
+	// without one. A self-signed certificate only passes when it is itself
+	// one of the trusted roots.
+	if !r.trustedChain(peerCertificates, now) {
+		if selfSigned(certificate) {
+			return sslFailure(payload, monitor.SSLFailureSelfSigned)
+		}
+		return sslFailure(payload, monitor.SSLFailureUntrustedChain)
 	}
 
 	payload.IsValid = true
@@ -655,6 +2027,11 @@ func (r *Runner) crawlMonitoringSSL(monitoring monitor.Monitoring) monitor.SSLRe
 	payload.ExpiresAt = &expiresAt
 	payload.IssuedAt = &issuedAt
 
+	remaining := certificate.NotAfter.Sub(now)
+	daysUntilExpiry := int(remaining / (24 * time.Hour))
+	payload.DaysUntilExpiry = &daysUntilExpiry
+	payload.ExpiringSoon = remaining <= time.Duration(r.cfg.SSLExpiryWarnDays)*24*time.Hour
+
 	issuer := certificate.Issuer.CommonName
 	if issuer == "" {
 		issuer = certificate.Issuer.String()
@@ -666,6 +2043,86 @@ func (r *Runner) crawlMonitoringSSL(monitoring monitor.Monitoring) monitor.SSLRe
 	return payload
 }
 
+// selfSigned reports whether certificate names itself as issuer and is
+// signed by its own key.
+func selfSigned(certificate *x509.Certificate) bool {
+	if !bytes.Equal(certificate.RawIssuer, certificate.RawSubject) {
+		return false
+	}
+	return certificate.CheckSignatureFrom(certificate) == nil
+}
+
+// trustedChain verifies the chain against rootCAs; a nil pool means the
+// system roots.
+func (r *Runner) trustedChain(peerCertificates []*x509.Certificate, now time.Time) bool {
+	intermediates := x509.NewCertPool()
+	for _, certificate := range peerCertificates[1:] {
+		intermediates.AddCert(certificate)
+	}
+	_, err := peerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         r.rootCAs,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	return err == nil
+}
+
+// setAuthorization applies the monitoring's credentials after its headers, so
+// they replace any Authorization header set there. Credentials taken from the
+// target URL only apply when neither the auth fields nor the headers set any.
+// A bearer monitoring never falls back to basic credentials.
+func setAuthorization(request *http.Request, monitoring monitor.Monitoring, urlCredentials *url.Userinfo) {
+	switch monitoring.AuthType {
+	case monitor.AuthTypeBearer:
+		if monitoring.AuthToken != "" {
+			request.Header.Set("Authorization", "Bearer "+monitoring.AuthToken)
+		}
+	default:
+		if monitoring.AuthUsername != "" && monitoring.AuthPassword != "" {
+			request.SetBasicAuth(monitoring.AuthUsername, monitoring.AuthPassword)
+		} else if urlCredentials != nil && request.Header.Get("Authorization") == "" {
+			password, _ := urlCredentials.Password()
+			request.SetBasicAuth(urlCredentials.Username(), password)
+		}
+	}
+}
+
+func idempotentMethod(method string) bool {
+	switch method {
+	case "get", "head", "options", "delete":
+		return true
+	default:
+		return false
+	}
+}
+
+func sslFailure(payload monitor.SSLResultPayload, reason string) monitor.SSLResultPayload {
+	payload.FailureReason = &reason
+	return payload
+}
+
+// certificateIdentity returns the serial number of certificate in hex and
+// the hex SHA-256 fingerprint of its DER encoding.
+func certificateIdentity(certificate *x509.Certificate) (*string, *string) {
+	var serialNumber *string
+	if certificate.SerialNumber != nil {
+		serial := certificate.SerialNumber.Text(16)
+		serialNumber = &serial
+	}
+	sum := sha256.Sum256(certificate.Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+	return serialNumber, &fingerprint
+}
+
+func subjectAlternativeNames(certificate *x509.Certificate) []string {
+	names := make([]string, 0, len(certificate.DNSNames)+len(certificate.IPAddresses))
+	names = append(names, certificate.DNSNames...)
+	for _, address := range certificate.IPAddresses {
+		names = append(names, address.String())
+	}
+	return names
+}
+
 func (r *Runner) crawlDomainExpiration(ctx context.Context, monitoring monitor.Monitoring) (monitor.Status, monitor.DomainResultPayload, bool) {
 	lookup := r.domainLookup
 	if lookup == nil {
@@ -677,7 +2134,7 @@ func (r *Runner) crawlDomainExpiration(ctx context.Context, monitoring monitor.M
 		if domainlookup.IsTemporary(err) {
 			return monitor.StatusUnknown, monitor.DomainResultPayload{}, false
 		}
-		now := time.Now().UTC()
+		now := r.clock.Now().UTC()
 		return monitor.StatusDown, monitor.DomainResultPayload{
 			MonitoringID: monitoring.ID,
 			IsValid:      false,
@@ -687,7 +2144,7 @@ func (r *Runner) crawlDomainExpiration(ctx context.Context, monitoring monitor.M
 
 	checkedAt := result.CheckedAt
 	if checkedAt.IsZero() {
-		checkedAt = time.Now().UTC()
+		checkedAt = r.clock.Now().UTC()
 	}
 
 	isValid := result.Registered && result.ExpiresAt != nil && result.ExpiresAt.After(checkedAt.Add(30*24*time.Hour))
@@ -705,7 +2162,9 @@ func (r *Runner) crawlDomainExpiration(ctx context.Context, monitoring monitor.M
 	}, true
 }
 
-func normalizeHeaders(rawHeaders any) map[string]string {
+// normalizeHeaders stringifies the monitoring headers and expands template
+// variables in their values; variables may be nil to skip expansion.
+func normalizeHeaders(rawHeaders any, variables *strings.Replacer) map[string]string {
 	result := make(map[string]string)
 
 	switch value := rawHeaders.(type) {
@@ -732,17 +2191,23 @@ func normalizeHeaders(rawHeaders any) map[string]string {
 		}
 	}
 
+	for key, raw := range result {
+		result[key] = expandTemplate(raw, variables)
+	}
 	return result
 }
 
-func normalizeBody(rawBody any) []byte {
+// normalizeBody encodes the monitoring body as JSON. Template variables in a
+// string body are expanded before it is parsed, so {{unix}} can stand in for
+// a bare number; variables may be nil to skip expansion.
+func normalizeBody(rawBody any, variables *strings.Replacer) []byte {
 	if rawBody == nil {
 		return []byte("[]")
 	}
 
 	switch value := rawBody.(type) {
 	case string:
-		trimmed := strings.TrimSpace(value)
+		trimmed := strings.TrimSpace(expandTemplate(value, variables))
 		if trimmed == "" {
 			return []byte("[]")
 		}
@@ -760,7 +2225,7 @@ func normalizeBody(rawBody any) []byte {
 		if err != nil {
 			return []byte("[]")
 		}
-		return payload
+		return []byte(expandTemplate(string(payload), variables))
 	}
 }
 
@@ -780,9 +2245,23 @@ func pointerFloat64Value(value *float64) any {
 	return *value
 }
 
+func pointerBoolValue(value *bool) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
 func pointerIntValue(value *int) any {
 	if value == nil {
 		return nil
 	}
 	return *value
 }
+
+func pointerStringValue(value *string) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
@@ -2,16 +2,38 @@ package runner
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"io"
 	"log"
+	"log/slog"
+	"math/big"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
@@ -19,6 +41,7 @@ import (
 	"github.com/m-breuer/webguard-instance-v2/internal/core"
 	"github.com/m-breuer/webguard-instance-v2/internal/domainlookup"
 	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+	"github.com/m-breuer/webguard-instance-v2/internal/target"
 )
 
 type staticDomainLookup struct {
@@ -37,7 +60,7 @@ func (s staticDomainLookup) Lookup(_ context.Context, target string) (domainlook
 func TestNormalizeHeaders(t *testing.T) {
 	t.Parallel()
 
-	headers := normalizeHeaders(`{"X-Test":"value","X-Int":1}`)
+	headers := normalizeHeaders(`{"X-Test":"value","X-Int":1}`, nil)
 	if headers["X-Test"] != "value" {
 		t.Fatalf("expected X-Test header")
 	}
@@ -45,7 +68,7 @@ func TestNormalizeHeaders(t *testing.T) {
 		t.Fatalf("expected X-Int header to be stringified")
 	}
 
-	headers = normalizeHeaders("not-json")
+	headers = normalizeHeaders("not-json", nil)
 	if len(headers) != 0 {
 		t.Fatalf("expected empty headers for invalid json, got %#v", headers)
 	}
@@ -54,7 +77,7 @@ func TestNormalizeHeaders(t *testing.T) {
 func TestNormalizeBody(t *testing.T) {
 	t.Parallel()
 
-	body := normalizeBody(`{"key":"value"}`)
+	body := normalizeBody(`{"key":"value"}`, nil)
 	var parsed map[string]string
 	if err := json.Unmarshal(body, &parsed); err != nil {
 		t.Fatalf("expected valid JSON body, got error: %v", err)
@@ -63,12 +86,241 @@ func TestNormalizeBody(t *testing.T) {
 		t.Fatalf("unexpected parsed value: %#v", parsed)
 	}
 
-	body = normalizeBody("invalid-json")
+	body = normalizeBody("invalid-json", nil)
 	if string(body) != "[]" {
 		t.Fatalf("expected fallback body [] for invalid JSON string, got %s", string(body))
 	}
 }
 
+func TestNormalizeBodyExpandsTemplateVariables(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.March, 4, 5, 6, 7, 0, time.UTC)
+	body := normalizeBody(`{"sent_at":"{{timestamp}}","epoch":{{unix}},"nonce":"{{uuid}}"}`, newTemplateVariables(now))
+
+	var parsed struct {
+		SentAt string `json:"sent_at"`
+		Epoch  int64  `json:"epoch"`
+		Nonce  string `json:"nonce"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		t.Fatalf("expected valid JSON body, got %s: %v", string(body), err)
+	}
+	if parsed.SentAt != "2026-03-04T05:06:07Z" {
+		t.Fatalf("expected timestamp 2026-03-04T05:06:07Z, got %q", parsed.SentAt)
+	}
+	if parsed.Epoch != now.Unix() {
+		t.Fatalf("expected unix %d, got %d", now.Unix(), parsed.Epoch)
+	}
+	if !uuidPattern.MatchString(parsed.Nonce) {
+		t.Fatalf("expected a version 4 uuid, got %q", parsed.Nonce)
+	}
+
+	literal := normalizeBody(map[string]any{"key": "value"}, newTemplateVariables(now))
+	if string(literal) != `{"key":"value"}` {
+		t.Fatalf("expected literal body to stay unchanged, got %s", string(literal))
+	}
+}
+
+func TestPerformHTTPRequestExpandsTemplatesPerRequest(t *testing.T) {
+	t.Parallel()
+
+	type received struct {
+		header string
+		nonce  string
+	}
+	requests := make(chan received, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var body struct {
+			Nonce string `json:"nonce"`
+		}
+		_ = json.NewDecoder(request.Body).Decode(&body)
+		requests <- received{header: request.Header.Get("X-Request-ID"), nonce: body.Nonce}
+		writer.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	monitoring := monitor.Monitoring{
+		Target:      server.URL,
+		Timeout:     2,
+		HTTPMethod:  monitor.HTTPMethodPost,
+		HTTPHeaders: `{"X-Request-ID":"{{uuid}}"}`,
+		HTTPBody:    `{"nonce":"{{uuid}}"}`,
+	}
+
+	seen := make(map[string]bool)
+	for range 2 {
+		if _, _, _, err := r.performHTTPRequest(context.Background(), monitoring); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := <-requests
+		if !uuidPattern.MatchString(got.header) || got.header != got.nonce {
+			t.Fatalf("expected matching uuids in header and body, got %q and %q", got.header, got.nonce)
+		}
+		if seen[got.nonce] {
+			t.Fatalf("expected a fresh uuid per request, got %q twice", got.nonce)
+		}
+		seen[got.nonce] = true
+	}
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestPerformHTTPRequestBypassesCache(t *testing.T) {
+	t.Parallel()
+
+	requests := make(chan *http.Request, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests <- request
+		writer.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	testCases := []struct {
+		name        string
+		cfg         config.Config
+		bypassCache bool
+		target      string
+	}{
+		{name: "global option", cfg: config.Config{BypassCache: true}, target: server.URL + "/status"},
+		{name: "monitoring option keeps query", bypassCache: true, target: server.URL + "/status?region=eu&flag"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r := New(nil, testCase.cfg, slog.New(slog.DiscardHandler), nil)
+			monitoring := monitor.Monitoring{
+				Target:      testCase.target,
+				Timeout:     2,
+				HTTPMethod:  monitor.HTTPMethodGet,
+				BypassCache: testCase.bypassCache,
+			}
+
+			targetURL, err := url.Parse(testCase.target)
+			if err != nil {
+				t.Fatalf("failed to parse target: %v", err)
+			}
+			seen := make(map[string]bool)
+			for range 2 {
+				if _, _, _, err := r.performHTTPRequest(context.Background(), monitoring); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				request := <-requests
+				if got := request.Header.Get("Cache-Control"); got != "no-cache" {
+					t.Fatalf("expected Cache-Control no-cache, got %q", got)
+				}
+				if got := request.Header.Get("Pragma"); got != "no-cache" {
+					t.Fatalf("expected Pragma no-cache, got %q", got)
+				}
+
+				prefix := ""
+				if targetURL.RawQuery != "" {
+					prefix = targetURL.RawQuery + "&"
+				}
+				if !strings.HasPrefix(request.URL.RawQuery, prefix+"_wg=") {
+					t.Fatalf("expected query %q to keep %q and append _wg, got %q", testCase.target, targetURL.RawQuery, request.URL.RawQuery)
+				}
+				nonce := request.URL.Query().Get("_wg")
+				if nonce == "" || seen[nonce] {
+					t.Fatalf("expected a fresh _wg nonce per request, got %q", nonce)
+				}
+				seen[nonce] = true
+			}
+		})
+	}
+}
+
+func TestPerformHTTPRequestSendsAcceptLanguage(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = io.WriteString(writer, request.Header.Get("Accept-Language"))
+	}))
+	t.Cleanup(server.Close)
+
+	testCases := []struct {
+		name           string
+		configured     string
+		monitoring     string
+		headers        any
+		expectedHeader string
+	}{
+		{name: "not configured", expectedHeader: ""},
+		{name: "global setting", configured: "de-DE", expectedHeader: "de-DE"},
+		{name: "monitoring override", configured: "de-DE", monitoring: "fr-FR", expectedHeader: "fr-FR"},
+		{name: "monitoring header wins", configured: "de-DE", monitoring: "fr-FR", headers: `{"Accept-Language":"es-ES"}`, expectedHeader: "es-ES"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := New(nil, config.Config{HTTPAcceptLanguage: testCase.configured}, slog.New(slog.DiscardHandler), nil)
+			_, body, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+				Target:         server.URL,
+				Timeout:        2,
+				HTTPMethod:     monitor.HTTPMethodGet,
+				HTTPHeaders:    testCase.headers,
+				AcceptLanguage: testCase.monitoring,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if body != testCase.expectedHeader {
+				t.Fatalf("expected Accept-Language %q, got %q", testCase.expectedHeader, body)
+			}
+		})
+	}
+}
+
+func TestPerformHTTPRequestLeavesCacheAloneByDefault(t *testing.T) {
+	t.Parallel()
+
+	requests := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests <- request
+		writer.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	if _, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{Target: server.URL, Timeout: 2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	request := <-requests
+	if request.URL.RawQuery != "" || request.Header.Get("Cache-Control") != "" || request.Header.Get("Pragma") != "" {
+		t.Fatalf("expected no cache bypass, got query %q and headers %v", request.URL.RawQuery, request.Header)
+	}
+}
+
+func TestPerformHTTPRequestAcceptsTargetWithoutScheme(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/health" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	statusCode, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+		Target:     strings.TrimPrefix(server.URL, "http://") + "/health",
+		Timeout:    2,
+		HTTPMethod: monitor.HTTPMethodGet,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if statusCode != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", statusCode)
+	}
+}
+
 func TestPerformHTTPRequestGETWithHeadersAndBasicAuth(t *testing.T) {
 	t.Parallel()
 
@@ -93,8 +345,8 @@ func TestPerformHTTPRequestGETWithHeadersAndBasicAuth(t *testing.T) {
 	}))
 	defer server.Close()
 
-	r := New(nil, config.Config{}, log.New(io.Discard, "", 0))
-	statusCode, body, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	statusCode, body, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
 		Target:       server.URL,
 		Timeout:      2,
 		HTTPMethod:   monitor.HTTPMethodGet,
@@ -113,6 +365,148 @@ func TestPerformHTTPRequestGETWithHeadersAndBasicAuth(t *testing.T) {
 	}
 }
 
+func TestPerformHTTPRequestSendsAuthorization(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = io.WriteString(writer, request.Header.Get("Authorization"))
+	}))
+	t.Cleanup(server.Close)
+
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	testCases := []struct {
+		name       string
+		monitoring monitor.Monitoring
+		expected   string
+	}{
+		{name: "basic by default", monitoring: monitor.Monitoring{AuthUsername: "user", AuthPassword: "pass"}, expected: basic},
+		{name: "explicit basic ignores token", monitoring: monitor.Monitoring{AuthType: monitor.AuthTypeBasic, AuthUsername: "user", AuthPassword: "pass", AuthToken: "secret-token"}, expected: basic},
+		{name: "bearer", monitoring: monitor.Monitoring{AuthType: monitor.AuthTypeBearer, AuthToken: "secret-token"}, expected: "Bearer secret-token"},
+		{name: "bearer ignores basic credentials", monitoring: monitor.Monitoring{AuthType: monitor.AuthTypeBearer, AuthUsername: "user", AuthPassword: "pass", AuthToken: "secret-token"}, expected: "Bearer secret-token"},
+		{name: "bearer replaces header", monitoring: monitor.Monitoring{AuthType: monitor.AuthTypeBearer, AuthToken: "secret-token", HTTPHeaders: `{"Authorization":"Token other"}`}, expected: "Bearer secret-token"},
+		{name: "bearer without token", monitoring: monitor.Monitoring{AuthType: monitor.AuthTypeBearer, AuthUsername: "user", AuthPassword: "pass"}, expected: ""},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			monitoring := testCase.monitoring
+			monitoring.Target = server.URL
+			monitoring.Timeout = 2
+			monitoring.HTTPMethod = monitor.HTTPMethodGet
+
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			_, body, _, err := r.performHTTPRequest(context.Background(), monitoring)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if body != testCase.expected {
+				t.Fatalf("expected Authorization %q, got %q", testCase.expected, body)
+			}
+		})
+	}
+}
+
+func TestPerformHTTPRequestUsesURLCredentials(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = io.WriteString(writer, request.Header.Get("Authorization"))
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse server url: %v", err)
+	}
+	serverURL.User = url.UserPassword("url-user", "url-pass")
+	serverURL.Path = "/status"
+	target := serverURL.String()
+
+	basic := func(username, password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	}
+	testCases := []struct {
+		name       string
+		monitoring monitor.Monitoring
+		expected   string
+	}{
+		{name: "url credentials", expected: basic("url-user", "url-pass")},
+		{name: "auth fields win", monitoring: monitor.Monitoring{AuthUsername: "user", AuthPassword: "pass"}, expected: basic("user", "pass")},
+		{name: "header wins", monitoring: monitor.Monitoring{HTTPHeaders: `{"Authorization":"Token other"}`}, expected: "Token other"},
+		{name: "bearer wins", monitoring: monitor.Monitoring{AuthType: monitor.AuthTypeBearer, AuthToken: "secret-token"}, expected: "Bearer secret-token"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			monitoring := testCase.monitoring
+			monitoring.Target = target
+			monitoring.Timeout = 2
+			monitoring.HTTPMethod = monitor.HTTPMethodGet
+
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			_, body, timings, err := r.performHTTPRequest(context.Background(), monitoring)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if body != testCase.expected {
+				t.Fatalf("expected Authorization %q, got %q", testCase.expected, body)
+			}
+			if expected := server.URL + "/status"; pointerStringValue(timings.FinalURL) != expected {
+				t.Fatalf("expected final url %q without credentials, got %q", expected, pointerStringValue(timings.FinalURL))
+			}
+		})
+	}
+}
+
+func TestPerformHTTPRequestUserAgent(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		configured string
+		headers    any
+		expected   string
+	}{
+		{name: "configured default", configured: "WebGuard-Instance/v1.2.3", expected: "WebGuard-Instance/v1.2.3"},
+		{name: "monitoring header wins", configured: "WebGuard-Instance/v1.2.3", headers: `{"user-agent":"CustomAgent/2.0"}`, expected: "CustomAgent/2.0"},
+		{name: "unset keeps go default", expected: "Go-http-client/1.1"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			userAgents := make(chan string, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				userAgents <- request.UserAgent()
+				writer.WriteHeader(http.StatusOK)
+			}))
+			t.Cleanup(server.Close)
+
+			r := New(nil, config.Config{HTTPUserAgent: testCase.configured}, slog.New(slog.DiscardHandler), nil)
+			if _, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+				Target:      server.URL,
+				Timeout:     2,
+				HTTPMethod:  monitor.HTTPMethodGet,
+				HTTPHeaders: testCase.headers,
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := <-userAgents; got != testCase.expected {
+				t.Fatalf("expected user agent %q, got %q", testCase.expected, got)
+			}
+		})
+	}
+}
+
 func TestPerformHTTPRequestPOSTBody(t *testing.T) {
 	t.Parallel()
 
@@ -138,8 +532,8 @@ func TestPerformHTTPRequestPOSTBody(t *testing.T) {
 	}))
 	defer server.Close()
 
-	r := New(nil, config.Config{}, log.New(io.Discard, "", 0))
-	statusCode, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	statusCode, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
 		Target:     server.URL,
 		Timeout:    2,
 		HTTPMethod: monitor.HTTPMethodPost,
@@ -153,332 +547,2214 @@ func TestPerformHTTPRequestPOSTBody(t *testing.T) {
 	}
 }
 
-func TestPerformHTTPRequestFollowsRedirectAcrossHosts(t *testing.T) {
+func TestHandleHTTPMonitoringTLSVerification(t *testing.T) {
 	t.Parallel()
 
-	targetServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		writer.WriteHeader(http.StatusOK)
-		_, _ = writer.Write([]byte("redirect-ok"))
 	}))
-	defer targetServer.Close()
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	defer server.Close()
 
-	redirectServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-		http.Redirect(writer, request, targetServer.URL, http.StatusMovedPermanently)
-	}))
-	defer redirectServer.Close()
+	testCases := []struct {
+		name           string
+		verifyTLS      bool
+		expectedStatus monitor.Status
+	}{
+		{name: "verification disabled", verifyTLS: false, expectedStatus: monitor.StatusUp},
+		{name: "verification enabled", verifyTLS: true, expectedStatus: monitor.StatusDown},
+	}
 
-	r := New(nil, config.Config{}, log.New(io.Discard, "", 0))
-	statusCode, body, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
-		Target:     redirectServer.URL,
-		Timeout:    2,
-		HTTPMethod: monitor.HTTPMethodGet,
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			r := New(nil, config.Config{VerifyTLS: testCase.verifyTLS}, slog.New(slog.DiscardHandler), nil)
+			status, _, httpStatusCode, _ := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
+				Target:     server.URL,
+				Timeout:    2,
+				HTTPMethod: monitor.HTTPMethodGet,
+			})
+
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected %s, got %s", testCase.expectedStatus, status)
+			}
+			if testCase.verifyTLS && httpStatusCode != nil {
+				t.Fatalf("expected nil http status code on TLS failure, got %d", *httpStatusCode)
+			}
+		})
+	}
+}
+
+func TestCrawlMonitoringSSLInspectsSelfSignedCertificateWhenVerifyTLSEnabled(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	r := New(nil, config.Config{VerifyTLS: true}, slog.New(slog.DiscardHandler), nil)
+	payload := r.crawlMonitoringSSL(context.Background(), monitor.Monitoring{
+		ID:     "ssl-self-signed",
+		Target: server.URL,
 	})
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+
+	if payload.SerialNumber == nil || len(payload.SANs) == 0 {
+		t.Fatalf("expected SSL monitoring to still inspect the certificate")
 	}
-	if statusCode != http.StatusOK {
-		t.Fatalf("expected final status 200 after redirect, got %d", statusCode)
+	if payload.IsValid {
+		t.Fatalf("expected self-signed certificate to be invalid")
 	}
-	if body != "redirect-ok" {
-		t.Fatalf("expected redirected response body, got %q", body)
+	if payload.FailureReason == nil || *payload.FailureReason != monitor.SSLFailureSelfSigned {
+		t.Fatalf("expected failure reason %q, got %v", monitor.SSLFailureSelfSigned, pointerStringValue(payload.FailureReason))
 	}
 }
 
-func TestHandleHTTPMonitoringTreatsRedirectStatusAsUp(t *testing.T) {
+func TestHandleHTTPMonitoringHeadRequest(t *testing.T) {
 	t.Parallel()
 
-	redirectOnlyServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-		writer.WriteHeader(http.StatusMovedPermanently)
+	methods := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		methods <- request.Method
+		if request.Method != http.MethodHead {
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		writer.WriteHeader(http.StatusNoContent)
 	}))
-	defer redirectOnlyServer.Close()
+	defer server.Close()
 
-	r := New(nil, config.Config{}, log.New(io.Discard, "", 0))
-	status, responseTime, httpStatusCode := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
-		Target:     redirectOnlyServer.URL,
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	status, responseTime, httpStatusCode, _ := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
+		Target:     server.URL,
 		Timeout:    2,
-		HTTPMethod: monitor.HTTPMethodGet,
+		HTTPMethod: monitor.HTTPMethodHead,
+		HTTPBody:   `{"ignored":true}`,
 	})
 
+	if method := <-methods; method != http.MethodHead {
+		t.Fatalf("expected HEAD, got %s", method)
+	}
 	if status != monitor.StatusUp {
-		t.Fatalf("expected up for redirect response, got %s", status)
+		t.Fatalf("expected up, got %s", status)
 	}
 	if responseTime == nil {
-		t.Fatalf("expected response time for redirect response")
-	}
-	if httpStatusCode == nil {
-		t.Fatalf("expected http status code")
+		t.Fatalf("expected response time")
 	}
-	if *httpStatusCode != http.StatusMovedPermanently {
-		t.Fatalf("expected http status code 301, got %d", *httpStatusCode)
+	if httpStatusCode == nil || *httpStatusCode != http.StatusNoContent {
+		t.Fatalf("expected http status code 204, got %v", pointerIntValue(httpStatusCode))
 	}
 }
 
-func TestHandleKeywordMonitoringReturnsHTTPStatusCodeWhenKeywordMissing(t *testing.T) {
+func TestHandleHTTPMonitoringHeadRequestErrorStatusIsDown(t *testing.T) {
 	t.Parallel()
 
 	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-		writer.WriteHeader(http.StatusTeapot)
-		_, _ = writer.Write([]byte("different-content"))
+		writer.WriteHeader(http.StatusServiceUnavailable)
 	}))
 	defer server.Close()
 
-	r := New(nil, config.Config{}, log.New(io.Discard, "", 0))
-	status, responseTime, httpStatusCode := r.handleKeywordMonitoring(context.Background(), monitor.Monitoring{
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	status, _, httpStatusCode, _ := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
 		Target:     server.URL,
 		Timeout:    2,
-		HTTPMethod: monitor.HTTPMethodGet,
-		Keyword:    "needle",
+		HTTPMethod: monitor.HTTPMethodHead,
 	})
 
 	if status != monitor.StatusDown {
-		t.Fatalf("expected down when keyword is missing, got %s", status)
-	}
-	if responseTime != nil {
-		t.Fatalf("expected nil response time when keyword is missing, got %v", *responseTime)
-	}
-	if httpStatusCode == nil {
-		t.Fatalf("expected http status code")
+		t.Fatalf("expected down, got %s", status)
 	}
-	if *httpStatusCode != http.StatusTeapot {
-		t.Fatalf("expected http status code %d, got %d", http.StatusTeapot, *httpStatusCode)
+	if httpStatusCode == nil || *httpStatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected http status code 503, got %v", pointerIntValue(httpStatusCode))
 	}
 }
 
-func TestPerformHTTPRequestRetriesOnTransportError(t *testing.T) {
+func TestPerformHTTPRequestOptions(t *testing.T) {
 	t.Parallel()
 
-	r := New(nil, config.Config{}, log.New(io.Discard, "", 0))
-	start := time.Now()
-	_, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
-		Target:     "http://127.0.0.1:1",
-		Timeout:    1,
-		HTTPMethod: monitor.HTTPMethodGet,
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodOptions {
+			t.Errorf("expected OPTIONS, got %s", request.Method)
+		}
+		if request.ContentLength > 0 {
+			t.Errorf("expected no request body for OPTIONS")
+		}
+		writer.Header().Set("Allow", "GET, OPTIONS")
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	statusCode, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+		Target:     server.URL,
+		Timeout:    2,
+		HTTPMethod: monitor.HTTPMethodOptions,
+		HTTPBody:   `{"ignored":true}`,
 	})
-	if err == nil {
-		t.Fatalf("expected transport error")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if time.Since(start) < 200*time.Millisecond {
-		t.Fatalf("expected retry delay to be applied")
+	if statusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", statusCode)
 	}
 }
 
-func TestHandlePingMonitoringSupportsHostnameAndIPTargets(t *testing.T) {
-	originalExecutor := pingExecutor
-	t.Cleanup(func() {
-		pingExecutor = originalExecutor
-	})
+func TestPerformHTTPRequestUsesProxy(t *testing.T) {
+	t.Parallel()
+
+	newProxy := func(t *testing.T, name string, proxied chan<- string) *httptest.Server {
+		proxy := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			proxied <- name + " " + request.URL.String()
+			writer.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(proxy.Close)
+		return proxy
+	}
 
 	testCases := []struct {
-		name   string
-		target string
+		name          string
+		configProxy   bool
+		monitorProxy  bool
+		expectedProxy string
 	}{
-		{name: "hostname", target: "example.com"},
-		{name: "ipv4", target: "8.8.8.8"},
-		{name: "ipv6", target: "2001:4860:4860::8888"},
+		{name: "config proxy", configProxy: true, expectedProxy: "config"},
+		{name: "monitoring override", configProxy: true, monitorProxy: true, expectedProxy: "monitoring"},
+		{name: "monitoring proxy only", monitorProxy: true, expectedProxy: "monitoring"},
 	}
 
 	for _, testCase := range testCases {
 		testCase := testCase
 		t.Run(testCase.name, func(t *testing.T) {
-			var receivedHost string
-			var receivedTimeout int
-			pingExecutor = func(_ context.Context, host string, timeoutSeconds int) ([]byte, error) {
-				receivedHost = host
-				receivedTimeout = timeoutSeconds
-				return []byte("64 bytes from " + host + ": icmp_seq=1 ttl=57 time=12.34 ms"), nil
-			}
+			t.Parallel()
 
-			status, responseTime := handlePingMonitoring(monitor.Monitoring{
-				Target:  testCase.target,
+			proxied := make(chan string, 2)
+			cfg := config.Config{}
+			if testCase.configProxy {
+				cfg.HTTPProxyURL = newProxy(t, "config", proxied).URL
+			}
+			monitoring := monitor.Monitoring{
+				Target:  "http://origin.example.test/health",
 				Timeout: 2,
-			})
-
-			if status != monitor.StatusUp {
-				t.Fatalf("expected up, got %s", status)
 			}
-			if responseTime == nil {
-				t.Fatalf("expected response time")
+			if testCase.monitorProxy {
+				monitoring.ProxyURL = newProxy(t, "monitoring", proxied).URL
 			}
-			if *responseTime != 12.34 {
-				t.Fatalf("expected response time 12.34, got %v", *responseTime)
+
+			r := New(nil, cfg, slog.New(slog.DiscardHandler), nil)
+			statusCode, _, _, err := r.performHTTPRequest(context.Background(), monitoring)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
 			}
-			if receivedHost != testCase.target {
-				t.Fatalf("expected ping target %q, got %q", testCase.target, receivedHost)
+			if statusCode != http.StatusNoContent {
+				t.Fatalf("expected status 204 from proxy, got %d", statusCode)
 			}
-			if receivedTimeout != 2 {
-				t.Fatalf("expected timeout 2, got %d", receivedTimeout)
+
+			expected := testCase.expectedProxy + " http://origin.example.test/health"
+			select {
+			case got := <-proxied:
+				if got != expected {
+					t.Fatalf("expected %q, got %q", expected, got)
+				}
+			default:
+				t.Fatalf("expected request to go through the proxy")
 			}
 		})
 	}
 }
 
-func TestHandlePingMonitoringDown(t *testing.T) {
-	originalExecutor := pingExecutor
-	t.Cleanup(func() {
-		pingExecutor = originalExecutor
+func TestPerformHTTPRequestRejectsInvalidProxy(t *testing.T) {
+	t.Parallel()
+
+	r := New(nil, config.Config{HTTPProxyURL: "proxy.example.test"}, slog.New(slog.DiscardHandler), nil)
+	_, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+		Target:  "http://origin.example.test/health",
+		Timeout: 2,
 	})
+	if err == nil || !strings.Contains(err.Error(), "invalid proxy url") {
+		t.Fatalf("expected invalid proxy error, got %v", err)
+	}
+}
 
-	pingExecutor = func(_ context.Context, _ string, _ int) ([]byte, error) {
-		return []byte("100% packet loss"), errors.New("exit status 1")
+func TestPerformHTTPRequestFollowsRedirectAcrossHosts(t *testing.T) {
+	t.Parallel()
+
+	targetServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write([]byte("redirect-ok"))
+	}))
+	defer targetServer.Close()
+
+	redirectServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		http.Redirect(writer, request, targetServer.URL, http.StatusMovedPermanently)
+	}))
+	defer redirectServer.Close()
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	statusCode, body, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+		Target:     redirectServer.URL,
+		Timeout:    2,
+		HTTPMethod: monitor.HTTPMethodGet,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if statusCode != http.StatusOK {
+		t.Fatalf("expected final status 200 after redirect, got %d", statusCode)
+	}
+	if body != "redirect-ok" {
+		t.Fatalf("expected redirected response body, got %q", body)
 	}
+}
 
-	status, responseTime := handlePingMonitoring(monitor.Monitoring{
-		Target: "8.8.8.8",
+func newStreamingBodyServer(t *testing.T, totalBytes int, tail string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		chunk := bytes.Repeat([]byte("a"), 32*1024)
+		for written := 0; written < totalBytes; written += len(chunk) {
+			if _, err := writer.Write(chunk); err != nil {
+				return
+			}
+		}
+		_, _ = writer.Write([]byte(tail))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPerformHTTPRequestCookieJar(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(writer http.ResponseWriter, request *http.Request) {
+		http.SetCookie(writer, &http.Cookie{Name: "session", Value: "abc123", Path: "/"})
+		http.Redirect(writer, request, "/dashboard", http.StatusFound)
+	})
+	mux.HandleFunc("/dashboard", func(writer http.ResponseWriter, request *http.Request) {
+		cookie, err := request.Cookie("session")
+		if err != nil || cookie.Value != "abc123" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	testCases := []struct {
+		name           string
+		useCookieJar   bool
+		expectedStatus int
+	}{
+		{name: "with cookie jar", useCookieJar: true, expectedStatus: http.StatusOK},
+		{name: "without cookie jar", useCookieJar: false, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, testCase := range testCases {
+		r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+		statusCode, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+			Target:       server.URL + "/login",
+			Timeout:      2,
+			HTTPMethod:   monitor.HTTPMethodGet,
+			UseCookieJar: testCase.useCookieJar,
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", testCase.name, err)
+		}
+		if statusCode != testCase.expectedStatus {
+			t.Fatalf("%s: expected status %d, got %d", testCase.name, testCase.expectedStatus, statusCode)
+		}
+	}
+}
+
+func TestPerformHTTPRequestBoundsBodyRead(t *testing.T) {
+	t.Parallel()
+
+	server := newStreamingBodyServer(t, 64*1024*1024, "needle")
+
+	var logs bytes.Buffer
+	r := New(nil, config.Config{HTTPMaxBodyBytes: 1024}, slog.New(slog.NewTextHandler(&logs, nil)), nil)
+	statusCode, body, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+		ID:         "large-body",
+		Target:     server.URL,
+		Timeout:    5,
+		HTTPMethod: monitor.HTTPMethodGet,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if statusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", statusCode)
+	}
+	if len(body) != 1024 {
+		t.Fatalf("expected body capped at 1024 bytes, got %d", len(body))
+	}
+	if !strings.Contains(logs.String(), `msg="HTTP response body truncated" monitoring_id=large-body limit_bytes=1024`) {
+		t.Fatalf("expected truncation warning, got %q", logs.String())
+	}
+}
+
+func TestHandleKeywordMonitoringSearchesOnlyWithinBodyLimit(t *testing.T) {
+	t.Parallel()
+
+	server := newStreamingBodyServer(t, 4096, "needle")
+
+	r := New(nil, config.Config{HTTPMaxBodyBytes: 1024}, slog.New(slog.DiscardHandler), nil)
+	status, _, _, _ := r.handleKeywordMonitoring(context.Background(), monitor.Monitoring{
+		Target:     server.URL,
+		Timeout:    2,
+		HTTPMethod: monitor.HTTPMethodGet,
+		Keyword:    "needle",
 	})
 	if status != monitor.StatusDown {
-		t.Fatalf("expected down, got %s", status)
+		t.Fatalf("expected keyword beyond the body limit to be missed, got %s", status)
 	}
-	if responseTime == nil {
-		t.Fatalf("expected fallback response time")
+
+	r = New(nil, config.Config{HTTPMaxBodyBytes: 64 * 1024}, slog.New(slog.DiscardHandler), nil)
+	status, _, _, _ = r.handleKeywordMonitoring(context.Background(), monitor.Monitoring{
+		Target:     server.URL,
+		Timeout:    2,
+		HTTPMethod: monitor.HTTPMethodGet,
+		Keyword:    "needle",
+	})
+	if status != monitor.StatusUp {
+		t.Fatalf("expected keyword within the body limit to be found, got %s", status)
 	}
 }
 
-func TestBuildPingCommand(t *testing.T) {
+func newRedirectChainServer(t *testing.T, hops int) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		hop, _ := strconv.Atoi(request.URL.Query().Get("hop"))
+		if hop < hops {
+			http.Redirect(writer, request, "/?hop="+strconv.Itoa(hop+1), http.StatusMovedPermanently)
+			return
+		}
+		_, _ = writer.Write([]byte("final"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPerformHTTPRequestMaxRedirects(t *testing.T) {
 	t.Parallel()
 
 	testCases := []struct {
-		name     string
-		host     string
-		timeout  int
-		expected []string
+		name           string
+		hops           int
+		maxRedirects   *int
+		expectedStatus int
+		expectError    bool
 	}{
-		{
-			name:     "hostname",
-			host:     "example.com",
-			timeout:  5,
-			expected: []string{"-c", "1", "-W", "5", "example.com"},
-		},
-		{
-			name:     "ipv4",
-			host:     "8.8.8.8",
-			timeout:  3,
-			expected: []string{"-c", "1", "-W", "3", "-4", "8.8.8.8"},
-		},
-		{
-			name:     "ipv6",
-			host:     "2001:4860:4860::8888",
-			timeout:  4,
-			expected: []string{"-c", "1", "-W", "4", "-6", "2001:4860:4860::8888"},
-		},
+		{name: "zero does not follow", hops: 1, maxRedirects: intPointer(0), expectedStatus: http.StatusMovedPermanently},
+		{name: "default follows five", hops: 5, expectedStatus: http.StatusOK},
+		{name: "default stops after five", hops: 6, expectError: true},
+		{name: "negative uses default", hops: 7, maxRedirects: intPointer(-1), expectError: true},
+		{name: "custom higher limit", hops: 7, maxRedirects: intPointer(10), expectedStatus: http.StatusOK},
+		{name: "custom lower limit", hops: 3, maxRedirects: intPointer(2), expectError: true},
 	}
 
 	for _, testCase := range testCases {
 		testCase := testCase
 		t.Run(testCase.name, func(t *testing.T) {
-			command, args := buildPingCommand(testCase.host, testCase.timeout)
-			if command != "ping" {
-				t.Fatalf("expected ping command, got %q", command)
+			t.Parallel()
+
+			server := newRedirectChainServer(t, testCase.hops)
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			statusCode, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+				Target:       server.URL,
+				Timeout:      2,
+				HTTPMethod:   monitor.HTTPMethodGet,
+				MaxRedirects: testCase.maxRedirects,
+			})
+
+			if testCase.expectError {
+				if err == nil {
+					t.Fatalf("expected redirect limit error, got status %d", statusCode)
+				}
+				return
 			}
-			if !reflect.DeepEqual(args, testCase.expected) {
-				t.Fatalf("unexpected ping args: got %#v want %#v", args, testCase.expected)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if statusCode != testCase.expectedStatus {
+				t.Fatalf("expected status %d, got %d", testCase.expectedStatus, statusCode)
 			}
 		})
 	}
 }
 
-func TestHandlePortMonitoringDown(t *testing.T) {
+func TestHandleHTTPMonitoringTreatsRedirectStatusAsUp(t *testing.T) {
 	t.Parallel()
 
-	status, responseTime := handlePortMonitoring(monitor.Monitoring{
-		Target: "127.0.0.1",
-		Port:   1,
+	redirectOnlyServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusMovedPermanently)
+	}))
+	defer redirectOnlyServer.Close()
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	status, responseTime, httpStatusCode, _ := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
+		Target:     redirectOnlyServer.URL,
+		Timeout:    2,
+		HTTPMethod: monitor.HTTPMethodGet,
 	})
-	if status != monitor.StatusDown {
-		t.Fatalf("expected down, got %s", status)
+
+	if status != monitor.StatusUp {
+		t.Fatalf("expected up for redirect response, got %s", status)
 	}
-	if responseTime != nil {
-		t.Fatalf("expected nil response time for failed port monitoring")
+	if responseTime == nil {
+		t.Fatalf("expected response time for redirect response")
+	}
+	if httpStatusCode == nil {
+		t.Fatalf("expected http status code")
+	}
+	if *httpStatusCode != http.StatusMovedPermanently {
+		t.Fatalf("expected http status code 301, got %d", *httpStatusCode)
 	}
 }
 
-func TestCrawlResponseMonitoringUnknownType(t *testing.T) {
+func TestHandleHTTPMonitoringExpectedStatusCodes(t *testing.T) {
 	t.Parallel()
 
-	r := New(nil, config.Config{}, log.New(io.Discard, "", 0))
-	status, responseTime, httpStatusCode := r.crawlResponseMonitoring(context.Background(), monitor.Monitoring{
-		Type: monitor.Type("custom"),
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name           string
+		expected       monitor.StatusCodeRanges
+		expectedStatus monitor.Status
+	}{
+		{name: "default treats 401 as down", expected: nil, expectedStatus: monitor.StatusDown},
+		{name: "explicit 401 is up", expected: monitor.StatusCodeRanges{{Min: 401, Max: 403}}, expectedStatus: monitor.StatusUp},
+		{name: "explicit 2xx is down", expected: monitor.StatusCodeRanges{{Min: 200, Max: 299}}, expectedStatus: monitor.StatusDown},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			status, responseTime, httpStatusCode, _ := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
+				Target:              server.URL,
+				Timeout:             2,
+				HTTPMethod:          monitor.HTTPMethodGet,
+				ExpectedStatusCodes: testCase.expected,
+			})
+
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected %s, got %s", testCase.expectedStatus, status)
+			}
+			if status == monitor.StatusUp && responseTime == nil {
+				t.Fatalf("expected response time when up")
+			}
+			if httpStatusCode == nil || *httpStatusCode != http.StatusUnauthorized {
+				t.Fatalf("expected http status code 401, got %v", pointerIntValue(httpStatusCode))
+			}
+		})
+	}
+}
+
+func TestHandleKeywordMonitoringReturnsHTTPStatusCodeWhenKeywordMissing(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusTeapot)
+		_, _ = writer.Write([]byte("different-content"))
+	}))
+	defer server.Close()
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	status, responseTime, httpStatusCode, _ := r.handleKeywordMonitoring(context.Background(), monitor.Monitoring{
+		Target:     server.URL,
+		Timeout:    2,
+		HTTPMethod: monitor.HTTPMethodGet,
+		Keyword:    "needle",
 	})
-	if status != monitor.StatusUnknown {
-		t.Fatalf("expected unknown status, got %s", status)
+
+	if status != monitor.StatusDown {
+		t.Fatalf("expected down when keyword is missing, got %s", status)
 	}
 	if responseTime != nil {
-		t.Fatalf("expected nil response time for unknown type")
+		t.Fatalf("expected nil response time when keyword is missing, got %v", *responseTime)
 	}
-	if httpStatusCode != nil {
-		t.Fatalf("expected nil http status code for unknown type")
+	if httpStatusCode == nil {
+		t.Fatalf("expected http status code")
+	}
+	if *httpStatusCode != http.StatusTeapot {
+		t.Fatalf("expected http status code %d, got %d", http.StatusTeapot, *httpStatusCode)
 	}
 }
 
-func TestCrawlResponseMonitoringPortReturnsNilHTTPStatusCode(t *testing.T) {
+func TestHandleKeywordMonitoringSendsRequestBody(t *testing.T) {
 	t.Parallel()
 
-	server, err := net.Listen("tcp", "127.0.0.1:0")
-	if err != nil {
-		t.Fatalf("failed to open listener: %v", err)
-	}
-	defer server.Close()
+	for _, method := range []monitor.HTTPMethod{monitor.HTTPMethodPost, monitor.HTTPMethodPut} {
+		method := method
+		t.Run(string(method), func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				if !strings.EqualFold(request.Method, string(method)) {
+					t.Errorf("expected %s, got %s", strings.ToUpper(string(method)), request.Method)
+				}
+				if contentType := request.Header.Get("Content-Type"); contentType != "application/json" {
+					t.Errorf("expected application/json, got %q", contentType)
+				}
+				body, _ := io.ReadAll(request.Body)
+				_, _ = writer.Write([]byte("echo: " + string(body)))
+			}))
+			t.Cleanup(server.Close)
+
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			status, responseTime, _, _ := r.handleKeywordMonitoring(context.Background(), monitor.Monitoring{
+				Target:     server.URL,
+				Timeout:    2,
+				HTTPMethod: method,
+				HTTPBody:   map[string]any{"query": "needle"},
+				Keyword:    `"query":"needle"`,
+			})
 
-	_, portRaw, err := net.SplitHostPort(server.Addr().String())
-	if err != nil {
-		t.Fatalf("failed to split listener address: %v", err)
-	}
+			if status != monitor.StatusUp {
+				t.Fatalf("expected keyword to match the echoed body, got %s", status)
+			}
+			if responseTime == nil {
+				t.Fatalf("expected response time")
+			}
+		})
+	}
+}
+
+func TestHandleKeywordMonitoringDecompressesBody(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		encoding string
+		compress func(writer io.Writer) io.WriteCloser
+	}{
+		{encoding: "gzip", compress: func(writer io.Writer) io.WriteCloser { return gzip.NewWriter(writer) }},
+		{encoding: "deflate", compress: func(writer io.Writer) io.WriteCloser { return zlib.NewWriter(writer) }},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.encoding, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Set("Content-Encoding", testCase.encoding)
+				compressor := testCase.compress(writer)
+				_, _ = compressor.Write([]byte("<html>status: all-systems-operational</html>"))
+				_ = compressor.Close()
+			}))
+			t.Cleanup(server.Close)
+
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			status, _, _, _ := r.handleKeywordMonitoring(context.Background(), monitor.Monitoring{
+				Target:      server.URL,
+				Timeout:     2,
+				HTTPMethod:  monitor.HTTPMethodGet,
+				HTTPHeaders: `{"Accept-Encoding":"gzip, deflate"}`,
+				Keyword:     "all-systems-operational",
+			})
+
+			if status != monitor.StatusUp {
+				t.Fatalf("expected keyword to match the decompressed body, got %s", status)
+			}
+		})
+	}
+}
+
+func TestHandleKeywordMonitoringModes(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("status: ok, build 2026.10.3"))
+	}))
+	t.Cleanup(server.Close)
+
+	testCases := []struct {
+		name            string
+		keyword         string
+		mode            monitor.KeywordMode
+		caseInsensitive bool
+		expectedStatus  monitor.Status
+		expectWarning   bool
+	}{
+		{name: "contains by default", keyword: "status: ok", expectedStatus: monitor.StatusUp},
+		{name: "contains missing keyword", keyword: "fatal error", mode: monitor.KeywordModeContains, expectedStatus: monitor.StatusDown},
+		{name: "absent keyword missing", keyword: "fatal error", mode: monitor.KeywordModeAbsent, expectedStatus: monitor.StatusUp},
+		{name: "absent keyword present", keyword: "status: ok", mode: monitor.KeywordModeAbsent, expectedStatus: monitor.StatusDown},
+		{name: "regex matches", keyword: `build \d{4}\.\d+\.\d+`, mode: monitor.KeywordModeRegex, expectedStatus: monitor.StatusUp},
+		{name: "regex does not match", keyword: `^build`, mode: monitor.KeywordModeRegex, expectedStatus: monitor.StatusDown},
+		{name: "invalid regex", keyword: `status: (ok`, mode: monitor.KeywordModeRegex, expectedStatus: monitor.StatusDown, expectWarning: true},
+		{name: "mixed case contains is case-sensitive by default", keyword: "Status: OK", expectedStatus: monitor.StatusDown},
+		{name: "mixed case contains with case-insensitive matching", keyword: "Status: OK", caseInsensitive: true, expectedStatus: monitor.StatusUp},
+		{name: "mixed case absent is case-sensitive by default", keyword: "STATUS: OK", mode: monitor.KeywordModeAbsent, expectedStatus: monitor.StatusUp},
+		{name: "mixed case absent with case-insensitive matching", keyword: "STATUS: OK", mode: monitor.KeywordModeAbsent, caseInsensitive: true, expectedStatus: monitor.StatusDown},
+		{name: "mixed case regex is case-sensitive by default", keyword: `^STATUS`, mode: monitor.KeywordModeRegex, expectedStatus: monitor.StatusDown},
+		{name: "mixed case regex with case-insensitive matching", keyword: `^STATUS`, mode: monitor.KeywordModeRegex, caseInsensitive: true, expectedStatus: monitor.StatusUp},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var logs bytes.Buffer
+			r := New(nil, config.Config{}, slog.New(slog.NewTextHandler(&logs, nil)), nil)
+			status, responseTime, httpStatusCode, _ := r.handleKeywordMonitoring(context.Background(), monitor.Monitoring{
+				ID:                     "keyword-mode",
+				Target:                 server.URL,
+				Timeout:                2,
+				HTTPMethod:             monitor.HTTPMethodGet,
+				Keyword:                testCase.keyword,
+				KeywordMode:            testCase.mode,
+				KeywordCaseInsensitive: testCase.caseInsensitive,
+			})
+
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected %s, got %s", testCase.expectedStatus, status)
+			}
+			if (responseTime != nil) != (testCase.expectedStatus == monitor.StatusUp) {
+				t.Fatalf("expected response time only when up, got %v", pointerFloat64Value(responseTime))
+			}
+			if httpStatusCode == nil || *httpStatusCode != http.StatusOK {
+				t.Fatalf("expected http status code 200, got %v", pointerIntValue(httpStatusCode))
+			}
+			if warned := strings.Contains(logs.String(), "Invalid keyword pattern"); warned != testCase.expectWarning {
+				t.Fatalf("expected warning logged=%v, got logs %q", testCase.expectWarning, logs.String())
+			}
+		})
+	}
+}
+
+func TestPerformHTTPRequestRetriesOnTransportError(t *testing.T) {
+	t.Parallel()
+
+	r := New(nil, config.Config{
+		HTTPRetryTimes:       1,
+		HTTPRetryBaseDelayMS: 250,
+	}, slog.New(slog.DiscardHandler), nil)
+	start := time.Now()
+	_, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+		Target:     "http://127.0.0.1:1",
+		Timeout:    1,
+		HTTPMethod: monitor.HTTPMethodGet,
+	})
+	if err == nil {
+		t.Fatalf("expected transport error")
+	}
+	if time.Since(start) < 200*time.Millisecond {
+		t.Fatalf("expected retry delay to be applied")
+	}
+}
+
+func newConnectionDroppingServer(t *testing.T, attempts *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts.Add(1)
+		hijacker, ok := writer.(http.Hijacker)
+		if !ok {
+			t.Errorf("expected hijackable response writer")
+			return
+		}
+		conn, _, err := hijacker.Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPerformHTTPRequestRetryAttempts(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		retryTimes       int
+		expectedAttempts int32
+	}{
+		{name: "no retries", retryTimes: 0, expectedAttempts: 1},
+		{name: "three retries", retryTimes: 3, expectedAttempts: 4},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var attempts atomic.Int32
+			server := newConnectionDroppingServer(t, &attempts)
+
+			r := New(nil, config.Config{
+				HTTPRetryTimes:       testCase.retryTimes,
+				HTTPRetryBaseDelayMS: 1,
+			}, slog.New(slog.DiscardHandler), nil)
+			_, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+				Target:     server.URL,
+				Timeout:    2,
+				HTTPMethod: monitor.HTTPMethodGet,
+			})
+			if err == nil {
+				t.Fatalf("expected transport error")
+			}
+			if got := attempts.Load(); got != testCase.expectedAttempts {
+				t.Fatalf("expected %d attempts, got %d", testCase.expectedAttempts, got)
+			}
+		})
+	}
+}
+
+func TestPerformHTTPRequestRetryStopsOnContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := newConnectionDroppingServer(t, &attempts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	r := New(nil, config.Config{
+		HTTPRetryTimes:       3,
+		HTTPRetryBaseDelayMS: 10000,
+	}, slog.New(slog.DiscardHandler), nil)
+	start := time.Now()
+	_, _, _, err := r.performHTTPRequest(ctx, monitor.Monitoring{
+		Target:     server.URL,
+		HTTPMethod: monitor.HTTPMethodGet,
+	})
+	if err == nil {
+		t.Fatalf("expected transport error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected retry wait to stop on context cancellation, took %s", elapsed)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("expected 1 attempt before cancellation, got %d", got)
+	}
+}
+
+func TestPerformHTTPRequestTimeoutBoundsAllAttempts(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts.Add(1)
+		select {
+		case <-request.Context().Done():
+			return
+		case <-time.After(800 * time.Millisecond):
+		}
+		hijacker, ok := writer.(http.Hijacker)
+		if !ok {
+			t.Errorf("expected hijackable response writer")
+			return
+		}
+		conn, _, err := hijacker.Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	r := New(nil, config.Config{
+		HTTPRetryTimes:       3,
+		HTTPRetryBaseDelayMS: 1,
+	}, slog.New(slog.DiscardHandler), nil)
+	start := time.Now()
+	_, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+		Target:     server.URL,
+		Timeout:    1,
+		HTTPMethod: monitor.HTTPMethodGet,
+	})
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatalf("expected error once the deadline elapsed")
+	}
+	if elapsed > 1250*time.Millisecond {
+		t.Fatalf("expected check to finish within the 1s timeout, took %s", elapsed)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Fatalf("expected second attempt to be cut short by the deadline, got %d attempts", got)
+	}
+}
+
+func TestPerformHTTPRequestRetryRespectsMonitoringTimeout(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := newConnectionDroppingServer(t, &attempts)
+
+	r := New(nil, config.Config{
+		HTTPRetryTimes:       3,
+		HTTPRetryBaseDelayMS: 5000,
+	}, slog.New(slog.DiscardHandler), nil)
+	start := time.Now()
+	_, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+		Target:     server.URL,
+		Timeout:    1,
+		HTTPMethod: monitor.HTTPMethodGet,
+	})
+	if err == nil {
+		t.Fatalf("expected transport error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected retries to stop within the monitoring timeout, took %s", elapsed)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("expected no retry beyond the monitoring timeout, got %d attempts", got)
+	}
+}
+
+func TestPerformHTTPRequestHonorsRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		statusCode int
+		retryAfter func() string
+		minWait    time.Duration
+	}{
+		{
+			name:       "seconds on 429",
+			statusCode: http.StatusTooManyRequests,
+			retryAfter: func() string { return "1" },
+			minWait:    time.Second,
+		},
+		{
+			name:       "http date on 503",
+			statusCode: http.StatusServiceUnavailable,
+			retryAfter: func() string { return time.Now().Add(2 * time.Second).UTC().Format(http.TimeFormat) },
+			minWait:    time.Second,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				if attempts.Add(1) == 1 {
+					writer.Header().Set("Retry-After", testCase.retryAfter())
+					writer.WriteHeader(testCase.statusCode)
+					return
+				}
+				_, _ = writer.Write([]byte("ok"))
+			}))
+			t.Cleanup(server.Close)
+
+			r := New(nil, config.Config{HTTPRetryTimes: 1}, slog.New(slog.DiscardHandler), nil)
+			start := time.Now()
+			statusCode, body, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+				Target:     server.URL,
+				Timeout:    5,
+				HTTPMethod: monitor.HTTPMethodGet,
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if statusCode != http.StatusOK || body != "ok" {
+				t.Fatalf("expected retried 200 ok, got %d %q", statusCode, body)
+			}
+			if elapsed := time.Since(start); elapsed < testCase.minWait {
+				t.Fatalf("expected to wait at least %s before retrying, waited %s", testCase.minWait, elapsed)
+			}
+			if got := attempts.Load(); got != 2 {
+				t.Fatalf("expected 2 attempts, got %d", got)
+			}
+		})
+	}
+}
+
+func TestPerformHTTPRequestRetryAfterBeyondTimeoutReturnsResponse(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+		writer.Header().Set("Retry-After", "120")
+		writer.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	r := New(nil, config.Config{HTTPRetryTimes: 2}, slog.New(slog.DiscardHandler), nil)
+	start := time.Now()
+	statusCode, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+		Target:     server.URL,
+		Timeout:    2,
+		HTTPMethod: monitor.HTTPMethodGet,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if statusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", statusCode)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected no wait beyond the monitoring timeout, took %s", elapsed)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("expected 1 attempt, got %d", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 4, 24, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name          string
+		value         string
+		expectedDelay time.Duration
+		expectedOK    bool
+	}{
+		{name: "seconds", value: "3", expectedDelay: 3 * time.Second, expectedOK: true},
+		{name: "zero seconds", value: "0", expectedDelay: 0, expectedOK: true},
+		{name: "http date", value: "Fri, 24 Apr 2026 12:00:10 GMT", expectedDelay: 10 * time.Second, expectedOK: true},
+		{name: "http date in the past", value: "Fri, 24 Apr 2026 11:59:00 GMT", expectedDelay: 0, expectedOK: true},
+		{name: "empty", value: "", expectedOK: false},
+		{name: "negative", value: "-5", expectedOK: false},
+		{name: "garbage", value: "soon", expectedOK: false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			delay, ok := parseRetryAfter(testCase.value, now)
+			if ok != testCase.expectedOK {
+				t.Fatalf("expected ok=%v, got %v", testCase.expectedOK, ok)
+			}
+			if delay != testCase.expectedDelay {
+				t.Fatalf("expected %s, got %s", testCase.expectedDelay, delay)
+			}
+		})
+	}
+}
+
+func TestRetryDelayGrowsExponentially(t *testing.T) {
+	originalJitter := retryJitter
+	t.Cleanup(func() {
+		retryJitter = originalJitter
+	})
+
+	retryJitter = func() float64 { return 0 }
+	base := 100 * time.Millisecond
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond}
+	for attempt, want := range expected {
+		if got := retryDelay(base, attempt); got != want {
+			t.Fatalf("attempt %d: expected %s, got %s", attempt, want, got)
+		}
+	}
+
+	retryJitter = func() float64 { return 1 }
+	if got := retryDelay(base, 1); got != 300*time.Millisecond {
+		t.Fatalf("expected jitter to add up to half the delay, got %s", got)
+	}
+
+	if got := retryDelay(0, 3); got != 0 {
+		t.Fatalf("expected zero delay without base delay, got %s", got)
+	}
+}
+
+func TestHandlePingMonitoringSupportsHostnameAndIPTargets(t *testing.T) {
+	originalExecutor := pingExecutor
+	t.Cleanup(func() {
+		pingExecutor = originalExecutor
+	})
+
+	testCases := []struct {
+		name   string
+		target string
+	}{
+		{name: "hostname", target: "example.com"},
+		{name: "ipv4", target: "8.8.8.8"},
+		{name: "ipv6", target: "2001:4860:4860::8888"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			var receivedHost string
+			var receivedTimeout int
+			pingExecutor = func(_ context.Context, host string, timeoutSeconds int) ([]byte, error) {
+				receivedHost = host
+				receivedTimeout = timeoutSeconds
+				return []byte("64 bytes from " + host + ": icmp_seq=1 ttl=57 time=12.34 ms"), nil
+			}
+
+			status, responseTime, _ := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil).handlePingMonitoring(context.Background(), monitor.Monitoring{
+				Target:  testCase.target,
+				Timeout: 2,
+			})
+
+			if status != monitor.StatusUp {
+				t.Fatalf("expected up, got %s", status)
+			}
+			if responseTime == nil {
+				t.Fatalf("expected response time")
+			}
+			if *responseTime != 12.34 {
+				t.Fatalf("expected response time 12.34, got %v", *responseTime)
+			}
+			if receivedHost != testCase.target {
+				t.Fatalf("expected ping target %q, got %q", testCase.target, receivedHost)
+			}
+			if receivedTimeout != 2 {
+				t.Fatalf("expected timeout 2, got %d", receivedTimeout)
+			}
+		})
+	}
+}
+
+func TestHandlePingMonitoringDown(t *testing.T) {
+	originalExecutor := pingExecutor
+	t.Cleanup(func() {
+		pingExecutor = originalExecutor
+	})
+
+	pingExecutor = func(_ context.Context, _ string, _ int) ([]byte, error) {
+		return []byte("100% packet loss"), errors.New("exit status 1")
+	}
+
+	status, responseTime, _ := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil).handlePingMonitoring(context.Background(), monitor.Monitoring{
+		Target: "8.8.8.8",
+	})
+	if status != monitor.StatusDown {
+		t.Fatalf("expected down, got %s", status)
+	}
+	if responseTime == nil {
+		t.Fatalf("expected fallback response time")
+	}
+}
+
+func TestBuildPingCommand(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		host     string
+		timeout  int
+		expected []string
+	}{
+		{
+			name:     "hostname",
+			host:     "example.com",
+			timeout:  5,
+			expected: []string{"-c", "1", "-W", "5", "example.com"},
+		},
+		{
+			name:     "ipv4",
+			host:     "8.8.8.8",
+			timeout:  3,
+			expected: []string{"-c", "1", "-W", "3", "-4", "8.8.8.8"},
+		},
+		{
+			name:     "ipv6",
+			host:     "2001:4860:4860::8888",
+			timeout:  4,
+			expected: []string{"-c", "1", "-W", "4", "-6", "2001:4860:4860::8888"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			command, args := buildPingCommand(testCase.host, testCase.timeout)
+			if command != "ping" {
+				t.Fatalf("expected ping command, got %q", command)
+			}
+			if !reflect.DeepEqual(args, testCase.expected) {
+				t.Fatalf("unexpected ping args: got %#v want %#v", args, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestHandlePortMonitoringRefused(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to open listener: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+
+	status, responseTime, details := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil).handlePortMonitoring(context.Background(), monitor.Monitoring{
+		Target: "127.0.0.1",
+		Port:   port,
+	})
+	if status != monitor.StatusDown {
+		t.Fatalf("expected down, got %s", status)
+	}
+	if responseTime == nil {
+		t.Fatalf("expected handshake latency for refused port")
+	}
+	if details.FailureReason == nil || *details.FailureReason != monitor.PortFailureConnectionRefused {
+		t.Fatalf("expected %s, got %v", monitor.PortFailureConnectionRefused, pointerStringValue(details.FailureReason))
+	}
+}
+
+func TestHandlePortMonitoringRecordsResolvedIP(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to open listener: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	status, _, details := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil).handlePortMonitoring(context.Background(), monitor.Monitoring{
+		Target: "localhost",
+		Port:   listener.Addr().(*net.TCPAddr).Port,
+	})
+	if status != monitor.StatusUp {
+		t.Fatalf("expected up, got %s", status)
+	}
+	if details.ResolvedIP == nil || *details.ResolvedIP != "127.0.0.1" {
+		t.Fatalf("expected resolved ip 127.0.0.1, got %v", pointerStringValue(details.ResolvedIP))
+	}
+}
+
+func TestHandlePortMonitoringProbeModeControlsTeardown(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		mode          monitor.PortProbeMode
+		expectedReset bool
+	}{
+		{name: "default closes gracefully", expectedReset: false},
+		{name: "connect closes gracefully", mode: monitor.PortProbeModeConnect, expectedReset: false},
+		{name: "reset aborts with rst", mode: monitor.PortProbeModeReset, expectedReset: true},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("failed to open listener: %v", err)
+			}
+			t.Cleanup(func() {
+				_ = listener.Close()
+			})
+
+			status, _, _ := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil).handlePortMonitoring(context.Background(), monitor.Monitoring{
+				Target:        "127.0.0.1",
+				Port:          listener.Addr().(*net.TCPAddr).Port,
+				Timeout:       2,
+				PortProbeMode: testCase.mode,
+			})
+			if status != monitor.StatusUp {
+				t.Fatalf("expected up, got %s", status)
+			}
+
+			// The check has already closed its side; the accepted connection
+			// sees how it was torn down.
+			conn, err := listener.Accept()
+			if err != nil {
+				t.Fatalf("expected the check to have established a connection: %v", err)
+			}
+			defer conn.Close()
+			_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			_, err = conn.Read(make([]byte, 1))
+
+			if testCase.expectedReset {
+				if !errors.Is(err, syscall.ECONNRESET) {
+					t.Fatalf("expected connection reset, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, io.EOF) {
+				t.Fatalf("expected graceful close, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParsePingAddress(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{name: "linux header", output: "PING example.com (93.184.216.34) 56(84) bytes of data.\n64 bytes from 93.184.216.34: icmp_seq=1 ttl=57 time=12.34 ms", expected: "93.184.216.34"},
+		{name: "reply only", output: "64 bytes from 8.8.8.8: icmp_seq=1 ttl=57 time=12.34 ms", expected: "8.8.8.8"},
+		{name: "ipv6 header", output: "PING 2001:4860:4860::8888(2001:4860:4860::8888) 56 data bytes", expected: "2001:4860:4860::8888"},
+		{name: "no address", output: "100% packet loss", expected: ""},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := parsePingAddress([]byte(testCase.output))
+			if testCase.expected == "" {
+				if got != nil {
+					t.Fatalf("expected no address, got %q", *got)
+				}
+				return
+			}
+			if got == nil || *got != testCase.expected {
+				t.Fatalf("expected %q, got %v", testCase.expected, pointerStringValue(got))
+			}
+		})
+	}
+}
+
+func TestHandlePortMonitoringHonorsTimeout(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+	// 192.0.2.0/24 (TEST-NET-1) is reserved and never routed, so the dial
+	// can only end through the timeout or an immediate network error.
+	status, responseTime, _ := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil).handlePortMonitoring(context.Background(), monitor.Monitoring{
+		Target:  "192.0.2.1",
+		Port:    81,
+		Timeout: 1,
+	})
+	elapsed := time.Since(start)
+
+	if status != monitor.StatusDown {
+		t.Fatalf("expected down, got %s", status)
+	}
+	if responseTime == nil {
+		t.Fatalf("expected dial latency for unreachable host")
+	}
+	if elapsed > 1500*time.Millisecond {
+		t.Fatalf("expected dial to give up after the 1s timeout, took %s", elapsed)
+	}
+}
+
+func TestPortFailureReason(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "refused", err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, expected: monitor.PortFailureConnectionRefused},
+		{name: "timeout", err: &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}, expected: monitor.PortFailureTimeout},
+		{name: "unreachable", err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.EHOSTUNREACH)}, expected: monitor.PortFailureUnreachable},
+	}
+
+	for _, testCase := range testCases {
+		if got := portFailureReason(testCase.err); got != testCase.expected {
+			t.Fatalf("%s: expected %s, got %s", testCase.name, testCase.expected, got)
+		}
+	}
+}
+
+func startUDPServer(t *testing.T, reply func(payload []byte) []byte) int {
+	t.Helper()
+
+	connection, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to open UDP listener: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = connection.Close()
+	})
+
+	go func() {
+		buffer := make([]byte, 1500)
+		for {
+			n, peer, err := connection.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			if response := reply(buffer[:n]); response != nil {
+				_, _ = connection.WriteTo(response, peer)
+			}
+		}
+	}()
+
+	return connection.LocalAddr().(*net.UDPAddr).Port
+}
+
+func TestHandlePortMonitoringUDP(t *testing.T) {
+	t.Parallel()
+
+	echo := func(payload []byte) []byte {
+		return append([]byte(nil), payload...)
+	}
+	probeOnly := func(payload []byte) []byte {
+		if string(payload) != "status" {
+			return nil
+		}
+		return []byte("ok")
+	}
+	silent := func([]byte) []byte {
+		return nil
+	}
+
+	testCases := []struct {
+		name           string
+		reply          func([]byte) []byte
+		probe          string
+		expectedStatus monitor.Status
+	}{
+		{name: "echo with default probe", reply: echo, expectedStatus: monitor.StatusUp},
+		{name: "custom probe answered", reply: probeOnly, probe: "status", expectedStatus: monitor.StatusUp},
+		{name: "default probe ignored", reply: probeOnly, expectedStatus: monitor.StatusDown},
+		{name: "no reply", reply: silent, expectedStatus: monitor.StatusDown},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			port := startUDPServer(t, testCase.reply)
+			status, responseTime, _ := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil).handlePortMonitoring(context.Background(), monitor.Monitoring{
+				Target:       "127.0.0.1",
+				Port:         port,
+				Timeout:      1,
+				Protocol:     monitor.ProtocolUDP,
+				ProbePayload: testCase.probe,
+			})
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected %s, got %s", testCase.expectedStatus, status)
+			}
+			if status == monitor.StatusUp && responseTime == nil {
+				t.Fatalf("expected response time for reachable UDP port")
+			}
+			if status != monitor.StatusUp && responseTime != nil {
+				t.Fatalf("expected nil response time, got %v", *responseTime)
+			}
+		})
+	}
+}
+
+func TestHandlePortMonitoringUDPClosedPortIsDown(t *testing.T) {
+	t.Parallel()
+
+	connection, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve UDP port: %v", err)
+	}
+	port := connection.LocalAddr().(*net.UDPAddr).Port
+	_ = connection.Close()
+
+	status, _, _ := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil).handlePortMonitoring(context.Background(), monitor.Monitoring{
+		Target:   "127.0.0.1",
+		Port:     port,
+		Timeout:  1,
+		Protocol: monitor.ProtocolUDP,
+	})
+	if status != monitor.StatusDown {
+		t.Fatalf("expected down, got %s", status)
+	}
+}
+
+func TestCrawlResponseMonitoringUnknownType(t *testing.T) {
+	t.Parallel()
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	status, responseTime, httpStatusCode, _ := r.crawlResponseMonitoring(context.Background(), monitor.Monitoring{
+		Type: monitor.Type("custom"),
+	})
+	if status != monitor.StatusUnknown {
+		t.Fatalf("expected unknown status, got %s", status)
+	}
+	if responseTime != nil {
+		t.Fatalf("expected nil response time for unknown type")
+	}
+	if httpStatusCode != nil {
+		t.Fatalf("expected nil http status code for unknown type")
+	}
+}
+
+func TestCrawlResponseMonitoringPortReturnsNilHTTPStatusCode(t *testing.T) {
+	t.Parallel()
+
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to open listener: %v", err)
+	}
+	defer server.Close()
+
+	_, portRaw, err := net.SplitHostPort(server.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to split listener address: %v", err)
+	}
 	port, err := strconv.Atoi(portRaw)
 	if err != nil {
-		t.Fatalf("failed to parse listener port: %v", err)
+		t.Fatalf("failed to parse listener port: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn, acceptErr := server.Accept()
+		if acceptErr == nil && conn != nil {
+			_ = conn.Close()
+		}
+	}()
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	status, _, httpStatusCode, _ := r.crawlResponseMonitoring(context.Background(), monitor.Monitoring{
+		Type:   monitor.TypePort,
+		Target: "127.0.0.1",
+		Port:   port,
+	})
+
+	if status != monitor.StatusUp {
+		t.Fatalf("expected up status for open port, got %s", status)
+	}
+	if httpStatusCode != nil {
+		t.Fatalf("expected nil http status code for port monitoring")
+	}
+
+	select {
+	case <-done:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatalf("port monitor did not connect to test listener")
+	}
+}
+
+func TestCrawlMonitoringSSLValid(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	trustServerCertificate(r, server)
+	payload := r.crawlMonitoringSSL(context.Background(), monitor.Monitoring{
+		ID:     "12",
+		Target: server.URL,
+	})
+
+	if payload.MonitoringID != "12" {
+		t.Fatalf("unexpected monitoring id: %s", payload.MonitoringID)
+	}
+	if !payload.IsValid {
+		t.Fatalf("expected certificate to be valid for httptest TLS server")
+	}
+	if payload.ExpiresAt == nil || payload.IssuedAt == nil {
+		t.Fatalf("expected issued/expires timestamps")
+	}
+	if payload.ChainLength < 1 {
+		t.Fatalf("expected chain length of at least 1, got %d", payload.ChainLength)
+	}
+	if !slices.Contains(payload.SANs, "example.com") {
+		t.Fatalf("expected SANs to include example.com, got %#v", payload.SANs)
+	}
+	if !slices.Contains(payload.SANs, "127.0.0.1") {
+		t.Fatalf("expected SANs to include 127.0.0.1, got %#v", payload.SANs)
+	}
+}
+
+func TestCrawlMonitoringSSLReportsSerialNumberAndFingerprint(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	leaf := server.Certificate()
+	sum := sha256.Sum256(leaf.Raw)
+	expectedFingerprint := hex.EncodeToString(sum[:])
+	expectedSerial := leaf.SerialNumber.Text(16)
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	for attempt := 1; attempt <= 2; attempt++ {
+		payload := r.crawlMonitoringSSL(context.Background(), monitor.Monitoring{ID: "12", Target: server.URL})
+
+		if payload.SerialNumber == nil || *payload.SerialNumber != expectedSerial {
+			t.Fatalf("expected serial number %s on check %d, got %v", expectedSerial, attempt, pointerStringValue(payload.SerialNumber))
+		}
+		if payload.Fingerprint == nil || *payload.Fingerprint != expectedFingerprint {
+			t.Fatalf("expected fingerprint %s on check %d, got %v", expectedFingerprint, attempt, pointerStringValue(payload.Fingerprint))
+		}
+	}
+}
+
+func TestCrawlMonitoringSSLReportsFingerprintOfInvalidCertificate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	targetURL, _ := startTLSServerWithCertificate(t, now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	payload := r.crawlMonitoringSSL(context.Background(), monitor.Monitoring{ID: "expired", Target: targetURL})
+
+	if payload.IsValid {
+		t.Fatalf("expected expired certificate to be invalid")
+	}
+	if payload.SerialNumber == nil || payload.Fingerprint == nil || len(*payload.Fingerprint) != 64 {
+		t.Fatalf("expected serial number and SHA-256 fingerprint for an expired certificate, got %v and %v", pointerStringValue(payload.SerialNumber), pointerStringValue(payload.Fingerprint))
+	}
+}
+
+// startTLSServerWithCertificate serves a certificate issued by a fresh test
+// CA and returns a pool trusting that CA.
+func startTLSServerWithCertificate(t *testing.T, notBefore, notAfter time.Time) (string, *x509.CertPool) {
+	t.Helper()
+
+	ca, caKey, _ := newTestCA(t, "WebGuard Test CA")
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	return startTLSServerWithOCSPStaple(t, ca, caKey, notBefore, notAfter, nil), roots
+}
+
+// startTLSServerWithOCSPStaple serves a certificate with serial number 1,
+// issued by ca, together with ca and staples the given OCSP response to
+// every handshake.
+func startTLSServerWithOCSPStaple(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, notBefore, notAfter time.Time, staple []byte) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der, ca.Raw}, PrivateKey: key, OCSPStaple: staple}},
+	})
+	if err != nil {
+		t.Fatalf("failed to open TLS listener: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = conn.(*tls.Conn).Handshake()
+			}()
+		}
+	}()
+
+	return "https://" + listener.Addr().String()
+}
+
+// trustServerCertificate makes r trust the self-signed certificate of an
+// httptest TLS server.
+func trustServerCertificate(r *Runner, server *httptest.Server) {
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	r.rootCAs = roots
+}
+
+func TestHandleHTTPMonitoringReportsTimingBreakdown(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	targetURL := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	status, _, _, timings := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
+		Target:     targetURL,
+		Timeout:    2,
+		HTTPMethod: monitor.HTTPMethodGet,
+	})
+	if status != monitor.StatusUp {
+		t.Fatalf("expected up, got %s", status)
+	}
+
+	for name, value := range map[string]*float64{
+		"dns":     timings.DNSMs,
+		"connect": timings.ConnectMs,
+		"tls":     timings.TLSMs,
+		"ttfb":    timings.TTFBMs,
+	} {
+		if value == nil {
+			t.Fatalf("expected %s timing to be populated", name)
+		}
+		if *value < 0 {
+			t.Fatalf("expected non-negative %s timing, got %v", name, *value)
+		}
+	}
+}
+
+func TestHandleHTTPMonitoringTimingsNilOnFailure(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve port: %v", err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	status, _, _, timings := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
+		Target:     "http://" + address,
+		Timeout:    2,
+		HTTPMethod: monitor.HTTPMethodGet,
+	})
+	if status != monitor.StatusDown {
+		t.Fatalf("expected down, got %s", status)
+	}
+	if !reflect.DeepEqual(timings.httpTimings, httpTimings{}) {
+		t.Fatalf("expected empty timings on failure, got %#v", timings.httpTimings)
+	}
+}
+
+func TestHandleHTTPMonitoringFailureReasons(t *testing.T) {
+	t.Parallel()
+
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to open listener: %v", err)
+	}
+	closedAddress := closedListener.Addr().String()
+	_ = closedListener.Close()
+
+	// Accepted connections are closed with SO_LINGER 0, which sends a RST.
+	resetListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to open listener: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = resetListener.Close()
+	})
+	go func() {
+		for {
+			conn, err := resetListener.Accept()
+			if err != nil {
+				return
+			}
+			buffer := make([]byte, 1024)
+			_, _ = conn.Read(buffer)
+			_ = conn.(*net.TCPConn).SetLinger(0)
+			_ = conn.Close()
+		}
+	}()
+
+	// Answers every handshake with bytes that are not a TLS record.
+	garbageListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to open listener: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = garbageListener.Close()
+	})
+	go func() {
+		for {
+			conn, err := garbageListener.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05})
+			_ = conn.Close()
+		}
+	}()
+
+	tlsServer := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	tlsServer.Config.ErrorLog = log.New(io.Discard, "", 0)
+	tlsServer.StartTLS()
+	t.Cleanup(tlsServer.Close)
+
+	slowServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case <-request.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		writer.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(slowServer.Close)
+
+	testCases := []struct {
+		name      string
+		target    string
+		verifyTLS bool
+		expected  string
+	}{
+		{name: "dns failure", target: "http://webguard-missing-host.invalid/", expected: monitor.HTTPFailureDNS},
+		{name: "connection refused", target: "http://" + closedAddress, expected: monitor.HTTPFailureConnectionRefused},
+		{name: "connection reset", target: "http://" + resetListener.Addr().String(), expected: monitor.HTTPFailureConnectionReset},
+		{name: "malformed tls record", target: "https://" + garbageListener.Addr().String(), expected: monitor.HTTPFailureTLS},
+		{name: "untrusted certificate", target: tlsServer.URL, verifyTLS: true, expected: monitor.HTTPFailureTLS},
+		{name: "timeout", target: slowServer.URL, expected: monitor.HTTPFailureTimeout},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := New(nil, config.Config{VerifyTLS: testCase.verifyTLS}, slog.New(slog.DiscardHandler), nil)
+			status, responseTime, _, details := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
+				Target:     testCase.target,
+				Timeout:    1,
+				HTTPMethod: monitor.HTTPMethodGet,
+			})
+			if status != monitor.StatusDown {
+				t.Fatalf("expected down, got %s", status)
+			}
+			if responseTime != nil {
+				t.Fatalf("expected no response time, got %v", *responseTime)
+			}
+			if details.FailureReason == nil || *details.FailureReason != testCase.expected {
+				t.Fatalf("expected failure reason %s, got %v", testCase.expected, pointerStringValue(details.FailureReason))
+			}
+		})
+	}
+}
+
+func TestHTTPFailureReasonFallsBackToRequestFailed(t *testing.T) {
+	t.Parallel()
+
+	if got := httpFailureReason(errHTTP3Unsupported); got != monitor.HTTPFailureRequest {
+		t.Fatalf("expected %s, got %s", monitor.HTTPFailureRequest, got)
+	}
+}
+
+func generateClientCertificatePEM(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "webguard-instance"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
+
+func TestHandleHTTPMonitoringWithClientCertificate(t *testing.T) {
+	t.Parallel()
+
+	certPEM, keyPEM := generateClientCertificatePEM(t)
+	block, _ := pem.Decode([]byte(certPEM))
+	clientCertificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse client certificate: %v", err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCertificate)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	testCases := []struct {
+		name           string
+		certPEM        string
+		keyPEM         string
+		expectedStatus monitor.Status
+	}{
+		{name: "pem pair", certPEM: certPEM, keyPEM: keyPEM, expectedStatus: monitor.StatusUp},
+		{
+			name:           "base64 pair",
+			certPEM:        base64.StdEncoding.EncodeToString([]byte(certPEM)),
+			keyPEM:         base64.StdEncoding.EncodeToString([]byte(keyPEM)),
+			expectedStatus: monitor.StatusUp,
+		},
+		{name: "missing certificate", expectedStatus: monitor.StatusDown},
+		{name: "key without certificate", keyPEM: keyPEM, expectedStatus: monitor.StatusDown},
+		{name: "invalid pair", certPEM: "-----BEGIN CERTIFICATE-----\ninvalid\n-----END CERTIFICATE-----", keyPEM: keyPEM, expectedStatus: monitor.StatusDown},
+		{name: "invalid base64", certPEM: "not base64!", keyPEM: "not base64!", expectedStatus: monitor.StatusDown},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			status, _, _, _ := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
+				Target:        server.URL,
+				Timeout:       2,
+				HTTPMethod:    monitor.HTTPMethodGet,
+				ClientCertPEM: testCase.certPEM,
+				ClientKeyPEM:  testCase.keyPEM,
+			})
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected %s, got %s", testCase.expectedStatus, status)
+			}
+		})
+	}
+}
+
+func TestCrawlMonitoringSSLReportsTLSVersionAndCipher(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		maxVersion         uint16
+		expectedVersion    string
+		expectedDeprecated bool
+	}{
+		{name: "modern", expectedVersion: "TLS 1.3"},
+		{name: "tls 1.2", maxVersion: tls.VersionTLS12, expectedVersion: "TLS 1.2"},
+		{name: "deprecated tls 1.1", maxVersion: tls.VersionTLS11, expectedVersion: "TLS 1.1", expectedDeprecated: true},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				writer.WriteHeader(http.StatusOK)
+			}))
+			server.Config.ErrorLog = log.New(io.Discard, "", 0)
+			if testCase.maxVersion != 0 {
+				server.TLS = &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: testCase.maxVersion}
+			}
+			server.StartTLS()
+			t.Cleanup(server.Close)
+
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			payload := r.crawlMonitoringSSL(context.Background(), monitor.Monitoring{ID: "tls", Target: server.URL})
+
+			if payload.TLSVersion == nil || *payload.TLSVersion != testCase.expectedVersion {
+				t.Fatalf("expected tls version %q, got %v", testCase.expectedVersion, pointerStringValue(payload.TLSVersion))
+			}
+			if payload.CipherSuite == nil || !strings.HasPrefix(*payload.CipherSuite, "TLS_") {
+				t.Fatalf("expected a named cipher suite, got %v", pointerStringValue(payload.CipherSuite))
+			}
+			if payload.Deprecated != testCase.expectedDeprecated {
+				t.Fatalf("expected deprecated=%v, got %v", testCase.expectedDeprecated, payload.Deprecated)
+			}
+		})
+	}
+}
+
+func TestHandleHTTPMonitoringRequiresHTTP2(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		enableHTTP2    bool
+		protocol       monitor.HTTPProtocol
+		expectedStatus monitor.Status
+	}{
+		{name: "h2 negotiated", enableHTTP2: true, protocol: monitor.HTTPProtocolHTTP2, expectedStatus: monitor.StatusUp},
+		{name: "only http/1.1 offered", protocol: monitor.HTTPProtocolHTTP2, expectedStatus: monitor.StatusDown},
+		{name: "any protocol", protocol: monitor.HTTPProtocolAny, expectedStatus: monitor.StatusUp},
+		{name: "http3 unsupported", enableHTTP2: true, protocol: monitor.HTTPProtocolHTTP3, expectedStatus: monitor.StatusDown},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			protocols := make(chan string, 1)
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				protocols <- request.Proto
+				writer.WriteHeader(http.StatusOK)
+			}))
+			server.Config.ErrorLog = log.New(io.Discard, "", 0)
+			server.EnableHTTP2 = testCase.enableHTTP2
+			server.StartTLS()
+			t.Cleanup(server.Close)
+
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			status, _, _, _ := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
+				Target:       server.URL,
+				Timeout:      2,
+				HTTPMethod:   monitor.HTTPMethodGet,
+				HTTPProtocol: testCase.protocol,
+			})
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected %s, got %s", testCase.expectedStatus, status)
+			}
+			if testCase.protocol == monitor.HTTPProtocolHTTP2 && testCase.enableHTTP2 {
+				if got := <-protocols; got != "HTTP/2.0" {
+					t.Fatalf("expected the request to arrive over HTTP/2.0, got %s", got)
+				}
+			}
+		})
+	}
+}
+
+// newSNIRecordingServer starts a TLS server that reports the SNI of every
+// handshake it sees.
+func newSNIRecordingServer(t *testing.T) (*httptest.Server, <-chan string) {
+	t.Helper()
+
+	serverNames := make(chan string, 4)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverNames <- hello.ServerName
+			return nil, nil
+		},
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server, serverNames
+}
+
+func TestCrawlMonitoringSSLUsesTLSServerName(t *testing.T) {
+	t.Parallel()
+
+	server, serverNames := newSNIRecordingServer(t)
+
+	// The httptest certificate covers example.com, so the override is also
+	// what the hostname is verified against.
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	trustServerCertificate(r, server)
+	payload := r.crawlMonitoringSSL(context.Background(), monitor.Monitoring{ID: "sni", Target: server.URL, TLSServerName: "example.com"})
+
+	if got := <-serverNames; got != "example.com" {
+		t.Fatalf("expected sni example.com, got %q", got)
+	}
+	if !payload.IsValid {
+		t.Fatalf("expected certificate to verify against the overridden server name, got failure %v", pointerStringValue(payload.FailureReason))
+	}
+
+	payload = r.crawlMonitoringSSL(context.Background(), monitor.Monitoring{ID: "sni", Target: server.URL, TLSServerName: "origin.example.test"})
+	if got := <-serverNames; got != "origin.example.test" {
+		t.Fatalf("expected sni origin.example.test, got %q", got)
+	}
+	if payload.IsValid || payload.FailureReason == nil || *payload.FailureReason != monitor.SSLFailureHostnameMismatch {
+		t.Fatalf("expected hostname mismatch for a name the certificate does not cover, got %v", pointerStringValue(payload.FailureReason))
+	}
+}
+
+func TestPerformHTTPRequestUsesTLSServerName(t *testing.T) {
+	t.Parallel()
+
+	server, serverNames := newSNIRecordingServer(t)
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	statusCode, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+		Target:        server.URL,
+		Timeout:       2,
+		HTTPMethod:    monitor.HTTPMethodGet,
+		TLSServerName: "origin.example.test",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if statusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", statusCode)
+	}
+	if got := <-serverNames; got != "origin.example.test" {
+		t.Fatalf("expected sni origin.example.test, got %q", got)
+	}
+}
+
+func TestCrawlMonitoringSSLExpiringSoon(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		validFor     time.Duration
+		expectedDays int
+		expiringSoon bool
+	}{
+		{name: "expires in 3 days", validFor: 3*24*time.Hour + time.Hour, expectedDays: 3, expiringSoon: true},
+		{name: "expires in 90 days", validFor: 90*24*time.Hour + time.Hour, expectedDays: 90, expiringSoon: false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			now := time.Now()
+			targetURL, roots := startTLSServerWithCertificate(t, now.Add(-time.Hour), now.Add(testCase.validFor))
+
+			r := New(nil, config.Config{SSLExpiryWarnDays: 14}, slog.New(slog.DiscardHandler), nil)
+			r.rootCAs = roots
+			payload := r.crawlMonitoringSSL(context.Background(), monitor.Monitoring{
+				ID:     "ssl-expiry",
+				Target: targetURL,
+			})
+
+			if !payload.IsValid {
+				t.Fatalf("expected certificate to be valid")
+			}
+			if payload.DaysUntilExpiry == nil {
+				t.Fatalf("expected days_until_expiry")
+			}
+			if *payload.DaysUntilExpiry != testCase.expectedDays {
+				t.Fatalf("expected %d days until expiry, got %d", testCase.expectedDays, *payload.DaysUntilExpiry)
+			}
+			if payload.ExpiringSoon != testCase.expiringSoon {
+				t.Fatalf("expected expiring_soon=%v, got %v", testCase.expiringSoon, payload.ExpiringSoon)
+			}
+		})
 	}
+}
 
-	done := make(chan struct{})
-	go func() {
-		defer close(done)
-		conn, acceptErr := server.Accept()
-		if acceptErr == nil && conn != nil {
-			_ = conn.Close()
-		}
-	}()
+type fixedClock struct {
+	now time.Time
+}
 
-	r := New(nil, config.Config{}, log.New(io.Discard, "", 0))
-	status, _, httpStatusCode := r.crawlResponseMonitoring(context.Background(), monitor.Monitoring{
-		Type:   monitor.TypePort,
-		Target: "127.0.0.1",
-		Port:   port,
-	})
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
 
-	if status != monitor.StatusUp {
-		t.Fatalf("expected up status for open port, got %s", status)
-	}
-	if httpStatusCode != nil {
-		t.Fatalf("expected nil http status code for port monitoring")
+func TestCrawlMonitoringSSLExpiryAtFixedInstant(t *testing.T) {
+	t.Parallel()
+
+	notBefore := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	targetURL, roots := startTLSServerWithCertificate(t, notBefore, notAfter)
+
+	testCases := []struct {
+		name          string
+		now           time.Time
+		expectedValid bool
+		expectedDays  int
+		expiringSoon  bool
+		failureReason string
+	}{
+		{name: "one day outside the warning window", now: notAfter.Add(-15 * 24 * time.Hour), expectedValid: true, expectedDays: 15},
+		{name: "exactly at the warning threshold", now: notAfter.Add(-14 * 24 * time.Hour), expectedValid: true, expectedDays: 14, expiringSoon: true},
+		{name: "last second of validity", now: notAfter.Add(-time.Second), expectedValid: true, expectedDays: 0, expiringSoon: true},
+		{name: "after expiry", now: notAfter.Add(time.Second), failureReason: monitor.SSLFailureExpired},
+		{name: "before validity", now: notBefore.Add(-time.Second), failureReason: monitor.SSLFailureNotYetValid},
 	}
 
-	select {
-	case <-done:
-	case <-time.After(100 * time.Millisecond):
-		t.Fatalf("port monitor did not connect to test listener")
+	for _, testCase := range testCases {
+		r := New(nil, config.Config{SSLExpiryWarnDays: 14}, slog.New(slog.DiscardHandler), nil)
+		r.clock = fixedClock{now: testCase.now}
+		r.rootCAs = roots
+		payload := r.crawlMonitoringSSL(context.Background(), monitor.Monitoring{ID: "ssl-clock", Target: targetURL})
+
+		if payload.IsValid != testCase.expectedValid {
+			t.Fatalf("%s: expected is_valid=%v, got %v", testCase.name, testCase.expectedValid, payload.IsValid)
+		}
+		if !testCase.expectedValid {
+			if payload.FailureReason == nil || *payload.FailureReason != testCase.failureReason {
+				t.Fatalf("%s: expected failure reason %s, got %v", testCase.name, testCase.failureReason, pointerStringValue(payload.FailureReason))
+			}
+			continue
+		}
+		if payload.DaysUntilExpiry == nil || *payload.DaysUntilExpiry != testCase.expectedDays {
+			t.Fatalf("%s: expected %d days until expiry, got %v", testCase.name, testCase.expectedDays, pointerIntValue(payload.DaysUntilExpiry))
+		}
+		if payload.ExpiringSoon != testCase.expiringSoon {
+			t.Fatalf("%s: expected expiring_soon=%v, got %v", testCase.name, testCase.expiringSoon, payload.ExpiringSoon)
+		}
 	}
 }
 
-func TestCrawlMonitoringSSLValid(t *testing.T) {
+func TestCrawlMonitoringSSLReportsFailureReason(t *testing.T) {
 	t.Parallel()
 
-	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-		writer.WriteHeader(http.StatusOK)
-	}))
-	defer server.Close()
+	now := time.Now()
+	testCases := []struct {
+		name           string
+		notBefore      time.Time
+		notAfter       time.Time
+		hostname       string
+		closed         bool
+		expectedValid  bool
+		expectedReason string
+	}{
+		{name: "valid certificate", notBefore: now.Add(-time.Hour), notAfter: now.Add(30 * 24 * time.Hour), expectedValid: true},
+		{name: "expired certificate", notBefore: now.Add(-48 * time.Hour), notAfter: now.Add(-24 * time.Hour), expectedReason: monitor.SSLFailureExpired},
+		{name: "not yet valid certificate", notBefore: now.Add(24 * time.Hour), notAfter: now.Add(48 * time.Hour), expectedReason: monitor.SSLFailureNotYetValid},
+		{name: "hostname mismatch", notBefore: now.Add(-time.Hour), notAfter: now.Add(30 * 24 * time.Hour), hostname: "localhost", expectedReason: monitor.SSLFailureHostnameMismatch},
+		{name: "connection failed", closed: true, expectedReason: monitor.SSLFailureConnectionFailed},
+	}
 
-	r := New(nil, config.Config{}, log.New(io.Discard, "", 0))
-	payload := r.crawlMonitoringSSL(monitor.Monitoring{
-		ID:     "12",
-		Target: server.URL,
-	})
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
 
-	if payload.MonitoringID != "12" {
-		t.Fatalf("unexpected monitoring id: %s", payload.MonitoringID)
-	}
-	if !payload.IsValid {
-		t.Fatalf("expected certificate to be valid for httptest TLS server")
-	}
-	if payload.ExpiresAt == nil || payload.IssuedAt == nil {
-		t.Fatalf("expected issued/expires timestamps")
+			targetURL := "https://127.0.0.1:1"
+			var roots *x509.CertPool
+			if !testCase.closed {
+				targetURL, roots = startTLSServerWithCertificate(t, testCase.notBefore, testCase.notAfter)
+			}
+			if testCase.hostname != "" {
+				targetURL = strings.Replace(targetURL, "127.0.0.1", testCase.hostname, 1)
+			}
+
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			r.rootCAs = roots
+			payload := r.crawlMonitoringSSL(context.Background(), monitor.Monitoring{
+				ID:     "ssl-reason",
+				Target: targetURL,
+			})
+
+			if payload.IsValid != testCase.expectedValid {
+				t.Fatalf("expected is_valid=%v, got %v", testCase.expectedValid, payload.IsValid)
+			}
+			if testCase.expectedReason == "" {
+				if payload.FailureReason != nil {
+					t.Fatalf("expected no failure reason, got %q", *payload.FailureReason)
+				}
+				return
+			}
+			if payload.FailureReason == nil || *payload.FailureReason != testCase.expectedReason {
+				t.Fatalf("expected failure reason %q, got %v", testCase.expectedReason, payload.FailureReason)
+			}
+		})
 	}
 }
 
@@ -499,8 +2775,8 @@ func TestRunSSLPostsResults(t *testing.T) {
 		WebGuardLocation:    "de-1",
 		QueueDefaultWorkers: 1,
 	}
-	r := New(client, cfg, log.New(io.Discard, "", 0))
-	if err := r.runSSL(context.Background()); err != nil {
+	r := New(client, cfg, slog.New(slog.DiscardHandler), nil)
+	if err := r.runSSL(context.Background(), r.newRunState()); err != nil {
 		t.Fatalf("runSSL failed: %v", err)
 	}
 
@@ -535,7 +2811,7 @@ func TestRunDomainExpirationPostsUpResponseAndMetadata(t *testing.T) {
 	r := New(client, config.Config{
 		WebGuardLocation:    "de-1",
 		QueueDefaultWorkers: 1,
-	}, log.New(io.Discard, "", 0))
+	}, slog.New(slog.DiscardHandler), nil)
 	r.domainLookup = staticDomainLookup{
 		result: domainlookup.Result{
 			Domain:     "example.com",
@@ -546,7 +2822,7 @@ func TestRunDomainExpirationPostsUpResponseAndMetadata(t *testing.T) {
 		},
 	}
 
-	if err := r.runDomainExpiration(context.Background()); err != nil {
+	if err := r.runDomainExpiration(context.Background(), r.newRunState()); err != nil {
 		t.Fatalf("runDomainExpiration failed: %v", err)
 	}
 
@@ -597,7 +2873,7 @@ func TestRunDomainExpirationExpiringWithinThirtyDaysIsDown(t *testing.T) {
 	r := New(client, config.Config{
 		WebGuardLocation:    "de-1",
 		QueueDefaultWorkers: 1,
-	}, log.New(io.Discard, "", 0))
+	}, slog.New(slog.DiscardHandler), nil)
 	r.domainLookup = staticDomainLookup{
 		result: domainlookup.Result{
 			Registered: true,
@@ -606,7 +2882,7 @@ func TestRunDomainExpirationExpiringWithinThirtyDaysIsDown(t *testing.T) {
 		},
 	}
 
-	if err := r.runDomainExpiration(context.Background()); err != nil {
+	if err := r.runDomainExpiration(context.Background(), r.newRunState()); err != nil {
 		t.Fatalf("runDomainExpiration failed: %v", err)
 	}
 
@@ -643,12 +2919,12 @@ func TestRunDomainExpirationTemporaryLookupFailurePostsUnknownOnly(t *testing.T)
 	r := New(client, config.Config{
 		WebGuardLocation:    "de-1",
 		QueueDefaultWorkers: 1,
-	}, log.New(io.Discard, "", 0))
+	}, slog.New(slog.DiscardHandler), nil)
 	r.domainLookup = staticDomainLookup{
 		err: &domainlookup.TemporaryError{Err: errors.New("timeout")},
 	}
 
-	if err := r.runDomainExpiration(context.Background()); err != nil {
+	if err := r.runDomainExpiration(context.Background(), r.newRunState()); err != nil {
 		t.Fatalf("runDomainExpiration failed: %v", err)
 	}
 
@@ -681,12 +2957,12 @@ func TestRunDomainExpirationMaintenancePostsUnknownWithoutLookup(t *testing.T) {
 	r := New(client, config.Config{
 		WebGuardLocation:    "de-1",
 		QueueDefaultWorkers: 1,
-	}, log.New(io.Discard, "", 0))
+	}, slog.New(slog.DiscardHandler), nil)
 	r.domainLookup = staticDomainLookup{
 		err: errors.New("lookup should not run"),
 	}
 
-	if err := r.runDomainExpiration(context.Background()); err != nil {
+	if err := r.runDomainExpiration(context.Background(), r.newRunState()); err != nil {
 		t.Fatalf("runDomainExpiration failed: %v", err)
 	}
 
@@ -753,7 +3029,7 @@ func TestRunDomainExpirationUsesCoreAPIEndpoints(t *testing.T) {
 	r := New(core.NewClient(server.URL, "secret-key", "de-1"), config.Config{
 		WebGuardLocation:    "de-1",
 		QueueDefaultWorkers: 1,
-	}, log.New(io.Discard, "", 0))
+	}, slog.New(slog.DiscardHandler), nil)
 	r.domainLookup = staticDomainLookup{
 		result: domainlookup.Result{
 			Registered: true,
@@ -762,7 +3038,7 @@ func TestRunDomainExpirationUsesCoreAPIEndpoints(t *testing.T) {
 		},
 	}
 
-	if err := r.runDomainExpiration(context.Background()); err != nil {
+	if err := r.runDomainExpiration(context.Background(), r.newRunState()); err != nil {
 		t.Fatalf("runDomainExpiration failed: %v", err)
 	}
 
@@ -789,17 +3065,490 @@ func TestLogFetchErrorIncludesStatusBody(t *testing.T) {
 	t.Parallel()
 
 	var logs bytes.Buffer
-	r := New(nil, config.Config{}, log.New(&logs, "", 0))
+	r := New(nil, config.Config{}, slog.New(slog.NewTextHandler(&logs, nil)), nil)
 
 	r.logFetchError(&core.HTTPStatusError{
 		StatusCode: http.StatusForbidden,
 		Body:       "forbidden",
 	})
 
-	if !bytes.Contains(logs.Bytes(), []byte("Failed to fetch monitorings from the Core API.")) {
+	if !bytes.Contains(logs.Bytes(), []byte("Failed to fetch monitorings from the Core API")) {
 		t.Fatalf("expected generic fetch error log, got %q", logs.String())
 	}
-	if !bytes.Contains(logs.Bytes(), []byte("forbidden")) {
+	if !bytes.Contains(logs.Bytes(), []byte("body=forbidden")) {
 		t.Fatalf("expected response body to be logged, got %q", logs.String())
 	}
 }
+
+func TestHandleHTTPMonitoringComparesBodyHash(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = io.WriteString(writer, "<html>\n  <body>stable</body>\n</html>\n")
+	}))
+	t.Cleanup(server.Close)
+
+	hashOf := func(body string) string {
+		sum := sha256.Sum256([]byte(body))
+		return hex.EncodeToString(sum[:])
+	}
+
+	testCases := []struct {
+		name             string
+		expectedHash     string
+		ignoreWhitespace bool
+		expectedStatus   monitor.Status
+	}{
+		{name: "matching body", expectedHash: hashOf("<html>\n  <body>stable</body>\n</html>\n"), expectedStatus: monitor.StatusUp},
+		{name: "changed body", expectedHash: hashOf("<html><body>old</body></html>"), expectedStatus: monitor.StatusDown},
+		{name: "whitespace ignored", expectedHash: hashOf("<html><body>stable</body></html>"), ignoreWhitespace: true, expectedStatus: monitor.StatusUp},
+		{name: "whitespace significant", expectedHash: hashOf("<html><body>stable</body></html>"), expectedStatus: monitor.StatusDown},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			status, _, statusCode, details := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
+				Target:                   server.URL,
+				Timeout:                  2,
+				HTTPMethod:               monitor.HTTPMethodGet,
+				ExpectedBodyHash:         testCase.expectedHash,
+				BodyHashIgnoreWhitespace: testCase.ignoreWhitespace,
+			})
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected %s, got %s", testCase.expectedStatus, status)
+			}
+			if statusCode == nil || *statusCode != http.StatusOK {
+				t.Fatalf("expected status code 200 to be reported, got %v", statusCode)
+			}
+			if testCase.expectedStatus == monitor.StatusDown {
+				if details.FailureReason == nil || *details.FailureReason != monitor.HTTPFailureBodyHashMismatch {
+					t.Fatalf("expected failure reason %s, got %v", monitor.HTTPFailureBodyHashMismatch, details.FailureReason)
+				}
+			} else if details.FailureReason != nil {
+				t.Fatalf("expected no failure reason, got %s", *details.FailureReason)
+			}
+		})
+	}
+}
+
+func TestHandleHTTPMonitoringFlagsUnexpectedContentType(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		// A captive portal answers every request with its login page.
+		writer.Header().Set("Content-Type", request.URL.Query().Get("type"))
+		_, _ = io.WriteString(writer, "<html><body>Sign in to the guest network</body></html>")
+	}))
+	t.Cleanup(server.Close)
+
+	testCases := []struct {
+		name           string
+		contentType    string
+		expected       string
+		expectedStatus monitor.Status
+	}{
+		{name: "portal html instead of json", contentType: "text/html; charset=utf-8", expected: "application/json", expectedStatus: monitor.StatusDown},
+		{name: "missing content type", contentType: "", expected: "application/json", expectedStatus: monitor.StatusDown},
+		{name: "matching type with charset", contentType: "application/json; charset=utf-8", expected: "application/json", expectedStatus: monitor.StatusUp},
+		{name: "wildcard subtype", contentType: "text/plain", expected: "text/*", expectedStatus: monitor.StatusUp},
+		{name: "no expectation", contentType: "text/html", expectedStatus: monitor.StatusUp},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			status, _, statusCode, details := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
+				Target:              server.URL + "/?type=" + url.QueryEscape(testCase.contentType),
+				Timeout:             2,
+				HTTPMethod:          monitor.HTTPMethodGet,
+				ExpectedContentType: testCase.expected,
+			})
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected %s, got %s", testCase.expectedStatus, status)
+			}
+			if statusCode == nil || *statusCode != http.StatusOK {
+				t.Fatalf("expected status code 200 to be reported, got %v", statusCode)
+			}
+			if testCase.expectedStatus == monitor.StatusDown {
+				if details.FailureReason == nil || *details.FailureReason != monitor.HTTPFailureUnexpectedContent {
+					t.Fatalf("expected failure reason %s, got %v", monitor.HTTPFailureUnexpectedContent, details.FailureReason)
+				}
+			} else if details.FailureReason != nil {
+				t.Fatalf("expected no failure reason, got %s", *details.FailureReason)
+			}
+		})
+	}
+}
+
+func TestHandleKeywordMonitoringFlagsUnexpectedContent(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/broken" {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		writer.Header().Set("Content-Type", "text/html")
+		_, _ = io.WriteString(writer, "<html><body>Sign in to the guest network</body></html>")
+	}))
+	t.Cleanup(server.Close)
+
+	testCases := []struct {
+		name           string
+		path           string
+		contentType    string
+		expectedReason string
+	}{
+		{name: "keyword missing despite 200", path: "/", expectedReason: monitor.HTTPFailureUnexpectedContent},
+		{name: "content type mismatch", path: "/", contentType: "application/json", expectedReason: monitor.HTTPFailureUnexpectedContent},
+		{name: "server error", path: "/broken"},
+	}
+
+	for _, testCase := range testCases {
+		r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+		status, _, _, details := r.handleKeywordMonitoring(context.Background(), monitor.Monitoring{
+			Type:                monitor.TypeKeyword,
+			Target:              server.URL + testCase.path,
+			Timeout:             2,
+			HTTPMethod:          monitor.HTTPMethodGet,
+			Keyword:             "all-systems-operational",
+			ExpectedContentType: testCase.contentType,
+		})
+		if status != monitor.StatusDown {
+			t.Fatalf("%s: expected down, got %s", testCase.name, status)
+		}
+		var reason string
+		if details.FailureReason != nil {
+			reason = *details.FailureReason
+		}
+		if reason != testCase.expectedReason {
+			t.Fatalf("%s: expected failure reason %q, got %q", testCase.name, testCase.expectedReason, reason)
+		}
+	}
+}
+
+func TestHandleHTTPMonitoringEnforcesTLSMinVersion(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		serverMaxVersion uint16
+		minVersion       string
+		expectedStatus   monitor.Status
+	}{
+		{name: "tls 1.0 server allowed", serverMaxVersion: tls.VersionTLS10, minVersion: "1.0", expectedStatus: monitor.StatusUp},
+		{name: "tls 1.0 server below minimum", serverMaxVersion: tls.VersionTLS10, minVersion: "1.2", expectedStatus: monitor.StatusDown},
+		{name: "tls 1.0 server with go default", serverMaxVersion: tls.VersionTLS10, expectedStatus: monitor.StatusDown},
+		{name: "tls 1.2 server below minimum", serverMaxVersion: tls.VersionTLS12, minVersion: "1.3", expectedStatus: monitor.StatusDown},
+		{name: "tls 1.3 server", serverMaxVersion: tls.VersionTLS13, minVersion: "1.3", expectedStatus: monitor.StatusUp},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusOK)
+			}))
+			server.Config.ErrorLog = log.New(io.Discard, "", 0)
+			server.TLS = &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: testCase.serverMaxVersion}
+			server.StartTLS()
+			t.Cleanup(server.Close)
+
+			r := New(nil, config.Config{TLSMinVersion: testCase.minVersion}, slog.New(slog.DiscardHandler), nil)
+			status, _, _, details := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
+				Target:     server.URL,
+				Timeout:    2,
+				HTTPMethod: monitor.HTTPMethodGet,
+			})
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected %s, got %s", testCase.expectedStatus, status)
+			}
+			if status == monitor.StatusDown && (details.FailureReason == nil || *details.FailureReason != monitor.HTTPFailureTLS) {
+				t.Fatalf("expected failure reason %s, got %v", monitor.HTTPFailureTLS, *details.FailureReason)
+			}
+		})
+	}
+}
+
+func TestCheckResponseMonitoringBlocksPrivateTargets(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	testCases := []struct {
+		name           string
+		block          bool
+		expectedStatus monitor.Status
+		expectedReason string
+	}{
+		{name: "allowed by default", block: false, expectedStatus: monitor.StatusUp},
+		{name: "blocked", block: true, expectedStatus: monitor.StatusDown, expectedReason: monitor.FailureTargetBlocked},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := New(nil, config.Config{BlockPrivateTargets: testCase.block}, slog.New(slog.DiscardHandler), nil)
+			status, _, _, details := r.checkResponseMonitoring(context.Background(), monitor.Monitoring{
+				ID:         "blocked-http",
+				Type:       monitor.TypeHTTP,
+				Target:     server.URL,
+				Timeout:    2,
+				HTTPMethod: monitor.HTTPMethodGet,
+			})
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected status %s, got %s", testCase.expectedStatus, status)
+			}
+			reason := ""
+			if details.FailureReason != nil {
+				reason = *details.FailureReason
+			}
+			if reason != testCase.expectedReason {
+				t.Fatalf("expected failure reason %q, got %q", testCase.expectedReason, reason)
+			}
+		})
+	}
+}
+
+func TestCheckResponseMonitoringBlocksRedirectToPrivateTarget(t *testing.T) {
+	t.Parallel()
+
+	// The proxy stands in for the internet: the public target redirects to
+	// a loopback address, which must not be followed.
+	var internalHits atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Hostname() == "public.example.test" {
+			http.Redirect(writer, request, "http://127.0.0.1:8080/latest/meta-data/", http.StatusFound)
+			return
+		}
+		internalHits.Add(1)
+		writer.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(proxy.Close)
+
+	r := New(nil, config.Config{BlockPrivateTargets: true, HTTPProxyURL: proxy.URL}, slog.New(slog.DiscardHandler), nil)
+	status, _, _, details := r.checkResponseMonitoring(context.Background(), monitor.Monitoring{
+		ID:         "redirecting-http",
+		Type:       monitor.TypeHTTP,
+		Target:     "http://public.example.test/",
+		Timeout:    2,
+		HTTPMethod: monitor.HTTPMethodGet,
+	})
+	if status != monitor.StatusDown {
+		t.Fatalf("expected the redirect to a private target to be down, got %s", status)
+	}
+	if details.FailureReason == nil || *details.FailureReason != monitor.FailureTargetBlocked {
+		t.Fatalf("expected failure reason %q, got %v", monitor.FailureTargetBlocked, pointerStringValue(details.FailureReason))
+	}
+	if hits := internalHits.Load(); hits != 0 {
+		t.Fatalf("expected the private target not to be requested, got %d requests", hits)
+	}
+}
+
+func TestDialerBlocksPrivateAddressesAfterResolution(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	r := New(nil, config.Config{BlockPrivateTargets: true}, slog.New(slog.DiscardHandler), nil)
+	// localhost stands in for a name whose answer changed to a private
+	// address after the pre-dial check.
+	_, err = r.dialer(2*time.Second).DialContext(context.Background(), "tcp4", net.JoinHostPort("localhost", port))
+	if !errors.Is(err, target.ErrPrivateAddress) {
+		t.Fatalf("expected the dial to be refused, got %v", err)
+	}
+
+	status, _, details := r.handlePortMonitoring(context.Background(), monitor.Monitoring{Target: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port, Timeout: 2})
+	if status != monitor.StatusDown || pointerStringValue(details.FailureReason) != monitor.FailureTargetBlocked {
+		t.Fatalf("expected the port check to be blocked, got %s %v", status, pointerStringValue(details.FailureReason))
+	}
+}
+func TestCABundleTrustsPrivateCA(t *testing.T) {
+	t.Parallel()
+
+	caCertificate, caKey, caPEM := newTestCA(t, "WebGuard Private CA")
+	_, _, otherCAPEM := newTestCA(t, "Unrelated CA")
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{issueTestCertificate(t, caCertificate, caKey)}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	directory := t.TempDir()
+	bundlePath := filepath.Join(directory, "ca.pem")
+	otherBundlePath := filepath.Join(directory, "other-ca.pem")
+	if err := os.WriteFile(bundlePath, caPEM, 0o600); err != nil {
+		t.Fatalf("failed to write CA bundle: %v", err)
+	}
+	if err := os.WriteFile(otherBundlePath, otherCAPEM, 0o600); err != nil {
+		t.Fatalf("failed to write CA bundle: %v", err)
+	}
+
+	testCases := []struct {
+		name           string
+		bundlePath     string
+		expectedStatus monitor.Status
+		expectedSSL    bool
+		expectedReason string
+	}{
+		{name: "system roots", expectedStatus: monitor.StatusDown, expectedReason: monitor.SSLFailureUntrustedChain},
+		{name: "bundle with signing CA", bundlePath: bundlePath, expectedStatus: monitor.StatusUp, expectedSSL: true},
+		{name: "bundle with other CA", bundlePath: otherBundlePath, expectedStatus: monitor.StatusDown, expectedReason: monitor.SSLFailureUntrustedChain},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := New(nil, config.Config{VerifyTLS: true, CABundlePath: testCase.bundlePath}, slog.New(slog.DiscardHandler), nil)
+			status, _, _, _ := r.handleHTTPMonitoring(context.Background(), monitor.Monitoring{
+				Target:     server.URL,
+				Timeout:    2,
+				HTTPMethod: monitor.HTTPMethodGet,
+			})
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected HTTP check %s, got %s", testCase.expectedStatus, status)
+			}
+
+			payload := r.crawlMonitoringSSL(context.Background(), monitor.Monitoring{ID: "private-ca", Target: server.URL})
+			reason := ""
+			if payload.FailureReason != nil {
+				reason = *payload.FailureReason
+			}
+			if payload.IsValid != testCase.expectedSSL {
+				t.Fatalf("expected SSL validity %v, got %v (reason %q)", testCase.expectedSSL, payload.IsValid, reason)
+			}
+			if reason != testCase.expectedReason {
+				t.Fatalf("expected SSL failure reason %q, got %q", testCase.expectedReason, reason)
+			}
+		})
+	}
+}
+
+func newTestCA(t *testing.T, commonName string) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().AddDate(-1, 0, 0),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+	return certificate, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func issueTestCertificate(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestPerformHTTPRequestRetriesOnlyIdempotentMethods(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		method           monitor.HTTPMethod
+		retryUnsafe      bool
+		dropConnection   bool
+		expectedAttempts int32
+	}{
+		{name: "post not retried on 503", method: monitor.HTTPMethodPost, expectedAttempts: 1},
+		{name: "post not retried on transport error", method: monitor.HTTPMethodPost, dropConnection: true, expectedAttempts: 1},
+		{name: "post retried with opt-in", method: monitor.HTTPMethodPost, retryUnsafe: true, expectedAttempts: 3},
+		{name: "post retried on transport error with opt-in", method: monitor.HTTPMethodPost, retryUnsafe: true, dropConnection: true, expectedAttempts: 3},
+		{name: "patch not retried", method: monitor.HTTPMethodPatch, expectedAttempts: 1},
+		{name: "get retried", method: monitor.HTTPMethodGet, expectedAttempts: 3},
+		{name: "delete retried on transport error", method: monitor.HTTPMethodDelete, dropConnection: true, expectedAttempts: 3},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				attempts.Add(1)
+				if testCase.dropConnection {
+					connection, _, err := writer.(http.Hijacker).Hijack()
+					if err == nil {
+						_ = connection.Close()
+					}
+					return
+				}
+				writer.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			t.Cleanup(server.Close)
+
+			r := New(nil, config.Config{HTTPRetryTimes: 2, HTTPRetryBaseDelayMS: 1}, slog.New(slog.DiscardHandler), nil)
+			_, _, _, _ = r.performHTTPRequest(context.Background(), monitor.Monitoring{
+				Target:             server.URL,
+				Timeout:            5,
+				HTTPMethod:         testCase.method,
+				HTTPBody:           `{"ping":true}`,
+				RetryUnsafeMethods: testCase.retryUnsafe,
+			})
+			if got := attempts.Load(); got != testCase.expectedAttempts {
+				t.Fatalf("expected %d attempts, got %d", testCase.expectedAttempts, got)
+			}
+		})
+	}
+}
@@ -3,17 +3,31 @@ package runner
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/core"
+	"github.com/m-breuer/webguard-instance-v2/internal/domainlookup"
+	"github.com/m-breuer/webguard-instance-v2/internal/logging"
+	"github.com/m-breuer/webguard-instance-v2/internal/metrics"
 	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+	"github.com/m-breuer/webguard-instance-v2/internal/server"
 )
 
 type getMonitoringsCall struct {
@@ -33,6 +47,10 @@ type fakeCoreClient struct {
 	postedResponses []monitor.MonitoringResponsePayload
 	postedSSL       []monitor.SSLResultPayload
 	postedDomains   []monitor.DomainResultPayload
+	postedSummaries []monitor.RunSummaryPayload
+
+	responseBatches [][]monitor.MonitoringResponsePayload
+	sslBatches      [][]monitor.SSLResultPayload
 }
 
 func (f *fakeCoreClient) GetMonitorings(_ context.Context, location string, types []monitor.Type) ([]monitor.Monitoring, error) {
@@ -43,6 +61,11 @@ func (f *fakeCoreClient) GetMonitorings(_ context.Context, location string, type
 	})
 	f.mu.Unlock()
 
+	if len(types) == len(monitor.CheckedTypes) {
+		monitorings := append([]monitor.Monitoring(nil), f.responseMonitorings...)
+		monitorings = append(monitorings, f.sslMonitorings...)
+		return append(monitorings, f.domainMonitorings...), nil
+	}
 	if len(types) == len(responseMonitoringTypes) {
 		return append([]monitor.Monitoring(nil), f.responseMonitorings...), nil
 	}
@@ -67,6 +90,20 @@ func (f *fakeCoreClient) PostSSLResult(_ context.Context, payload monitor.SSLRes
 	return nil
 }
 
+func (f *fakeCoreClient) PostMonitoringResponses(_ context.Context, payloads []monitor.MonitoringResponsePayload) error {
+	f.mu.Lock()
+	f.responseBatches = append(f.responseBatches, slices.Clone(payloads))
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeCoreClient) PostSSLResults(_ context.Context, payloads []monitor.SSLResultPayload) error {
+	f.mu.Lock()
+	f.sslBatches = append(f.sslBatches, slices.Clone(payloads))
+	f.mu.Unlock()
+	return nil
+}
+
 func (f *fakeCoreClient) PostDomainResult(_ context.Context, payload monitor.DomainResultPayload) error {
 	f.mu.Lock()
 	f.postedDomains = append(f.postedDomains, payload)
@@ -98,6 +135,19 @@ func (f *fakeCoreClient) snapshotPostedDomains() []monitor.DomainResultPayload {
 	return append([]monitor.DomainResultPayload(nil), f.postedDomains...)
 }
 
+func (f *fakeCoreClient) PostRunSummary(_ context.Context, payload monitor.RunSummaryPayload) error {
+	f.mu.Lock()
+	f.postedSummaries = append(f.postedSummaries, payload)
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeCoreClient) snapshotPostedSummaries() []monitor.RunSummaryPayload {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]monitor.RunSummaryPayload(nil), f.postedSummaries...)
+}
+
 func TestRunMonitoringMaintenancePostsUnknown(t *testing.T) {
 	t.Parallel()
 
@@ -116,58 +166,22 @@ func TestRunMonitoringMaintenancePostsUnknown(t *testing.T) {
 		WebGuardLocation:    "de-1",
 		QueueDefaultWorkers: 1,
 	}
-	runner := New(client, cfg, log.New(io.Discard, "", 0))
+	runner := New(client, cfg, slog.New(slog.DiscardHandler), nil)
 
 	if err := runner.RunMonitoring(context.Background()); err != nil {
 		t.Fatalf("RunMonitoring failed: %v", err)
 	}
 
 	calls := client.snapshotCalls()
-	if len(calls) != 3 {
-		t.Fatalf("expected 3 monitoring fetch calls, got %d", len(calls))
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 monitoring fetch call, got %d", len(calls))
 	}
-
-	var foundResponseFetch bool
-	var foundSSLFetch bool
-	var foundDomainFetch bool
-	for _, call := range calls {
-		if call.location != "de-1" {
-			t.Fatalf("expected location de-1, got %q", call.location)
-		}
-
-		if len(call.types) == 4 &&
-			call.types[0] == monitor.TypeHTTP &&
-			call.types[1] == monitor.TypePing &&
-			call.types[2] == monitor.TypeKeyword &&
-			call.types[3] == monitor.TypePort {
-			foundResponseFetch = true
-			continue
-		}
-
-		if len(call.types) == 3 &&
-			call.types[0] == monitor.TypeHTTP &&
-			call.types[1] == monitor.TypeKeyword &&
-			call.types[2] == monitor.TypePort {
-			foundSSLFetch = true
-			continue
-		}
-
-		if len(call.types) == 1 && call.types[0] == monitor.TypeDomainExpiration {
-			foundDomainFetch = true
-			continue
-		}
-
-		t.Fatalf("unexpected type filter: %#v", call.types)
+	if calls[0].location != "de-1" {
+		t.Fatalf("expected location de-1, got %q", calls[0].location)
 	}
 
-	if !foundResponseFetch {
-		t.Fatalf("response fetch call missing")
-	}
-	if !foundSSLFetch {
-		t.Fatalf("ssl fetch call missing")
-	}
-	if !foundDomainFetch {
-		t.Fatalf("domain expiration fetch call missing")
+	if postedSSL := client.snapshotPostedSSL(); len(postedSSL) != 0 {
+		t.Fatalf("expected SSL check to be skipped during maintenance, got %d posted", len(postedSSL))
 	}
 
 	postedResponses := client.snapshotPostedResponses()
@@ -189,7 +203,28 @@ func TestRunMonitoringMaintenancePostsUnknown(t *testing.T) {
 	}
 }
 
-func TestRunMonitoringRequestsNonPingTypesForSSL(t *testing.T) {
+func TestRunMonitoringSkipsSummaryAfterDeadline(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{},
+		sslMonitorings:      []monitor.Monitoring{},
+	}
+	runner := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	err := runner.RunMonitoring(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded error, got %v", err)
+	}
+	if summaries := client.snapshotPostedSummaries(); len(summaries) != 0 {
+		t.Fatalf("expected no run summary after the deadline, got %d", len(summaries))
+	}
+}
+
+func TestRunMonitoringFetchesAllTypesOnce(t *testing.T) {
 	t.Parallel()
 
 	client := &fakeCoreClient{
@@ -200,45 +235,130 @@ func TestRunMonitoringRequestsNonPingTypesForSSL(t *testing.T) {
 		WebGuardLocation:    "us-1",
 		QueueDefaultWorkers: 1,
 	}
-	runner := New(client, cfg, log.New(io.Discard, "", 0))
+	runner := New(client, cfg, slog.New(slog.DiscardHandler), nil)
 
 	if err := runner.RunMonitoring(context.Background()); err != nil {
 		t.Fatalf("RunMonitoring failed: %v", err)
 	}
 
 	calls := client.snapshotCalls()
-	if len(calls) != 3 {
-		t.Fatalf("expected 3 monitoring fetch calls, got %d", len(calls))
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 monitoring fetch call, got %d", len(calls))
+	}
+	if calls[0].location != "us-1" {
+		t.Fatalf("expected location us-1, got %q", calls[0].location)
 	}
 
-	var foundSSLFetch bool
-	for _, call := range calls {
-		if call.location != "us-1" {
-			t.Fatalf("expected location us-1, got %q", call.location)
-		}
-		if len(call.types) == 4 &&
-			call.types[0] == monitor.TypeHTTP &&
-			call.types[1] == monitor.TypePing &&
-			call.types[2] == monitor.TypeKeyword &&
-			call.types[3] == monitor.TypePort {
-			continue
-		}
-		if len(call.types) == 3 &&
-			call.types[0] == monitor.TypeHTTP &&
-			call.types[1] == monitor.TypeKeyword &&
-			call.types[2] == monitor.TypePort {
-			foundSSLFetch = true
-			continue
+	expectedTypes := []monitor.Type{
+		monitor.TypeHTTP,
+		monitor.TypePing,
+		monitor.TypeICMP,
+		monitor.TypeKeyword,
+		monitor.TypePort,
+		monitor.TypeDNS,
+		monitor.TypeSMTP,
+		monitor.TypeWebSocket,
+		monitor.TypeDomainExpiration,
+	}
+	if !slices.Equal(calls[0].types, expectedTypes) {
+		t.Fatalf("unexpected type filter: %#v", calls[0].types)
+	}
+}
+
+func TestRunMonitoringSendsOneFetchRequestToCore(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu        sync.Mutex
+		fetches   int
+		typeQuery []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		if request.Method == http.MethodGet && request.URL.Path == "/api/v1/internal/monitorings" {
+			mu.Lock()
+			fetches++
+			typeQuery = append(typeQuery, request.URL.Query().Get("type"))
+			mu.Unlock()
+			_, _ = io.WriteString(writer, `[{"id":"port","type":"port","target":"127.0.0.1","port":1,"timeout":1},{"id":"site","type":"http","target":"http://127.0.0.1:1","timeout":1}]`)
+			return
 		}
-		if len(call.types) == 1 && call.types[0] == monitor.TypeDomainExpiration {
-			continue
+		_, _ = io.WriteString(writer, `{}`)
+	}))
+	t.Cleanup(server.Close)
+
+	client := core.NewClient(server.URL, "secret-key", "de-1")
+	r := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+	if err := r.RunMonitoring(context.Background()); err != nil {
+		t.Fatalf("RunMonitoring failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fetches != 1 {
+		t.Fatalf("expected 1 monitoring fetch request to Core, got %d (type queries %q)", fetches, typeQuery)
+	}
+}
+
+func TestRouteMonitoringsSplitsSingleResultSet(t *testing.T) {
+	t.Parallel()
+
+	response, ssl, domain := routeMonitorings([]monitor.Monitoring{
+		{ID: "http", Type: monitor.TypeHTTP},
+		{ID: "ping", Type: monitor.TypePing},
+		{ID: "keyword", Type: monitor.TypeKeyword},
+		{ID: "port", Type: monitor.TypePort},
+		{ID: "dns", Type: monitor.TypeDNS},
+		{ID: "domain", Type: monitor.TypeDomainExpiration},
+	})
+
+	ids := func(monitorings []monitor.Monitoring) []string {
+		result := make([]string, 0, len(monitorings))
+		for _, monitoring := range monitorings {
+			result = append(result, monitoring.ID)
 		}
+		return result
+	}
+
+	if got := ids(response); !slices.Equal(got, []string{"http", "ping", "keyword", "port", "dns"}) {
+		t.Fatalf("unexpected response monitorings: %v", got)
+	}
+	if got := ids(ssl); !slices.Equal(got, []string{"http", "keyword", "port"}) {
+		t.Fatalf("unexpected ssl monitorings: %v", got)
+	}
+	if got := ids(domain); !slices.Equal(got, []string{"domain"}) {
+		t.Fatalf("unexpected domain monitorings: %v", got)
+	}
+}
+
+func TestRunMonitoringRoutesSharedMonitoringToResponseAndSSL(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{ID: "port-monitoring", Type: monitor.TypePort},
+		},
+	}
+	cfg := config.Config{
+		WebGuardLocation:    "de-1",
+		QueueDefaultWorkers: 1,
+	}
+	runner := New(client, cfg, slog.New(slog.DiscardHandler), nil)
 
-		t.Fatalf("unexpected type filter: %#v", call.types)
+	if err := runner.RunMonitoring(context.Background()); err != nil {
+		t.Fatalf("RunMonitoring failed: %v", err)
 	}
 
-	if !foundSSLFetch {
-		t.Fatalf("ssl types fetch missing")
+	if calls := client.snapshotCalls(); len(calls) != 1 {
+		t.Fatalf("expected 1 monitoring fetch call, got %d", len(calls))
+	}
+	postedResponses := client.snapshotPostedResponses()
+	if len(postedResponses) != 1 || postedResponses[0].MonitoringID != "port-monitoring" {
+		t.Fatalf("expected response result for port-monitoring, got %#v", postedResponses)
+	}
+	postedSSL := client.snapshotPostedSSL()
+	if len(postedSSL) != 1 || postedSSL[0].MonitoringID != "port-monitoring" {
+		t.Fatalf("expected SSL result for port-monitoring, got %#v", postedSSL)
 	}
 }
 
@@ -265,7 +385,7 @@ func TestRunMonitoringSkipsHeartbeatMonitoringsWithoutPostingResults(t *testing.
 		WebGuardLocation:    "de-1",
 		QueueDefaultWorkers: 1,
 	}
-	runner := New(client, cfg, log.New(&logs, "", 0))
+	runner := New(client, cfg, slog.New(slog.NewTextHandler(&logs, nil)), nil)
 
 	if err := runner.RunMonitoring(context.Background()); err != nil {
 		t.Fatalf("RunMonitoring failed: %v", err)
@@ -279,11 +399,14 @@ func TestRunMonitoringSkipsHeartbeatMonitoringsWithoutPostingResults(t *testing.
 	}
 
 	logOutput := logs.String()
-	if !strings.Contains(logOutput, "Skipping passive/unsupported response monitoring (monitoring_id=hb-response type=heartbeat)") {
+	if !strings.Contains(logOutput, `msg="Skipping passive/unsupported response monitoring" monitoring_id=hb-response type=heartbeat`) {
 		t.Fatalf("expected response skip log, got %q", logOutput)
 	}
-	if !strings.Contains(logOutput, "Skipping passive/unsupported SSL monitoring (monitoring_id=hb-ssl type=heartbeat)") {
-		t.Fatalf("expected ssl skip log, got %q", logOutput)
+	if !strings.Contains(logOutput, `msg="Skipping passive/unsupported response monitoring" monitoring_id=hb-ssl type=heartbeat`) {
+		t.Fatalf("expected ssl heartbeat to be skipped by the response phase, got %q", logOutput)
+	}
+	if strings.Contains(logOutput, "Skipping passive/unsupported SSL monitoring") {
+		t.Fatalf("expected heartbeat not to be routed to the SSL phase, got %q", logOutput)
 	}
 }
 
@@ -320,7 +443,7 @@ func TestRunResponsePostsHTTPStatusCodeForHTTPAndKeywordMonitoring(t *testing.T)
 		WebGuardLocation:    "de-1",
 		QueueDefaultWorkers: 1,
 	}
-	runner := New(client, cfg, log.New(io.Discard, "", 0))
+	runner := New(client, cfg, slog.New(slog.DiscardHandler), nil)
 
 	if err := runner.runResponse(context.Background()); err != nil {
 		t.Fatalf("runResponse failed: %v", err)
@@ -347,6 +470,112 @@ func TestRunResponsePostsHTTPStatusCodeForHTTPAndKeywordMonitoring(t *testing.T)
 		if *payload.HTTPStatusCode != http.StatusCreated {
 			t.Fatalf("expected http_status_code=%d for %s, got %d", http.StatusCreated, monitoringID, *payload.HTTPStatusCode)
 		}
+		if payload.ConnectMs == nil || payload.TTFBMs == nil {
+			t.Fatalf("expected connect and ttfb timings for %s", monitoringID)
+		}
+	}
+}
+
+func TestRunResponseJSONLogsContainStructuredFields(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{
+				ID:         "json-monitoring",
+				Type:       monitor.TypeHTTP,
+				Target:     server.URL,
+				Timeout:    2,
+				HTTPMethod: monitor.HTTPMethodGet,
+			},
+		},
+	}
+
+	var logs bytes.Buffer
+	cfg := config.Config{
+		WebGuardLocation:    "de-1",
+		QueueDefaultWorkers: 1,
+	}
+	runner := New(client, cfg, logging.New(&logs, logging.FormatJSON, ""), nil)
+
+	if err := runner.runResponse(context.Background()); err != nil {
+		t.Fatalf("runResponse failed: %v", err)
+	}
+
+	entries := make(map[string]map[string]any)
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("expected JSON log line, got %q: %v", line, err)
+		}
+		message, _ := entry["msg"].(string)
+		entries[message] = entry
+	}
+
+	result, ok := entries["Response monitoring result computed"]
+	if !ok {
+		t.Fatalf("expected result log entry, got %q", logs.String())
+	}
+	for _, key := range []string{"monitoring_id", "type", "status", "response_time", "http_status_code", "duration_ms"} {
+		if _, ok := result[key]; !ok {
+			t.Fatalf("expected key %q in result log entry %#v", key, result)
+		}
+	}
+	if result["monitoring_id"] != "json-monitoring" || result["status"] != string(monitor.StatusUp) {
+		t.Fatalf("unexpected result log entry: %#v", result)
+	}
+
+	summary, ok := entries["Response monitoring dispatch done"]
+	if !ok {
+		t.Fatalf("expected dispatch summary log entry, got %q", logs.String())
+	}
+	for _, key := range []string{"total", "dispatched", "skipped_maintenance", "skipped_unsupported", "duration_ms"} {
+		if _, ok := summary[key]; !ok {
+			t.Fatalf("expected key %q in summary log entry %#v", key, summary)
+		}
+	}
+}
+
+func TestRunResponseWarnLevelSuppressesRoutineLogs(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &failingPostClient{fakeCoreClient: fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{ID: "quiet", Type: monitor.TypeHTTP, Target: server.URL, Timeout: 2, HTTPMethod: monitor.HTTPMethodGet},
+			{ID: "passive", Type: monitor.TypeHeartbeat},
+		},
+	}}
+
+	var logs bytes.Buffer
+	runner := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1}, logging.New(&logs, logging.FormatText, "warn"), nil)
+
+	if err := runner.runResponse(context.Background()); err != nil {
+		t.Fatalf("runResponse failed: %v", err)
+	}
+
+	output := logs.String()
+	for _, message := range []string{
+		"Dispatching response monitoring jobs",
+		"Skipping passive/unsupported response monitoring",
+		"Response monitoring result computed",
+		"Response monitoring dispatch done",
+	} {
+		if strings.Contains(output, message) {
+			t.Fatalf("expected %q to be suppressed at warn level, got %q", message, output)
+		}
+	}
+	if !strings.Contains(output, "level=ERROR") || !strings.Contains(output, "Failed to post response result") {
+		t.Fatalf("expected the post failure to be logged at error level, got %q", output)
 	}
 }
 
@@ -355,24 +584,36 @@ type parallelPhasesClient struct {
 	release chan struct{}
 }
 
-func (p *parallelPhasesClient) GetMonitorings(_ context.Context, _ string, types []monitor.Type) ([]monitor.Monitoring, error) {
-	phase := "ssl"
-	if len(types) == len(responseMonitoringTypes) {
-		phase = "response"
-	}
-	if len(types) == len(domainExpirationMonitoringTypes) && types[0] == monitor.TypeDomainExpiration {
-		phase = "domain"
-	}
+func (p *parallelPhasesClient) GetMonitorings(_ context.Context, _ string, _ []monitor.Type) ([]monitor.Monitoring, error) {
+	return []monitor.Monitoring{
+		{ID: "response", Type: monitor.TypePing, MaintenanceActive: true},
+		{ID: "ssl", Type: monitor.TypePort},
+		{ID: "domain", Type: monitor.TypeDomainExpiration, MaintenanceActive: true},
+	}, nil
+}
+
+func (p *parallelPhasesClient) block(phase string) {
 	p.started <- phase
 	<-p.release
-	return []monitor.Monitoring{}, nil
 }
 
-func (p *parallelPhasesClient) PostMonitoringResponse(_ context.Context, _ monitor.MonitoringResponsePayload) error {
+func (p *parallelPhasesClient) PostMonitoringResponse(_ context.Context, payload monitor.MonitoringResponsePayload) error {
+	if payload.MonitoringID == "response" || payload.MonitoringID == "domain" {
+		p.block(payload.MonitoringID)
+	}
 	return nil
 }
 
 func (p *parallelPhasesClient) PostSSLResult(_ context.Context, _ monitor.SSLResultPayload) error {
+	p.block("ssl")
+	return nil
+}
+
+func (p *parallelPhasesClient) PostMonitoringResponses(_ context.Context, _ []monitor.MonitoringResponsePayload) error {
+	return nil
+}
+
+func (p *parallelPhasesClient) PostSSLResults(_ context.Context, _ []monitor.SSLResultPayload) error {
 	return nil
 }
 
@@ -380,6 +621,10 @@ func (p *parallelPhasesClient) PostDomainResult(_ context.Context, _ monitor.Dom
 	return nil
 }
 
+func (p *parallelPhasesClient) PostRunSummary(_ context.Context, _ monitor.RunSummaryPayload) error {
+	return nil
+}
+
 func TestRunMonitoringRunsPhasesInParallel(t *testing.T) {
 	t.Parallel()
 
@@ -392,7 +637,7 @@ func TestRunMonitoringRunsPhasesInParallel(t *testing.T) {
 		WebGuardLocation:    "de-1",
 		QueueDefaultWorkers: 1,
 	}
-	runner := New(client, cfg, log.New(io.Discard, "", 0))
+	runner := New(client, cfg, slog.New(slog.DiscardHandler), nil)
 
 	done := make(chan struct{})
 	go func() {
@@ -419,3 +664,1451 @@ func TestRunMonitoringRunsPhasesInParallel(t *testing.T) {
 		t.Fatalf("RunMonitoring did not finish after releasing blocked phases")
 	}
 }
+
+func TestRunMonitoringExposesCheckMetrics(t *testing.T) {
+	t.Parallel()
+
+	target := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("haystack"))
+	}))
+	defer target.Close()
+
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{
+				ID:         "http-monitoring",
+				Type:       monitor.TypeHTTP,
+				Target:     target.URL,
+				Timeout:    2,
+				HTTPMethod: monitor.HTTPMethodGet,
+			},
+			{
+				ID:         "keyword-monitoring",
+				Type:       monitor.TypeKeyword,
+				Target:     target.URL,
+				Timeout:    2,
+				HTTPMethod: monitor.HTTPMethodGet,
+				Keyword:    "needle",
+			},
+		},
+	}
+
+	cfg := config.Config{
+		WebGuardLocation:    "de-1",
+		QueueDefaultWorkers: 1,
+	}
+	registry := metrics.NewRegistry()
+	runner := New(client, cfg, slog.New(slog.DiscardHandler), registry)
+
+	if err := runner.RunMonitoring(context.Background()); err != nil {
+		t.Fatalf("RunMonitoring failed: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.Handler(nil, server.BuildInfo{}, registry, nil, slog.New(slog.DiscardHandler)).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+
+	body := recorder.Body.String()
+	for _, line := range []string{
+		`webguard_monitoring_checks_total{status="up",type="http"} 1`,
+		`webguard_monitoring_checks_total{status="down",type="keyword"} 1`,
+		`webguard_check_duration_seconds_count{type="http"} 1`,
+		`webguard_check_duration_seconds_count{type="keyword"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("expected %q in metrics output:\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, "webguard_last_run_timestamp_seconds 0\n") {
+		t.Fatalf("expected last run timestamp to be set:\n%s", body)
+	}
+}
+
+func TestShutdownWaitsForActiveRun(t *testing.T) {
+	t.Parallel()
+
+	client := &parallelPhasesClient{
+		started: make(chan string, 3),
+		release: make(chan struct{}),
+	}
+	runner := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+
+	runDone := make(chan struct{})
+	go func() {
+		_ = runner.RunMonitoring(context.Background())
+		close(runDone)
+	}()
+	for range 3 {
+		<-client.started
+	}
+
+	shutdownDone := make(chan error, 1)
+	go func() {
+		shutdownDone <- runner.Shutdown(context.Background())
+	}()
+
+	select {
+	case err := <-shutdownDone:
+		t.Fatalf("expected Shutdown to wait for the active run, returned %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(client.release)
+
+	select {
+	case err := <-shutdownDone:
+		if err != nil {
+			t.Fatalf("expected clean drain, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected Shutdown to return after the run completed")
+	}
+	<-runDone
+}
+
+func TestShutdownReturnsWhenDeadlineElapses(t *testing.T) {
+	t.Parallel()
+
+	client := &parallelPhasesClient{
+		started: make(chan string, 3),
+		release: make(chan struct{}),
+	}
+	defer close(client.release)
+	runner := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+
+	go func() {
+		_ = runner.RunMonitoring(context.Background())
+	}()
+	for range 3 {
+		<-client.started
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := runner.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRunMonitoringRejectedAfterShutdown(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeCoreClient{}
+	runner := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+
+	if err := runner.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected idle Shutdown to return immediately, got %v", err)
+	}
+	if err := runner.RunMonitoring(context.Background()); !errors.Is(err, errRunnerShuttingDown) {
+		t.Fatalf("expected shutting down error, got %v", err)
+	}
+	if calls := client.snapshotCalls(); len(calls) != 0 {
+		t.Fatalf("expected no Core API calls after shutdown, got %d", len(calls))
+	}
+}
+
+type fetchFailingClient struct {
+	fakeCoreClient
+}
+
+func (c *fetchFailingClient) GetMonitorings(context.Context, string, []monitor.Type) ([]monitor.Monitoring, error) {
+	return nil, errors.New("core unavailable")
+}
+
+func TestRunMonitoringReturnsFetchError(t *testing.T) {
+	t.Parallel()
+
+	client := &fetchFailingClient{}
+	runner := New(client, config.Config{WebGuardLocation: "de-1"}, slog.New(slog.DiscardHandler), nil)
+
+	err := runner.RunMonitoring(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "core unavailable") {
+		t.Fatalf("expected the fetch error to be returned, got %v", err)
+	}
+	if got := len(client.snapshotPostedSummaries()); got != 0 {
+		t.Fatalf("expected no run summary after a failed fetch, got %d", got)
+	}
+}
+
+func TestRunMonitoringReturnsErrorWhenCoreIsUnreachable(t *testing.T) {
+	t.Parallel()
+
+	coreServer := httptest.NewServer(http.NotFoundHandler())
+	coreURL := coreServer.URL
+	coreServer.Close()
+
+	client := core.NewClient(coreURL, "secret-key", "de-1")
+	client.SetRetry(0, 0)
+	runner := New(client, config.Config{WebGuardLocation: "de-1"}, slog.New(slog.DiscardHandler), nil)
+
+	if err := runner.RunMonitoring(context.Background()); err == nil {
+		t.Fatalf("expected an error when Core is unreachable")
+	}
+	if err := runner.RunMonitoringType(context.Background(), string(monitor.TypeHTTP)); err == nil {
+		t.Fatalf("expected an error for a single type when Core is unreachable")
+	}
+}
+
+func TestRunMonitoringReturnsPhaseErrors(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+		<-request.Context().Done()
+	}))
+	defer server.Close()
+
+	client := &fakeCoreClient{}
+	for i := 0; i < 3; i++ {
+		client.responseMonitorings = append(client.responseMonitorings, monitor.Monitoring{
+			ID:         "hanging-" + strconv.Itoa(i),
+			Type:       monitor.TypeHTTP,
+			Target:     server.URL,
+			HTTPMethod: monitor.HTTPMethodGet,
+		})
+	}
+
+	r := New(client, config.Config{
+		WebGuardLocation:    "de-1",
+		QueueDefaultWorkers: 1,
+		PhaseTimeoutSeconds: 1,
+	}, slog.New(slog.DiscardHandler), nil)
+
+	err := r.RunMonitoring(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "response phase") {
+		t.Fatalf("expected the response phase deadline error, got %v", err)
+	}
+	if got := len(client.snapshotPostedSummaries()); got != 1 {
+		t.Fatalf("expected the run summary to be posted despite the phase error, got %d", got)
+	}
+}
+
+func TestRunMonitoringSkippedWhilePaused(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{ID: "maintenance", Type: monitor.TypeHTTP, MaintenanceActive: true},
+		},
+	}
+	runner := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+
+	runner.Pause()
+	if !runner.Paused() {
+		t.Fatalf("expected runner to be paused")
+	}
+	if err := runner.RunMonitoring(context.Background()); err != nil {
+		t.Fatalf("expected paused run to be skipped without error, got %v", err)
+	}
+	if calls := client.snapshotCalls(); len(calls) != 0 {
+		t.Fatalf("expected no Core API calls while paused, got %d", len(calls))
+	}
+
+	runner.Resume()
+	if runner.Paused() {
+		t.Fatalf("expected runner to be resumed")
+	}
+	if err := runner.RunMonitoring(context.Background()); err != nil {
+		t.Fatalf("RunMonitoring failed: %v", err)
+	}
+	if got := len(client.snapshotPostedResponses()); got != 1 {
+		t.Fatalf("expected one posted response after resuming, got %d", got)
+	}
+}
+
+func TestRunMonitoringTypeFetchesOnlyRequestedPhase(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		expectedTypes []monitor.Type
+	}{
+		{name: "http", expectedTypes: []monitor.Type{monitor.TypeHTTP}},
+		{name: "port", expectedTypes: []monitor.Type{monitor.TypePort}},
+		{name: "ssl", expectedTypes: sslMonitoringTypes},
+		{name: "domain_expiration", expectedTypes: domainExpirationMonitoringTypes},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &fakeCoreClient{}
+			runner := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+
+			if err := runner.RunMonitoringType(context.Background(), testCase.name); err != nil {
+				t.Fatalf("RunMonitoringType failed: %v", err)
+			}
+
+			calls := client.snapshotCalls()
+			if len(calls) != 1 {
+				t.Fatalf("expected 1 Core API call, got %d", len(calls))
+			}
+			if !slices.Equal(calls[0].types, testCase.expectedTypes) {
+				t.Fatalf("expected types %v, got %v", testCase.expectedTypes, calls[0].types)
+			}
+		})
+	}
+}
+
+func TestRunMonitoringTypeRejectsUnknownType(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeCoreClient{}
+	runner := New(client, config.Config{WebGuardLocation: "de-1"}, slog.New(slog.DiscardHandler), nil)
+
+	if err := runner.RunMonitoringType(context.Background(), "heartbeat"); err == nil {
+		t.Fatalf("expected error for unsupported type")
+	}
+	if calls := client.snapshotCalls(); len(calls) != 0 {
+		t.Fatalf("expected no Core API calls, got %d", len(calls))
+	}
+}
+
+func TestRunMonitoringDryRunLogsPayloadsWithoutPosting(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	expiresAt := time.Now().Add(90 * 24 * time.Hour).UTC().Truncate(time.Second)
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{
+				ID:         "dry-http",
+				Type:       monitor.TypeHTTP,
+				Target:     server.URL,
+				Timeout:    2,
+				HTTPMethod: monitor.HTTPMethodGet,
+			},
+			{
+				ID:                "dry-maintenance",
+				Type:              monitor.TypeHTTP,
+				Target:            server.URL,
+				MaintenanceActive: true,
+			},
+		},
+		domainMonitorings: []monitor.Monitoring{
+			{
+				ID:     "dry-domain",
+				Type:   monitor.TypeDomainExpiration,
+				Target: "example.com",
+			},
+		},
+	}
+
+	var logs bytes.Buffer
+	cfg := config.Config{
+		WebGuardLocation:    "de-1",
+		QueueDefaultWorkers: 2,
+		DryRun:              true,
+	}
+	runner := New(client, cfg, logging.New(&logs, logging.FormatJSON, ""), nil)
+	runner.domainLookup = staticDomainLookup{
+		result: domainlookup.Result{
+			Domain:     "example.com",
+			Registered: true,
+			ExpiresAt:  &expiresAt,
+			CheckedAt:  time.Now(),
+		},
+	}
+
+	if err := runner.RunMonitoring(context.Background()); err != nil {
+		t.Fatalf("RunMonitoring failed: %v", err)
+	}
+
+	if posted := client.snapshotPostedResponses(); len(posted) != 0 {
+		t.Fatalf("expected no posted responses, got %d", len(posted))
+	}
+	if posted := client.snapshotPostedSSL(); len(posted) != 0 {
+		t.Fatalf("expected no posted SSL results, got %d", len(posted))
+	}
+	if posted := client.snapshotPostedDomains(); len(posted) != 0 {
+		t.Fatalf("expected no posted domain results, got %d", len(posted))
+	}
+
+	logged := make(map[string]map[string]any)
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("expected JSON log line, got %q: %v", line, err)
+		}
+		if entry["msg"] != "Dry run: skipping result post" {
+			continue
+		}
+		if entry["level"] != "INFO" {
+			t.Fatalf("expected info level, got %v", entry["level"])
+		}
+		encoded, _ := entry["payload"].(string)
+		var payload map[string]any
+		if err := json.Unmarshal([]byte(encoded), &payload); err != nil {
+			t.Fatalf("expected JSON payload, got %q: %v", encoded, err)
+		}
+		logged[entry["kind"].(string)+"/"+entry["monitoring_id"].(string)] = payload
+	}
+
+	expected := map[string]string{
+		"monitoring_response/dry-http":        "up",
+		"monitoring_response/dry-maintenance": "unknown",
+		"monitoring_response/dry-domain":      "up",
+	}
+	for key, status := range expected {
+		payload, ok := logged[key]
+		if !ok {
+			t.Fatalf("expected dry run log for %s, got %v", key, logged)
+		}
+		if payload["status"] != status {
+			t.Fatalf("expected %s status %s, got %v", key, status, payload["status"])
+		}
+	}
+	if payload, ok := logged["ssl_result/dry-http"]; !ok || payload["monitoring_id"] != "dry-http" {
+		t.Fatalf("expected dry run SSL payload for dry-http, got %v", logged)
+	}
+	if payload, ok := logged["domain_result/dry-domain"]; !ok || payload["is_valid"] != true {
+		t.Fatalf("expected valid dry run domain payload for dry-domain, got %v", logged)
+	}
+}
+
+type concurrencyRecordingClient struct {
+	fakeCoreClient
+
+	mu       sync.Mutex
+	inFlight map[string]int
+	peak     map[string]int
+}
+
+func (c *concurrencyRecordingClient) record(phase string) {
+	c.mu.Lock()
+	c.inFlight[phase]++
+	c.peak[phase] = max(c.peak[phase], c.inFlight[phase])
+	c.mu.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+
+	c.mu.Lock()
+	c.inFlight[phase]--
+	c.mu.Unlock()
+}
+
+func (c *concurrencyRecordingClient) PostMonitoringResponse(_ context.Context, _ monitor.MonitoringResponsePayload) error {
+	c.record("response")
+	return nil
+}
+
+func (c *concurrencyRecordingClient) PostSSLResult(_ context.Context, _ monitor.SSLResultPayload) error {
+	c.record("ssl")
+	return nil
+}
+
+func (c *concurrencyRecordingClient) peakFor(phase string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.peak[phase]
+}
+
+func TestDispatchUsesPhaseWorkerCounts(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		cfg              config.Config
+		expectedResponse int
+		expectedSSL      int
+	}{
+		{name: "default applies to both phases", cfg: config.Config{QueueDefaultWorkers: 2}, expectedResponse: 2, expectedSSL: 2},
+		{name: "response override", cfg: config.Config{QueueDefaultWorkers: 2, QueueResponseWorkers: 5}, expectedResponse: 5, expectedSSL: 2},
+		{name: "ssl override", cfg: config.Config{QueueDefaultWorkers: 2, QueueSSLWorkers: 1}, expectedResponse: 2, expectedSSL: 1},
+		{name: "unset default falls back to one worker", cfg: config.Config{QueueSSLWorkers: 3}, expectedResponse: 1, expectedSSL: 3},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			monitorings := make([]monitor.Monitoring, 0, 8)
+			for i := 0; i < 8; i++ {
+				monitorings = append(monitorings, monitor.Monitoring{
+					ID:     "port-" + strconv.Itoa(i),
+					Type:   monitor.TypePort,
+					Target: "127.0.0.1",
+				})
+			}
+
+			client := &concurrencyRecordingClient{inFlight: map[string]int{}, peak: map[string]int{}}
+			r := New(client, testCase.cfg, slog.New(slog.DiscardHandler), nil)
+
+			if err := r.dispatchResponse(context.Background(), monitorings, nil); err != nil {
+				t.Fatalf("dispatchResponse failed: %v", err)
+			}
+			if err := r.dispatchSSL(context.Background(), monitorings, nil); err != nil {
+				t.Fatalf("dispatchSSL failed: %v", err)
+			}
+
+			if got := client.peakFor("response"); got != testCase.expectedResponse {
+				t.Fatalf("expected %d response workers, got %d", testCase.expectedResponse, got)
+			}
+			if got := client.peakFor("ssl"); got != testCase.expectedSSL {
+				t.Fatalf("expected %d SSL workers, got %d", testCase.expectedSSL, got)
+			}
+		})
+	}
+}
+
+func TestDispatchResponseStopsAtPhaseTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+		<-request.Context().Done()
+	}))
+	defer server.Close()
+
+	monitorings := make([]monitor.Monitoring, 0, 3)
+	for i := 0; i < 3; i++ {
+		monitorings = append(monitorings, monitor.Monitoring{
+			ID:         "hanging-" + strconv.Itoa(i),
+			Type:       monitor.TypeHTTP,
+			Target:     server.URL,
+			HTTPMethod: monitor.HTTPMethodGet,
+		})
+	}
+
+	client := &fakeCoreClient{}
+	r := New(client, config.Config{
+		QueueDefaultWorkers: 1,
+		PhaseTimeoutSeconds: 1,
+	}, slog.New(slog.DiscardHandler), nil)
+
+	start := time.Now()
+	err := r.dispatchResponse(context.Background(), monitorings, nil)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected phase deadline error, got %v", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Fatalf("expected phase to stop after about 1s, took %s", elapsed)
+	}
+	if posted := client.snapshotPostedResponses(); len(posted) != 0 {
+		t.Fatalf("expected aborted checks not to be posted, got %+v", posted)
+	}
+}
+
+func TestDispatchResponseIgnoresParentCancellation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := New(&fakeCoreClient{}, config.Config{PhaseTimeoutSeconds: 1}, slog.New(slog.DiscardHandler), nil)
+	err := r.dispatchResponse(ctx, []monitor.Monitoring{{ID: "port-1", Type: monitor.TypePort, Target: "127.0.0.1"}}, nil)
+	if err != nil {
+		t.Fatalf("expected no phase error on shutdown, got %v", err)
+	}
+}
+
+func TestDispatchResponsePostsPortFailureReason(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to open listener: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+
+	client := &fakeCoreClient{}
+	r := New(client, config.Config{QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+	if err := r.dispatchResponse(context.Background(), []monitor.Monitoring{
+		{ID: "port-closed", Type: monitor.TypePort, Target: "127.0.0.1", Port: port},
+	}, nil); err != nil {
+		t.Fatalf("dispatchResponse failed: %v", err)
+	}
+
+	posted := client.snapshotPostedResponses()
+	if len(posted) != 1 {
+		t.Fatalf("expected one posted response, got %d", len(posted))
+	}
+	if posted[0].FailureReason == nil || *posted[0].FailureReason != monitor.PortFailureConnectionRefused {
+		t.Fatalf("expected failure reason %s, got %v", monitor.PortFailureConnectionRefused, pointerStringValue(posted[0].FailureReason))
+	}
+	if posted[0].ResponseTime == nil {
+		t.Fatalf("expected handshake latency to be posted for the refused port")
+	}
+}
+
+func TestDispatchResponsePostsResolvedIP(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	client := &fakeCoreClient{}
+	r := New(client, config.Config{QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+	if err := r.dispatchResponse(context.Background(), []monitor.Monitoring{
+		{ID: "http-loopback", Type: monitor.TypeHTTP, Target: server.URL, Timeout: 2, HTTPMethod: monitor.HTTPMethodGet},
+	}, nil); err != nil {
+		t.Fatalf("dispatchResponse failed: %v", err)
+	}
+
+	posted := client.snapshotPostedResponses()
+	if len(posted) != 1 {
+		t.Fatalf("expected one posted response, got %d", len(posted))
+	}
+	if posted[0].ResolvedIP == nil || *posted[0].ResolvedIP != "127.0.0.1" {
+		t.Fatalf("expected resolved ip 127.0.0.1, got %v", pointerStringValue(posted[0].ResolvedIP))
+	}
+}
+
+func TestDispatchResponsePostsFinalURLAfterRedirects(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.Handle("/start", http.RedirectHandler("/next", http.StatusFound))
+	mux.Handle("/next", http.RedirectHandler("/landing?from=next", http.StatusMovedPermanently))
+	mux.HandleFunc("/landing", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := &fakeCoreClient{}
+	r := New(client, config.Config{QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+	if err := r.dispatchResponse(context.Background(), []monitor.Monitoring{
+		{ID: "redirected", Type: monitor.TypeHTTP, Target: server.URL + "/start", Timeout: 2, HTTPMethod: monitor.HTTPMethodGet},
+		{ID: "direct", Type: monitor.TypeHTTP, Target: server.URL + "/landing", Timeout: 2, HTTPMethod: monitor.HTTPMethodGet},
+	}, nil); err != nil {
+		t.Fatalf("dispatchResponse failed: %v", err)
+	}
+
+	posted := make(map[string]monitor.MonitoringResponsePayload)
+	for _, payload := range client.snapshotPostedResponses() {
+		posted[payload.MonitoringID] = payload
+	}
+
+	redirected := posted["redirected"]
+	if redirected.FinalURL == nil || *redirected.FinalURL != server.URL+"/landing?from=next" {
+		t.Fatalf("expected final url %s/landing?from=next, got %v", server.URL, pointerStringValue(redirected.FinalURL))
+	}
+	if redirected.RedirectCount == nil || *redirected.RedirectCount != 2 {
+		t.Fatalf("expected 2 redirects, got %v", pointerIntValue(redirected.RedirectCount))
+	}
+
+	direct := posted["direct"]
+	if direct.FinalURL == nil || *direct.FinalURL != server.URL+"/landing" {
+		t.Fatalf("expected final url %s/landing, got %v", server.URL, pointerStringValue(direct.FinalURL))
+	}
+	if direct.RedirectCount == nil || *direct.RedirectCount != 0 {
+		t.Fatalf("expected no redirects, got %v", pointerIntValue(direct.RedirectCount))
+	}
+}
+
+func TestDispatchResponseReportsSchemeDowngrade(t *testing.T) {
+	t.Parallel()
+
+	plain := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(plain.Close)
+
+	secureMux := http.NewServeMux()
+	secureMux.Handle("/downgrade", http.RedirectHandler(plain.URL+"/landing", http.StatusMovedPermanently))
+	secureMux.Handle("/stay", http.RedirectHandler("/landing", http.StatusFound))
+	secureMux.HandleFunc("/landing", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	})
+	secure := httptest.NewUnstartedServer(secureMux)
+	secure.Config.ErrorLog = log.New(io.Discard, "", 0)
+	secure.StartTLS()
+	t.Cleanup(secure.Close)
+
+	client := &fakeCoreClient{}
+	r := New(client, config.Config{QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+	if err := r.dispatchResponse(context.Background(), []monitor.Monitoring{
+		{ID: "downgraded", Type: monitor.TypeHTTP, Target: secure.URL + "/downgrade", Timeout: 2, HTTPMethod: monitor.HTTPMethodGet},
+		{ID: "strict", Type: monitor.TypeHTTP, Target: secure.URL + "/downgrade", Timeout: 2, HTTPMethod: monitor.HTTPMethodGet, FailOnSchemeDowngrade: true},
+		{ID: "secure", Type: monitor.TypeHTTP, Target: secure.URL + "/stay", Timeout: 2, HTTPMethod: monitor.HTTPMethodGet, FailOnSchemeDowngrade: true},
+	}, nil); err != nil {
+		t.Fatalf("dispatchResponse failed: %v", err)
+	}
+
+	posted := make(map[string]monitor.MonitoringResponsePayload)
+	for _, payload := range client.snapshotPostedResponses() {
+		posted[payload.MonitoringID] = payload
+	}
+
+	downgraded := posted["downgraded"]
+	if downgraded.Status != monitor.StatusUp || !downgraded.SchemeDowngraded {
+		t.Fatalf("expected an up result flagged as downgraded, got status %s downgraded %v", downgraded.Status, downgraded.SchemeDowngraded)
+	}
+
+	strict := posted["strict"]
+	if strict.Status != monitor.StatusDown {
+		t.Fatalf("expected strict downgrade to report down, got %s", strict.Status)
+	}
+	if strict.FailureReason == nil || *strict.FailureReason != monitor.HTTPFailureSchemeDowngraded {
+		t.Fatalf("expected failure reason %s, got %v", monitor.HTTPFailureSchemeDowngraded, pointerStringValue(strict.FailureReason))
+	}
+
+	secureResult := posted["secure"]
+	if secureResult.Status != monitor.StatusUp || secureResult.SchemeDowngraded {
+		t.Fatalf("expected an https-only redirect to stay up without a downgrade, got status %s downgraded %v", secureResult.Status, secureResult.SchemeDowngraded)
+	}
+}
+
+func TestDispatchResponseReportsDegradedStatus(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		if request.URL.Path == "/broken" {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	client := &fakeCoreClient{}
+	r := New(client, config.Config{QueueDefaultWorkers: 4}, slog.New(slog.DiscardHandler), nil)
+	run := r.newRunState()
+	if err := r.dispatchResponse(context.Background(), []monitor.Monitoring{
+		{ID: "crossed", Type: monitor.TypeHTTP, Target: server.URL, Timeout: 2, HTTPMethod: monitor.HTTPMethodGet, DegradedThresholdMs: 20},
+		{ID: "within", Type: monitor.TypeHTTP, Target: server.URL, Timeout: 2, HTTPMethod: monitor.HTTPMethodGet, DegradedThresholdMs: 5000},
+		{ID: "unset", Type: monitor.TypeHTTP, Target: server.URL, Timeout: 2, HTTPMethod: monitor.HTTPMethodGet},
+		{ID: "down", Type: monitor.TypeHTTP, Target: server.URL + "/broken", Timeout: 2, HTTPMethod: monitor.HTTPMethodGet, DegradedThresholdMs: 20},
+	}, run); err != nil {
+		t.Fatalf("dispatchResponse failed: %v", err)
+	}
+
+	expected := map[string]monitor.Status{
+		"crossed": monitor.StatusDegraded,
+		"within":  monitor.StatusUp,
+		"unset":   monitor.StatusUp,
+		"down":    monitor.StatusDown,
+	}
+	posted := client.snapshotPostedResponses()
+	if len(posted) != len(expected) {
+		t.Fatalf("expected %d posted responses, got %d", len(expected), len(posted))
+	}
+	for _, payload := range posted {
+		if payload.Status != expected[payload.MonitoringID] {
+			t.Fatalf("expected %s for %s, got %s", expected[payload.MonitoringID], payload.MonitoringID, payload.Status)
+		}
+	}
+
+	summary := run.summary(time.Now(), 0)
+	if summary.Up != 2 || summary.Degraded != 1 || summary.Down != 1 || summary.Unknown != 0 {
+		t.Fatalf("expected 2 up, 1 degraded and 1 down in the summary, got %+v", summary)
+	}
+}
+
+func TestDispatchResponseRecordsHopStatusCodes(t *testing.T) {
+	t.Parallel()
+
+	origin := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(origin.Close)
+
+	edgeMux := http.NewServeMux()
+	edgeMux.Handle("/edge", http.RedirectHandler("/cdn", http.StatusFound))
+	edgeMux.Handle("/cdn", http.RedirectHandler(origin.URL+"/origin", http.StatusMovedPermanently))
+	edge := httptest.NewServer(edgeMux)
+	t.Cleanup(edge.Close)
+
+	client := &fakeCoreClient{}
+	r := New(client, config.Config{QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+	if err := r.dispatchResponse(context.Background(), []monitor.Monitoring{
+		{ID: "hops", Type: monitor.TypeHTTP, Target: edge.URL + "/edge", Timeout: 2, HTTPMethod: monitor.HTTPMethodGet, RecordHopStatuses: true},
+		{ID: "plain", Type: monitor.TypeHTTP, Target: edge.URL + "/edge", Timeout: 2, HTTPMethod: monitor.HTTPMethodGet},
+	}, nil); err != nil {
+		t.Fatalf("dispatchResponse failed: %v", err)
+	}
+
+	posted := make(map[string]monitor.MonitoringResponsePayload)
+	for _, payload := range client.snapshotPostedResponses() {
+		posted[payload.MonitoringID] = payload
+	}
+
+	hops := posted["hops"]
+	expectedHops := []int{http.StatusFound, http.StatusMovedPermanently, http.StatusServiceUnavailable}
+	if !slices.Equal(hops.HopStatusCodes, expectedHops) {
+		t.Fatalf("expected hop status codes %v, got %v", expectedHops, hops.HopStatusCodes)
+	}
+	if hops.FirstHopStatusCode == nil || *hops.FirstHopStatusCode != http.StatusFound {
+		t.Fatalf("expected first hop status %d, got %v", http.StatusFound, pointerIntValue(hops.FirstHopStatusCode))
+	}
+	if hops.FinalHopStatusCode == nil || *hops.FinalHopStatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected final hop status %d, got %v", http.StatusServiceUnavailable, pointerIntValue(hops.FinalHopStatusCode))
+	}
+	if hops.RedirectCount == nil || *hops.RedirectCount != 2 {
+		t.Fatalf("expected 2 redirects, got %v", pointerIntValue(hops.RedirectCount))
+	}
+
+	plain := posted["plain"]
+	if plain.HopStatusCodes != nil || plain.FirstHopStatusCode != nil || plain.FinalHopStatusCode != nil {
+		t.Fatalf("expected no hop statuses without record_hop_statuses, got %v", plain.HopStatusCodes)
+	}
+}
+
+func TestDispatchResponseReportsInvalidTargetDown(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeCoreClient{}
+	r := New(client, config.Config{QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+	if err := r.dispatchResponse(context.Background(), []monitor.Monitoring{
+		{ID: "http-malformed", Type: monitor.TypeHTTP, Target: "ht!tp://example.com"},
+		{ID: "ping-spaces", Type: monitor.TypePing, Target: "exa mple.com"},
+	}, nil); err != nil {
+		t.Fatalf("dispatchResponse failed: %v", err)
+	}
+
+	posted := client.snapshotPostedResponses()
+	if len(posted) != 2 {
+		t.Fatalf("expected two posted responses, got %d", len(posted))
+	}
+	for _, payload := range posted {
+		if payload.Status != monitor.StatusDown {
+			t.Fatalf("expected %s to be down, got %s", payload.MonitoringID, payload.Status)
+		}
+		if payload.ResponseTime != nil {
+			t.Fatalf("expected no response time for %s, got %v", payload.MonitoringID, *payload.ResponseTime)
+		}
+	}
+}
+
+// inFlightCounter tracks the peak number of concurrent holders.
+type inFlightCounter struct {
+	mu      sync.Mutex
+	current int
+	peak    int
+}
+
+func (c *inFlightCounter) hold(duration time.Duration) {
+	c.mu.Lock()
+	c.current++
+	c.peak = max(c.peak, c.current)
+	c.mu.Unlock()
+
+	time.Sleep(duration)
+
+	c.mu.Lock()
+	c.current--
+	c.mu.Unlock()
+}
+
+func (c *inFlightCounter) peakValue() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.peak
+}
+
+func TestRunMonitoringBoundsConcurrentChecksAcrossPhases(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		limit        int
+		expectedPeak int
+	}{
+		{name: "limited", limit: 2, expectedPeak: 2},
+		{name: "unlimited", limit: 0, expectedPeak: 3},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Both the SSL handshake and the HTTP request of a check count as
+			// in flight, so the peak covers checks from both phases.
+			counter := &inFlightCounter{}
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				counter.hold(50 * time.Millisecond)
+				writer.WriteHeader(http.StatusOK)
+			}))
+			server.Config.ErrorLog = log.New(io.Discard, "", 0)
+			server.TLS = &tls.Config{
+				GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+					counter.hold(50 * time.Millisecond)
+					return nil, nil
+				},
+			}
+			server.StartTLS()
+			t.Cleanup(server.Close)
+
+			monitorings := make([]monitor.Monitoring, 0, 6)
+			for i := 0; i < 6; i++ {
+				monitorings = append(monitorings, monitor.Monitoring{
+					ID:         "http-" + strconv.Itoa(i),
+					Type:       monitor.TypeHTTP,
+					Target:     server.URL,
+					Timeout:    5,
+					HTTPMethod: monitor.HTTPMethodGet,
+				})
+			}
+
+			r := New(&fakeCoreClient{responseMonitorings: monitorings}, config.Config{
+				WebGuardLocation:    "de-1",
+				QueueDefaultWorkers: 6,
+				MaxConcurrentChecks: testCase.limit,
+			}, slog.New(slog.DiscardHandler), nil)
+			if err := r.RunMonitoring(context.Background()); err != nil {
+				t.Fatalf("RunMonitoring failed: %v", err)
+			}
+
+			peak := counter.peakValue()
+			if testCase.limit > 0 && peak > testCase.limit {
+				t.Fatalf("expected at most %d concurrent checks, got %d", testCase.limit, peak)
+			}
+			if peak < testCase.expectedPeak {
+				t.Fatalf("expected concurrency to reach %d, got %d", testCase.expectedPeak, peak)
+			}
+		})
+	}
+}
+
+type failingPostClient struct {
+	fakeCoreClient
+
+	mu    sync.Mutex
+	posts int
+}
+
+func (c *failingPostClient) PostMonitoringResponse(_ context.Context, _ monitor.MonitoringResponsePayload) error {
+	c.mu.Lock()
+	c.posts++
+	c.mu.Unlock()
+	return errors.New("core unavailable")
+}
+
+func (c *failingPostClient) postCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.posts
+}
+
+func TestRunMonitoringStopsPostingAfterFailureThreshold(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		threshold     int
+		expectedPosts int
+	}{
+		{name: "threshold reached", threshold: 3, expectedPosts: 3},
+		{name: "breaker disabled", threshold: 0, expectedPosts: 10},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			monitorings := make([]monitor.Monitoring, 0, 10)
+			for i := 0; i < 10; i++ {
+				monitorings = append(monitorings, monitor.Monitoring{
+					ID:                "maintenance-" + strconv.Itoa(i),
+					Type:              monitor.TypeHTTP,
+					MaintenanceActive: true,
+				})
+			}
+			client := &failingPostClient{fakeCoreClient: fakeCoreClient{responseMonitorings: monitorings}}
+
+			var logs bytes.Buffer
+			runner := New(client, config.Config{
+				WebGuardLocation:         "de-1",
+				QueueDefaultWorkers:      1,
+				CorePostFailureThreshold: testCase.threshold,
+			}, slog.New(slog.NewTextHandler(&logs, nil)), nil)
+
+			if err := runner.RunMonitoring(context.Background()); err != nil {
+				t.Fatalf("RunMonitoring failed: %v", err)
+			}
+
+			if got := client.postCount(); got != testCase.expectedPosts {
+				t.Fatalf("expected %d post attempts, got %d", testCase.expectedPosts, got)
+			}
+			if got := strings.Count(logs.String(), "Failed to post maintenance response result"); got != testCase.expectedPosts {
+				t.Fatalf("expected %d post failure logs, got %d", testCase.expectedPosts, got)
+			}
+
+			summaries := strings.Count(logs.String(), "Skipped posting results after repeated Core API failures")
+			if testCase.expectedPosts < len(monitorings) {
+				if summaries != 1 {
+					t.Fatalf("expected a single summary log, got %d", summaries)
+				}
+				// The run summary is suspended along with the remaining results.
+				expectedSkipped := "skipped_posts=" + strconv.Itoa(len(monitorings)-testCase.expectedPosts+1)
+				if !strings.Contains(logs.String(), expectedSkipped) {
+					t.Fatalf("expected summary with %s, got %q", expectedSkipped, logs.String())
+				}
+			} else if summaries != 0 {
+				t.Fatalf("expected no summary log, got %d", summaries)
+			}
+		})
+	}
+}
+
+func TestRunMonitoringPostsRunSummary(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	expiresAt := time.Now().Add(90 * 24 * time.Hour)
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{ID: "http-up", Type: monitor.TypeHTTP, Target: server.URL, Timeout: 2, HTTPMethod: monitor.HTTPMethodGet},
+			{ID: "port-down", Type: monitor.TypePort, Target: "127.0.0.1"},
+			{ID: "maintenance", Type: monitor.TypePing, MaintenanceActive: true},
+			{ID: "heartbeat", Type: monitor.TypeHeartbeat},
+		},
+		domainMonitorings: []monitor.Monitoring{
+			{ID: "domain-up", Type: monitor.TypeDomainExpiration, Target: "example.com"},
+		},
+	}
+
+	runner := New(client, config.Config{
+		WebGuardLocation:    "de-1",
+		QueueDefaultWorkers: 2,
+	}, slog.New(slog.DiscardHandler), nil)
+	runner.domainLookup = staticDomainLookup{
+		result: domainlookup.Result{Registered: true, ExpiresAt: &expiresAt, CheckedAt: time.Now()},
+	}
+
+	before := time.Now()
+	if err := runner.RunMonitoring(context.Background()); err != nil {
+		t.Fatalf("RunMonitoring failed: %v", err)
+	}
+
+	summaries := client.snapshotPostedSummaries()
+	if len(summaries) != 1 {
+		t.Fatalf("expected one run summary, got %d", len(summaries))
+	}
+	summary := summaries[0]
+
+	// http-up and port-down also run through the SSL phase, where a plain
+	// HTTP server and a closed port both report invalid certificates.
+	if summary.Checks != 5 {
+		t.Fatalf("expected 5 checks, got %d", summary.Checks)
+	}
+	if summary.Up != 2 || summary.Down != 3 || summary.Unknown != 0 {
+		t.Fatalf("expected up=2 down=3 unknown=0, got up=%d down=%d unknown=%d", summary.Up, summary.Down, summary.Unknown)
+	}
+	if summary.SkippedMaintenance != 1 {
+		t.Fatalf("expected 1 skipped maintenance monitoring, got %d", summary.SkippedMaintenance)
+	}
+	if summary.StartedAt.Before(before.Add(-time.Second)) || summary.StartedAt.After(time.Now()) {
+		t.Fatalf("unexpected started_at: %s", summary.StartedAt)
+	}
+	if summary.DurationMs < 0 {
+		t.Fatalf("expected non-negative duration, got %d", summary.DurationMs)
+	}
+	if summary.ResponseTimeMax == nil || summary.ResponseTimeP50 == nil || *summary.ResponseTimeP50 > *summary.ResponseTimeMax {
+		t.Fatalf("expected response time percentiles from the http check, got p50=%v max=%v", summary.ResponseTimeP50, summary.ResponseTimeMax)
+	}
+}
+
+func TestRunMonitoringTypeDoesNotPostRunSummary(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{ID: "maintenance", Type: monitor.TypeHTTP, MaintenanceActive: true},
+		},
+	}
+	runner := New(client, config.Config{WebGuardLocation: "de-1"}, slog.New(slog.DiscardHandler), nil)
+
+	if err := runner.RunMonitoringType(context.Background(), string(monitor.TypeHTTP)); err != nil {
+		t.Fatalf("RunMonitoringType failed: %v", err)
+	}
+	if summaries := client.snapshotPostedSummaries(); len(summaries) != 0 {
+		t.Fatalf("expected no run summary for a single-type run, got %d", len(summaries))
+	}
+}
+
+type fakeResultSink struct {
+	mu sync.Mutex
+
+	responses []monitor.MonitoringResponsePayload
+	ssl       []monitor.SSLResultPayload
+	domains   []monitor.DomainResultPayload
+}
+
+func (f *fakeResultSink) EmitResponse(_ context.Context, payload monitor.MonitoringResponsePayload) error {
+	f.mu.Lock()
+	f.responses = append(f.responses, payload)
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeResultSink) EmitSSL(_ context.Context, payload monitor.SSLResultPayload) error {
+	f.mu.Lock()
+	f.ssl = append(f.ssl, payload)
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeResultSink) EmitDomain(_ context.Context, payload monitor.DomainResultPayload) error {
+	f.mu.Lock()
+	f.domains = append(f.domains, payload)
+	f.mu.Unlock()
+	return nil
+}
+
+func TestRunMonitoringEmitsResultsToSink(t *testing.T) {
+	t.Parallel()
+
+	expiresAt := time.Now().Add(90 * 24 * time.Hour)
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{ID: "port-down", Type: monitor.TypePort, Target: "127.0.0.1"},
+		},
+		domainMonitorings: []monitor.Monitoring{
+			{ID: "domain-up", Type: monitor.TypeDomainExpiration, Target: "example.com"},
+		},
+	}
+
+	runner := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+	runner.domainLookup = staticDomainLookup{
+		result: domainlookup.Result{Registered: true, ExpiresAt: &expiresAt, CheckedAt: time.Now()},
+	}
+	resultSink := &fakeResultSink{}
+	runner.sink = resultSink
+
+	if err := runner.RunMonitoring(context.Background()); err != nil {
+		t.Fatalf("RunMonitoring failed: %v", err)
+	}
+
+	// Domain checks report a response status alongside their domain result.
+	if len(resultSink.responses) != 2 {
+		t.Fatalf("expected port and domain responses to reach the sink, got %+v", resultSink.responses)
+	}
+	if len(resultSink.ssl) != 1 || resultSink.ssl[0].MonitoringID != "port-down" {
+		t.Fatalf("expected the SSL result to reach the sink, got %+v", resultSink.ssl)
+	}
+	if len(resultSink.domains) != 1 || resultSink.domains[0].MonitoringID != "domain-up" {
+		t.Fatalf("expected the domain result to reach the sink, got %+v", resultSink.domains)
+	}
+
+	if got := len(client.snapshotPostedResponses()) + len(client.snapshotPostedSSL()) + len(client.snapshotPostedDomains()); got != 0 {
+		t.Fatalf("expected no results posted to Core, got %d", got)
+	}
+	if got := len(client.snapshotPostedSummaries()); got != 1 {
+		t.Fatalf("expected the run summary to still go to Core, got %d", got)
+	}
+}
+
+func TestNewResultSinkSelectsBackend(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeCoreClient{}
+	testCases := []struct {
+		name     string
+		cfg      config.Config
+		expected string
+	}{
+		{name: "default", cfg: config.Config{}, expected: "runner.coreSink"},
+		{name: "core", cfg: config.Config{ResultSink: config.ResultSinkCore}, expected: "runner.coreSink"},
+		{name: "stdout", cfg: config.Config{ResultSink: config.ResultSinkStdout}, expected: "*sink.JSONLines"},
+		{name: "file", cfg: config.Config{ResultSink: config.ResultSinkFile, ResultSinkFile: filepath.Join(t.TempDir(), "results.jsonl")}, expected: "*sink.JSONLines"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			resultSink, err := newResultSink(client, testCase.cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := fmt.Sprintf("%T", resultSink); got != testCase.expected {
+				t.Fatalf("expected %s, got %s", testCase.expected, got)
+			}
+		})
+	}
+}
+
+func TestRunMonitoringPostsResultsInBatches(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name            string
+		monitorings     int
+		batchSize       int
+		expectedBatches int
+	}{
+		{name: "partial last batch", monitorings: 7, batchSize: 3, expectedBatches: 3},
+		{name: "exact multiple", monitorings: 6, batchSize: 3, expectedBatches: 2},
+		{name: "single batch", monitorings: 2, batchSize: 10, expectedBatches: 1},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &fakeCoreClient{}
+			for i := range testCase.monitorings {
+				client.responseMonitorings = append(client.responseMonitorings, monitor.Monitoring{
+					ID:                strconv.Itoa(i),
+					Type:              monitor.TypeHTTP,
+					MaintenanceActive: true,
+				})
+			}
+
+			runner := New(client, config.Config{
+				WebGuardLocation:    "de-1",
+				QueueDefaultWorkers: 2,
+				CorePostBatchSize:   testCase.batchSize,
+			}, slog.New(slog.DiscardHandler), nil)
+			if err := runner.runResponse(context.Background()); err != nil {
+				t.Fatalf("runResponse failed: %v", err)
+			}
+
+			if got := len(client.snapshotPostedResponses()); got != 0 {
+				t.Fatalf("expected no single response posts, got %d", got)
+			}
+			client.mu.Lock()
+			batches := slices.Clone(client.responseBatches)
+			client.mu.Unlock()
+			if len(batches) != testCase.expectedBatches {
+				t.Fatalf("expected %d batches, got %d", testCase.expectedBatches, len(batches))
+			}
+			delivered := 0
+			for _, batch := range batches {
+				if len(batch) > testCase.batchSize {
+					t.Fatalf("expected batches of at most %d results, got %d", testCase.batchSize, len(batch))
+				}
+				delivered += len(batch)
+			}
+			if delivered != testCase.monitorings {
+				t.Fatalf("expected %d delivered results, got %d", testCase.monitorings, delivered)
+			}
+		})
+	}
+}
+
+func TestRunSSLPostsResultsInBatches(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeCoreClient{}
+	for i := range 5 {
+		client.sslMonitorings = append(client.sslMonitorings, monitor.Monitoring{
+			ID:     strconv.Itoa(i),
+			Type:   monitor.TypeHTTP,
+			Target: "http://127.0.0.1:1",
+		})
+	}
+
+	runner := New(client, config.Config{
+		WebGuardLocation:    "de-1",
+		QueueDefaultWorkers: 2,
+		CorePostBatchSize:   2,
+	}, slog.New(slog.DiscardHandler), nil)
+	if err := runner.runSSL(context.Background(), runner.newRunState()); err != nil {
+		t.Fatalf("runSSL failed: %v", err)
+	}
+
+	if got := len(client.snapshotPostedSSL()); got != 0 {
+		t.Fatalf("expected no single SSL posts, got %d", got)
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.sslBatches) != 3 {
+		t.Fatalf("expected 3 SSL batches, got %d", len(client.sslBatches))
+	}
+}
+
+func TestBatchingDisabledForOtherSinksAndDryRun(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeCoreClient{}
+	dryRun := New(client, config.Config{CorePostBatchSize: 5, DryRun: true}, slog.New(slog.DiscardHandler), nil)
+	if dryRun.batchesResults() {
+		t.Fatalf("expected dry runs not to batch")
+	}
+
+	withSink := New(client, config.Config{CorePostBatchSize: 5}, slog.New(slog.DiscardHandler), nil)
+	withSink.sink = &fakeResultSink{}
+	if withSink.batchesResults() {
+		t.Fatalf("expected results for other sinks not to be batched")
+	}
+
+	if !New(client, config.Config{CorePostBatchSize: 5}, slog.New(slog.DiscardHandler), nil).batchesResults() {
+		t.Fatalf("expected results posted to Core to be batched")
+	}
+}
+
+func TestCollectMonitoringReturnsResultsWithoutPosting(t *testing.T) {
+	t.Parallel()
+
+	expiresAt := time.Now().Add(90 * 24 * time.Hour)
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{ID: "port-down", Type: monitor.TypePort, Target: "127.0.0.1"},
+			{ID: "maintenance", Type: monitor.TypeHTTP, Target: "https://example.com", MaintenanceActive: true},
+		},
+		domainMonitorings: []monitor.Monitoring{
+			{ID: "domain-up", Type: monitor.TypeDomainExpiration, Target: "example.com"},
+		},
+	}
+
+	runner := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+	runner.domainLookup = staticDomainLookup{
+		result: domainlookup.Result{Registered: true, ExpiresAt: &expiresAt, CheckedAt: time.Now()},
+	}
+
+	results, err := runner.CollectMonitoring(context.Background(), "")
+	if err != nil {
+		t.Fatalf("CollectMonitoring failed: %v", err)
+	}
+
+	expected := []struct {
+		id     string
+		kind   string
+		target string
+		status monitor.Status
+	}{
+		{id: "port-down", kind: "port", target: "127.0.0.1", status: monitor.StatusDown},
+		{id: "port-down", kind: "ssl", target: "127.0.0.1", status: monitor.StatusDown},
+		{id: "maintenance", kind: "http", target: "https://example.com", status: monitor.StatusUnknown},
+		{id: "domain-up", kind: "domain_expiration", target: "example.com", status: monitor.StatusUp},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %+v", len(expected), results)
+	}
+	for index, want := range expected {
+		got := results[index]
+		if got.MonitoringID != want.id || got.Type != want.kind || got.Target != want.target || got.Status != want.status {
+			t.Fatalf("expected result %d to be %+v, got %+v", index, want, got)
+		}
+	}
+
+	if got := len(client.snapshotPostedResponses()) + len(client.snapshotPostedSSL()) + len(client.snapshotPostedDomains()); got != 0 {
+		t.Fatalf("expected no results posted to Core, got %d", got)
+	}
+	if got := len(client.snapshotPostedSummaries()); got != 0 {
+		t.Fatalf("expected no run summary posted, got %d", got)
+	}
+}
+
+func TestRunMonitoringCapsMonitoringsPerRun(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{ID: "mon-d", Type: monitor.TypePort, Target: "127.0.0.1", Port: 1},
+			{ID: "mon-b", Type: monitor.TypePort, Target: "127.0.0.1", Port: 1},
+			{ID: "mon-a", Type: monitor.TypePort, Target: "127.0.0.1", Port: 1},
+			{ID: "mon-c", Type: monitor.TypePort, Target: "127.0.0.1", Port: 1},
+		},
+	}
+
+	var logs bytes.Buffer
+	runner := New(client, config.Config{
+		WebGuardLocation:     "de-1",
+		QueueDefaultWorkers:  1,
+		MaxMonitoringsPerRun: 2,
+	}, logging.New(&logs, logging.FormatJSON, ""), nil)
+
+	if err := runner.RunMonitoring(context.Background()); err != nil {
+		t.Fatalf("RunMonitoring failed: %v", err)
+	}
+
+	var posted []string
+	for _, payload := range client.snapshotPostedResponses() {
+		posted = append(posted, payload.MonitoringID)
+	}
+	slices.Sort(posted)
+	if !slices.Equal(posted, []string{"mon-a", "mon-b"}) {
+		t.Fatalf("expected only the first two monitorings by ID to be dispatched, got %v", posted)
+	}
+
+	var skipped map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("expected JSON log line, got %q: %v", line, err)
+		}
+		if entry["msg"] == "Skipping monitorings beyond MAX_MONITORINGS_PER_RUN" {
+			skipped = entry
+		}
+	}
+	if skipped == nil {
+		t.Fatalf("expected a log entry for the skipped monitorings, got %q", logs.String())
+	}
+	if skipped["skipped"] != float64(2) || skipped["fetched"] != float64(4) || skipped["limit"] != float64(2) {
+		t.Fatalf("unexpected skipped log entry: %#v", skipped)
+	}
+}
+
+func TestLimitMonitoringsKeepsListWithinCap(t *testing.T) {
+	t.Parallel()
+
+	monitorings := []monitor.Monitoring{{ID: "b"}, {ID: "a"}}
+	for _, limit := range []int{0, 2, 5} {
+		runner := New(nil, config.Config{MaxMonitoringsPerRun: limit}, slog.New(slog.DiscardHandler), nil)
+		limited := runner.limitMonitorings(monitorings)
+		if len(limited) != 2 || limited[0].ID != "b" || limited[1].ID != "a" {
+			t.Fatalf("expected limit %d to keep the fetched list untouched, got %+v", limit, limited)
+		}
+	}
+}
+
+func TestRunMonitoringSkipsDisabledMonitorings(t *testing.T) {
+	t.Parallel()
+
+	disabled := false
+	client := &fakeCoreClient{
+		responseMonitorings: []monitor.Monitoring{
+			{ID: "enabled", Type: monitor.TypePort, Target: "127.0.0.1", Port: 1},
+			{ID: "disabled", Type: monitor.TypePort, Target: "127.0.0.1", Port: 1, Enabled: &disabled},
+			{ID: "maintenance", Type: monitor.TypePort, Target: "127.0.0.1", Port: 1, MaintenanceActive: true},
+		},
+	}
+
+	runner := New(client, config.Config{WebGuardLocation: "de-1", QueueDefaultWorkers: 1}, slog.New(slog.DiscardHandler), nil)
+	if err := runner.RunMonitoring(context.Background()); err != nil {
+		t.Fatalf("RunMonitoring failed: %v", err)
+	}
+
+	statuses := make(map[string]monitor.Status)
+	for _, payload := range client.snapshotPostedResponses() {
+		statuses[payload.MonitoringID] = payload.Status
+	}
+	if _, ok := statuses["disabled"]; ok {
+		t.Fatalf("expected nothing to be posted for the disabled monitoring, got %s", statuses["disabled"])
+	}
+	if statuses["enabled"] != monitor.StatusDown {
+		t.Fatalf("expected the enabled monitoring to be checked, got %q", statuses["enabled"])
+	}
+	if statuses["maintenance"] != monitor.StatusUnknown {
+		t.Fatalf("expected the maintenance monitoring to post unknown, got %q", statuses["maintenance"])
+	}
+	for _, payload := range client.snapshotPostedSSL() {
+		if payload.MonitoringID == "disabled" {
+			t.Fatal("expected no SSL result for the disabled monitoring")
+		}
+	}
+
+	summaries := client.snapshotPostedSummaries()
+	if len(summaries) != 1 || summaries[0].SkippedMaintenance != 1 {
+		t.Fatalf("expected one summary with only the maintenance monitoring skipped, got %+v", summaries)
+	}
+}
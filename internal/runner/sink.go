@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+	"github.com/m-breuer/webguard-instance-v2/internal/sink"
+)
+
+// ResultSink receives check results. Monitorings are always fetched from
+// Core, and run summaries always go there; the sink only decides where the
+// per-check results end up.
+type ResultSink interface {
+	EmitResponse(ctx context.Context, payload monitor.MonitoringResponsePayload) error
+	EmitSSL(ctx context.Context, payload monitor.SSLResultPayload) error
+	EmitDomain(ctx context.Context, payload monitor.DomainResultPayload) error
+}
+
+// coreSink posts results to the Core API, which is the default.
+type coreSink struct {
+	client CoreClient
+}
+
+func (s coreSink) EmitResponse(ctx context.Context, payload monitor.MonitoringResponsePayload) error {
+	return s.client.PostMonitoringResponse(ctx, payload)
+}
+
+func (s coreSink) EmitSSL(ctx context.Context, payload monitor.SSLResultPayload) error {
+	return s.client.PostSSLResult(ctx, payload)
+}
+
+func (s coreSink) EmitDomain(ctx context.Context, payload monitor.DomainResultPayload) error {
+	return s.client.PostDomainResult(ctx, payload)
+}
+
+// newResultSink builds the sink selected by RESULT_SINK.
+func newResultSink(client CoreClient, cfg config.Config) (ResultSink, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.ResultSink)) {
+	case config.ResultSinkStdout:
+		return sink.NewJSONLines(os.Stdout), nil
+	case config.ResultSinkFile:
+		return sink.OpenFile(cfg.ResultSinkFile)
+	default:
+		return coreSink{client: client}, nil
+	}
+}
@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+	"github.com/m-breuer/webguard-instance-v2/internal/target"
+)
+
+const (
+	defaultSMTPPort           = 25
+	fixedSMTPTimeoutSeconds   = 10
+	smtpHelloName             = "localhost"
+	smtpStartTLSExtensionName = "STARTTLS"
+)
+
+func (r *Runner) handleSMTPMonitoring(ctx context.Context, monitoring monitor.Monitoring) (monitor.Status, *float64) {
+	port := monitoring.Port
+	if port <= 0 {
+		port = defaultSMTPPort
+	}
+
+	address, err := target.TCPAddress(monitoring.Target, port)
+	if err != nil {
+		return monitor.StatusDown, nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return monitor.StatusDown, nil
+	}
+
+	timeoutSeconds := fixedSMTPTimeoutSeconds
+	if monitoring.Timeout > 0 {
+		timeoutSeconds = monitoring.Timeout
+	}
+	timeout := time.Duration(timeoutSeconds) * time.Second
+
+	start := time.Now()
+	conn, err := r.dialTCP(ctx, r.dialer(timeout), ipNetwork("tcp", monitoring.IPVersion), address)
+	if err != nil {
+		return monitor.StatusDown, nil
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(start.Add(timeout))
+
+	// smtp.NewClient fails unless the server greets with 220.
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return monitor.StatusDown, nil
+	}
+	defer client.Close()
+	responseTime := roundMilliseconds(time.Since(start))
+
+	if err := client.Hello(smtpHelloName); err != nil {
+		return monitor.StatusDown, nil
+	}
+
+	if supported, _ := client.Extension(smtpStartTLSExtensionName); supported {
+		if err := client.StartTLS(&tls.Config{
+			ServerName:         host,
+			RootCAs:            r.rootCAs,
+			InsecureSkipVerify: !r.cfg.VerifyTLS, //nolint:gosec // Controlled by VERIFY_TLS for parity with HTTP checks.
+		}); err != nil {
+			return monitor.StatusDown, nil
+		}
+	} else if monitoring.SMTPRequireStartTLS {
+		return monitor.StatusDown, nil
+	}
+
+	_ = client.Quit()
+	return monitor.StatusUp, &responseTime
+}
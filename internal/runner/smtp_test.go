@@ -0,0 +1,210 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+type fakeSMTPServer struct {
+	greeting      string
+	advertiseTLS  bool
+	failHandshake bool
+	certificate   tls.Certificate
+	upgradedToTLS chan struct{}
+}
+
+func startFakeSMTPServer(t *testing.T, server fakeSMTPServer) int {
+	t.Helper()
+
+	if server.advertiseTLS {
+		certPEM, keyPEM := generateClientCertificatePEM(t)
+		certificate, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+		if err != nil {
+			t.Fatalf("failed to load certificate: %v", err)
+		}
+		server.certificate = certificate
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to open listener: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s fakeSMTPServer) serve(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if _, err := conn.Write([]byte(s.greeting + "\r\n")); err != nil {
+		return
+	}
+	if !strings.HasPrefix(s.greeting, "2") {
+		return
+	}
+
+	upgraded := false
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			if s.advertiseTLS && !upgraded {
+				_, _ = conn.Write([]byte("250-fake.example.test\r\n250 STARTTLS\r\n"))
+			} else {
+				_, _ = conn.Write([]byte("250 fake.example.test\r\n"))
+			}
+		case command == "STARTTLS":
+			_, _ = conn.Write([]byte("220 Ready to start TLS\r\n"))
+			if s.failHandshake {
+				return
+			}
+			tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{s.certificate}})
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			if s.upgradedToTLS != nil {
+				close(s.upgradedToTLS)
+			}
+			conn = tlsConn
+			reader = bufio.NewReader(conn)
+			upgraded = true
+		case command == "QUIT":
+			_, _ = conn.Write([]byte("221 Bye\r\n"))
+			return
+		default:
+			_, _ = conn.Write([]byte("502 Command not implemented\r\n"))
+		}
+	}
+}
+
+func TestHandleSMTPMonitoring(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name            string
+		server          fakeSMTPServer
+		requireStartTLS bool
+		expectedStatus  monitor.Status
+		expectUpgrade   bool
+	}{
+		{
+			name:           "greeting with starttls",
+			server:         fakeSMTPServer{greeting: "220 fake.example.test ESMTP", advertiseTLS: true},
+			expectedStatus: monitor.StatusUp,
+			expectUpgrade:  true,
+		},
+		{
+			name:            "starttls required and offered",
+			server:          fakeSMTPServer{greeting: "220 fake.example.test ESMTP", advertiseTLS: true},
+			requireStartTLS: true,
+			expectedStatus:  monitor.StatusUp,
+			expectUpgrade:   true,
+		},
+		{
+			name:           "plain greeting without starttls",
+			server:         fakeSMTPServer{greeting: "220 fake.example.test ESMTP"},
+			expectedStatus: monitor.StatusUp,
+		},
+		{
+			name:            "starttls required but missing",
+			server:          fakeSMTPServer{greeting: "220 fake.example.test ESMTP"},
+			requireStartTLS: true,
+			expectedStatus:  monitor.StatusDown,
+		},
+		{
+			name:           "rejecting greeting",
+			server:         fakeSMTPServer{greeting: "554 No SMTP service here"},
+			expectedStatus: monitor.StatusDown,
+		},
+		{
+			name:           "starttls handshake fails",
+			server:         fakeSMTPServer{greeting: "220 fake.example.test ESMTP", advertiseTLS: true, failHandshake: true},
+			expectedStatus: monitor.StatusDown,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			upgraded := make(chan struct{})
+			testCase.server.upgradedToTLS = upgraded
+			port := startFakeSMTPServer(t, testCase.server)
+
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			status, responseTime := r.handleSMTPMonitoring(context.Background(), monitor.Monitoring{
+				Type:                monitor.TypeSMTP,
+				Target:              "127.0.0.1",
+				Port:                port,
+				Timeout:             2,
+				SMTPRequireStartTLS: testCase.requireStartTLS,
+			})
+
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected %s, got %s", testCase.expectedStatus, status)
+			}
+			if testCase.expectedStatus == monitor.StatusUp && responseTime == nil {
+				t.Fatalf("expected response time")
+			}
+			if testCase.expectedStatus == monitor.StatusDown && responseTime != nil {
+				t.Fatalf("expected nil response time, got %v", *responseTime)
+			}
+			if testCase.expectUpgrade {
+				select {
+				case <-upgraded:
+				default:
+					t.Fatalf("expected connection to be upgraded via STARTTLS")
+				}
+			}
+		})
+	}
+}
+
+func TestHandleSMTPMonitoringConnectionRefusedIsDown(t *testing.T) {
+	t.Parallel()
+
+	r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+	status, responseTime := r.handleSMTPMonitoring(context.Background(), monitor.Monitoring{
+		Type:    monitor.TypeSMTP,
+		Target:  "127.0.0.1",
+		Port:    1,
+		Timeout: 1,
+	})
+
+	if status != monitor.StatusDown {
+		t.Fatalf("expected down, got %s", status)
+	}
+	if responseTime != nil {
+		t.Fatalf("expected nil response time")
+	}
+}
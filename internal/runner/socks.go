@@ -0,0 +1,130 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"golang.org/x/net/proxy"
+)
+
+var errSOCKSProxy = errors.New("socks5 proxy")
+
+// socksDialer opens TCP connections through SOCKS5_PROXY. With the socks5h
+// scheme hostnames are handed to the proxy unresolved, so targets only known
+// to the network behind it work too; with socks5 they are resolved here and
+// the proxy is given the address.
+type socksDialer struct {
+	proxyURL       *url.URL
+	resolveLocally bool
+}
+
+// newSOCKSDialer returns nil for a nil proxy URL, which dials directly.
+func newSOCKSDialer(proxyURL *url.URL) *socksDialer {
+	if proxyURL == nil {
+		return nil
+	}
+	return &socksDialer{proxyURL: proxyURL, resolveLocally: proxyURL.Scheme == "socks5"}
+}
+
+// dial connects to the proxy with forward and has it connect on to address.
+// forward's timeout and ctx's deadline bound the connect and the handshake
+// together. A target the proxy reports as refused wraps ECONNREFUSED, so port
+// checks classify it like a direct refusal.
+func (d *socksDialer) dial(ctx context.Context, forward *net.Dialer, resolver *net.Resolver, address string) (net.Conn, error) {
+	if forward.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, forward.Timeout)
+		defer cancel()
+	}
+
+	if d.resolveLocally {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		ip, err := resolveHostIP(ctx, resolver, host)
+		if err != nil {
+			return nil, err
+		}
+		address = net.JoinHostPort(ip.String(), port)
+	}
+
+	dialer, err := proxy.FromURL(d.proxyURL, proxyForward{forward})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errSOCKSProxy, err)
+	}
+	conn, err := dialer.(proxy.ContextDialer).DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, socksError(err)
+	}
+	return &socksConn{Conn: conn, remote: socksAddr(address)}, nil
+}
+
+// proxyForward dials the proxy itself. Its errors wrap errSOCKSProxy so the
+// proxy being down does not read as the target refusing.
+type proxyForward struct {
+	dialer *net.Dialer
+}
+
+func (f proxyForward) Dial(network, address string) (net.Conn, error) {
+	return f.DialContext(context.Background(), network, address)
+}
+
+func (f proxyForward) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := f.dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: connect to %s: %v", errSOCKSProxy, address, err)
+	}
+	return conn, nil
+}
+
+// socksError wraps handshake failures in errSOCKSProxy. x/net/proxy only
+// reports the proxy's reply code as text, so a refused target is recognised
+// by its message.
+func socksError(err error) error {
+	if errors.Is(err, errSOCKSProxy) {
+		return err
+	}
+	if strings.HasSuffix(err.Error(), "connection refused") {
+		return fmt.Errorf("%w: %v: %w", errSOCKSProxy, err, syscall.ECONNREFUSED)
+	}
+	return fmt.Errorf("%w: %v", errSOCKSProxy, err)
+}
+
+// socksConn reports the target as its remote address instead of the proxy,
+// so resolved_ip is only set when the address is known here.
+type socksConn struct {
+	net.Conn
+	remote net.Addr
+}
+
+func (c *socksConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+type socksAddr string
+
+func (a socksAddr) Network() string { return "tcp" }
+func (a socksAddr) String() string  { return string(a) }
+
+// dialTCP opens a TCP connection to address, through SOCKS5_PROXY when one
+// is set. ip_version does not apply to proxied connections.
+func (r *Runner) dialTCP(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	if r.socks == nil {
+		return dialer.DialContext(ctx, network, address)
+	}
+	// The proxy, not the target, is dialed here, so BLOCK_PRIVATE_TARGETS is
+	// left to the pre-dial check.
+	forward := *dialer
+	forward.Control = nil
+	resolver := r.resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return r.socks.dial(ctx, &forward, resolver, address)
+}
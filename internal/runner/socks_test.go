@@ -0,0 +1,354 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+// SOCKS5 wire values from RFC 1928 and RFC 1929 used by the fake proxy.
+const (
+	socksVersion5            = 0x05
+	socksPasswordAuthVersion = 0x01
+
+	socksAuthNone     = 0x00
+	socksAuthPassword = 0x02
+
+	socksAddressIPv4   = 0x01
+	socksAddressDomain = 0x03
+	socksAddressIPv6   = 0x04
+
+	socksReplySucceeded         = 0x00
+	socksReplyConnectionRefused = 0x05
+)
+
+// fakeSOCKSServer is a SOCKS5 proxy that supports CONNECT only and records
+// every target it was asked to connect to.
+type fakeSOCKSServer struct {
+	address  string
+	username string
+	password string
+
+	mu      sync.Mutex
+	targets []string
+}
+
+func startFakeSOCKSServer(t *testing.T, username, password string) *fakeSOCKSServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	server := &fakeSOCKSServer{address: listener.Addr().String(), username: username, password: password}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeSOCKSServer) snapshotTargets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.targets...)
+}
+
+func (s *fakeSOCKSServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	var greeting [2]byte
+	if _, err := io.ReadFull(conn, greeting[:]); err != nil {
+		return
+	}
+	methods := make([]byte, greeting[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return
+	}
+	if s.username == "" {
+		_, _ = conn.Write([]byte{socksVersion5, socksAuthNone})
+	} else {
+		_, _ = conn.Write([]byte{socksVersion5, socksAuthPassword})
+		if !s.authenticate(conn) {
+			return
+		}
+	}
+
+	var request [4]byte
+	if _, err := io.ReadFull(conn, request[:]); err != nil {
+		return
+	}
+	var host string
+	switch request[3] {
+	case socksAddressIPv4, socksAddressIPv6:
+		address := make([]byte, net.IPv4len)
+		if request[3] == socksAddressIPv6 {
+			address = make([]byte, net.IPv6len)
+		}
+		if _, err := io.ReadFull(conn, address); err != nil {
+			return
+		}
+		host = net.IP(address).String()
+	case socksAddressDomain:
+		var size [1]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return
+		}
+		name := make([]byte, size[0])
+		if _, err := io.ReadFull(conn, name); err != nil {
+			return
+		}
+		host = string(name)
+	}
+	var port [2]byte
+	if _, err := io.ReadFull(conn, port[:]); err != nil {
+		return
+	}
+	target := net.JoinHostPort(host, strconv.Itoa(int(port[0])<<8|int(port[1])))
+
+	s.mu.Lock()
+	s.targets = append(s.targets, target)
+	s.mu.Unlock()
+
+	// Hostnames only the proxy knows resolve to loopback.
+	if net.ParseIP(host) == nil {
+		target = net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port[0])<<8|int(port[1])))
+	}
+	upstream, err := net.DialTimeout("tcp", target, time.Second)
+	if err != nil {
+		_, _ = conn.Write([]byte{socksVersion5, socksReplyConnectionRefused, 0x00, socksAddressIPv4, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer upstream.Close()
+	_, _ = conn.Write([]byte{socksVersion5, socksReplySucceeded, 0x00, socksAddressIPv4, 127, 0, 0, 1, 0, 0})
+
+	go func() {
+		_, _ = io.Copy(upstream, conn)
+		_ = upstream.Close()
+	}()
+	_, _ = io.Copy(conn, upstream)
+}
+
+func (s *fakeSOCKSServer) authenticate(conn net.Conn) bool {
+	var header [2]byte
+	if _, err := io.ReadFull(conn, header[:]); err != nil {
+		return false
+	}
+	username := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, username); err != nil {
+		return false
+	}
+	var size [1]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return false
+	}
+	password := make([]byte, size[0])
+	if _, err := io.ReadFull(conn, password); err != nil {
+		return false
+	}
+	if string(username) != s.username || string(password) != s.password {
+		_, _ = conn.Write([]byte{socksPasswordAuthVersion, 0x01})
+		return false
+	}
+	_, _ = conn.Write([]byte{socksPasswordAuthVersion, 0x00})
+	return true
+}
+
+func startEchoListener(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestDialTCPRoutesThroughSOCKSProxy(t *testing.T) {
+	t.Parallel()
+
+	echoAddress := startEchoListener(t)
+	_, echoPort, _ := net.SplitHostPort(echoAddress)
+
+	testCases := []struct {
+		name          string
+		username      string
+		password      string
+		proxy         func(address string) string
+		target        string
+		proxiedTarget string
+	}{
+		{name: "ip target", proxy: func(address string) string { return address }, target: echoAddress, proxiedTarget: echoAddress},
+		{name: "hostname resolved by the proxy", proxy: func(address string) string { return "socks5h://" + address }, target: net.JoinHostPort("internal.example.test", echoPort), proxiedTarget: net.JoinHostPort("internal.example.test", echoPort)},
+		{name: "hostname resolved locally", proxy: func(address string) string { return "socks5://" + address }, target: net.JoinHostPort("localhost", echoPort), proxiedTarget: net.JoinHostPort("127.0.0.1", echoPort)},
+		{name: "password auth", username: "user", password: "secret", proxy: func(address string) string { return "socks5://user:secret@" + address }, target: echoAddress, proxiedTarget: echoAddress},
+	}
+
+	for _, testCase := range testCases {
+		proxy := startFakeSOCKSServer(t, testCase.username, testCase.password)
+		r := New(nil, config.Config{SOCKS5Proxy: testCase.proxy(proxy.address)}, slog.New(slog.DiscardHandler), nil)
+
+		conn, err := r.dialTCP(context.Background(), r.dialer(2*time.Second), "tcp", testCase.target)
+		if err != nil {
+			t.Fatalf("%s: unexpected dial error: %v", testCase.name, err)
+		}
+		if _, err := conn.Write([]byte("ping")); err != nil {
+			t.Fatalf("%s: write failed: %v", testCase.name, err)
+		}
+		reply := make([]byte, 4)
+		if _, err := io.ReadFull(conn, reply); err != nil || string(reply) != "ping" {
+			t.Fatalf("%s: expected the echo through the proxy, got %q: %v", testCase.name, reply, err)
+		}
+		if conn.RemoteAddr().String() != testCase.proxiedTarget {
+			t.Fatalf("%s: expected remote address %s, got %s", testCase.name, testCase.proxiedTarget, conn.RemoteAddr())
+		}
+		_ = conn.Close()
+
+		if targets := proxy.snapshotTargets(); len(targets) != 1 || targets[0] != testCase.proxiedTarget {
+			t.Fatalf("%s: expected the proxy to connect to %s, got %v", testCase.name, testCase.proxiedTarget, targets)
+		}
+	}
+}
+
+func TestDialTCPRejectedSOCKSAuthentication(t *testing.T) {
+	t.Parallel()
+
+	proxy := startFakeSOCKSServer(t, "user", "secret")
+	r := New(nil, config.Config{SOCKS5Proxy: "socks5://user:wrong@" + proxy.address}, slog.New(slog.DiscardHandler), nil)
+
+	_, err := r.dialTCP(context.Background(), r.dialer(2*time.Second), "tcp", startEchoListener(t))
+	if !errors.Is(err, errSOCKSProxy) {
+		t.Fatalf("expected a SOCKS proxy error, got %v", err)
+	}
+}
+
+func TestHandlePortMonitoringThroughSOCKSProxy(t *testing.T) {
+	t.Parallel()
+
+	openAddress := startEchoListener(t)
+	openHost, openPort, _ := net.SplitHostPort(openAddress)
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	_ = closed.Close()
+
+	proxy := startFakeSOCKSServer(t, "", "")
+	r := New(nil, config.Config{SOCKS5Proxy: proxy.address}, slog.New(slog.DiscardHandler), nil)
+
+	port, _ := strconv.Atoi(openPort)
+	status, _, details := r.handlePortMonitoring(context.Background(), monitor.Monitoring{ID: "open", Type: monitor.TypePort, Target: openHost, Port: port, Timeout: 2})
+	if status != monitor.StatusUp {
+		t.Fatalf("expected the open port to be up through the proxy, got %s", status)
+	}
+	if pointerStringValue(details.ResolvedIP) != openHost {
+		t.Fatalf("expected resolved ip %s, got %q", openHost, pointerStringValue(details.ResolvedIP))
+	}
+
+	status, _, details = r.handlePortMonitoring(context.Background(), monitor.Monitoring{ID: "closed", Type: monitor.TypePort, Target: "127.0.0.1", Port: closedPort, Timeout: 2})
+	if status != monitor.StatusDown {
+		t.Fatalf("expected the closed port to be down, got %s", status)
+	}
+	if pointerStringValue(details.FailureReason) != monitor.PortFailureConnectionRefused {
+		t.Fatalf("expected failure reason %s, got %q", monitor.PortFailureConnectionRefused, pointerStringValue(details.FailureReason))
+	}
+
+	if targets := proxy.snapshotTargets(); len(targets) != 2 {
+		t.Fatalf("expected both checks to go through the proxy, got %v", targets)
+	}
+}
+
+func TestPerformHTTPRequestThroughSOCKSProxy(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = io.WriteString(writer, "behind the bastion")
+	}))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse server url: %v", err)
+	}
+
+	proxy := startFakeSOCKSServer(t, "", "")
+	r := New(nil, config.Config{SOCKS5Proxy: proxy.address}, slog.New(slog.DiscardHandler), nil)
+
+	statusCode, body, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+		ID:         "bastion",
+		Type:       monitor.TypeHTTP,
+		Target:     "http://internal.example.test:" + serverURL.Port() + "/",
+		Timeout:    2,
+		HTTPMethod: monitor.HTTPMethodGet,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if statusCode != http.StatusOK || body != "behind the bastion" {
+		t.Fatalf("expected 200 with the proxied body, got %d %q", statusCode, body)
+	}
+	if targets := proxy.snapshotTargets(); len(targets) != 1 || targets[0] != "internal.example.test:"+serverURL.Port() {
+		t.Fatalf("expected the proxy to connect to the unresolved host, got %v", targets)
+	}
+}
+
+func TestInspectCertificateThroughSOCKSProxy(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse server url: %v", err)
+	}
+
+	proxy := startFakeSOCKSServer(t, "", "")
+	r := New(nil, config.Config{SOCKS5Proxy: proxy.address}, slog.New(slog.DiscardHandler), nil)
+	trustServerCertificate(r, server)
+
+	payload := r.crawlMonitoringSSL(context.Background(), monitor.Monitoring{
+		ID:            "bastion",
+		Target:        "https://internal.example.test:" + serverURL.Port(),
+		TLSServerName: "example.com",
+	})
+	if payload.TLSVersion == nil || payload.ExpiresAt == nil {
+		t.Fatalf("expected the certificate to be inspected through the proxy, got %+v", payload)
+	}
+	if targets := proxy.snapshotTargets(); len(targets) != 1 || targets[0] != "internal.example.test:"+serverURL.Port() {
+		t.Fatalf("expected the proxy to connect to the unresolved host, got %v", targets)
+	}
+}
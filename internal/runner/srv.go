@@ -0,0 +1,33 @@
+package runner
+
+import (
+	"context"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+	"github.com/m-breuer/webguard-instance-v2/internal/target"
+)
+
+// resolvesSRV reports whether srv_target applies to a check. Only checks
+// that dial a plain host and port can take their address from SRV.
+func resolvesSRV(monitoringType monitor.Type) bool {
+	switch monitoringType {
+	case monitor.TypePort, monitor.TypePing, monitor.TypeICMP:
+		return true
+	default:
+		return false
+	}
+}
+
+// resolveSRVTarget replaces the SRV name in the target of monitoring with the
+// host and port of its highest-priority record, so every later step checks
+// the service host itself.
+func (r *Runner) resolveSRVTarget(ctx context.Context, monitoring monitor.Monitoring) (monitor.Monitoring, bool) {
+	host, port, err := target.ResolveSRV(ctx, monitoring.Target, r.resolver)
+	if err != nil {
+		r.logger.Warn("No SRV record for monitoring target", "monitoring_id", monitoring.ID, "target", target.Redact(monitoring.Target), "error", err)
+		return monitoring, false
+	}
+	monitoring.Target = host
+	monitoring.Port = port
+	return monitoring, true
+}
@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"net"
+	"testing"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+const dnsTypeSRV uint16 = 33
+
+func srvRecordData(priority, weight, port uint16, target string) []byte {
+	data := make([]byte, 6)
+	binary.BigEndian.PutUint16(data[0:2], priority)
+	binary.BigEndian.PutUint16(data[2:4], weight)
+	binary.BigEndian.PutUint16(data[4:6], port)
+	return append(data, encodeDNSName(target)...)
+}
+
+// startSRVDNS serves _svc._tcp.example.test with a priority 10 record for
+// primary.example.test on primaryPort and a priority 20 record for
+// backup.example.test on backupPort. Both hosts resolve to 127.0.0.1.
+func startSRVDNS(t *testing.T, primaryPort, backupPort int) string {
+	t.Helper()
+
+	return startFakeDNSListener(t, func(name string, recordType uint16) (int, []fakeDNSAnswer, bool) {
+		switch {
+		case name == "_svc._tcp.example.test" && recordType == dnsTypeSRV:
+			return 0, []fakeDNSAnswer{
+				{recordType: dnsTypeSRV, data: srvRecordData(20, 0, uint16(backupPort), "backup.example.test")},
+				{recordType: dnsTypeSRV, data: srvRecordData(10, 0, uint16(primaryPort), "primary.example.test")},
+			}, true
+		case (name == "primary.example.test" || name == "backup.example.test") && recordType == dnsTypeA:
+			return 0, []fakeDNSAnswer{{recordType: dnsTypeA, data: net.ParseIP("127.0.0.1").To4()}}, true
+		case name == "_svc._tcp.example.test" || name == "primary.example.test" || name == "backup.example.test":
+			return 0, nil, true
+		default:
+			return dnsRcodeNameError, nil, true
+		}
+	})
+}
+
+func TestCheckResponseMonitoringDialsHighestPrioritySRVRecord(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	_ = closed.Close()
+
+	r := New(nil, config.Config{DNSResolver: startSRVDNS(t, listener.Addr().(*net.TCPAddr).Port, closedPort)}, slog.New(slog.DiscardHandler), nil)
+
+	testCases := []struct {
+		name           string
+		monitoring     monitor.Monitoring
+		expectedStatus monitor.Status
+		expectedReason string
+	}{
+		{
+			name:           "srv record",
+			monitoring:     monitor.Monitoring{ID: "srv", Type: monitor.TypePort, Target: "_svc._tcp.example.test", SRVTarget: true, Timeout: 2},
+			expectedStatus: monitor.StatusUp,
+		},
+		{
+			name:           "no srv record",
+			monitoring:     monitor.Monitoring{ID: "missing", Type: monitor.TypePort, Target: "_none._tcp.example.test", SRVTarget: true, Timeout: 2},
+			expectedStatus: monitor.StatusDown,
+			expectedReason: monitor.FailureSRVNotFound,
+		},
+		{
+			name:           "srv target disabled",
+			monitoring:     monitor.Monitoring{ID: "literal", Type: monitor.TypePort, Target: "_svc._tcp.example.test", Port: listener.Addr().(*net.TCPAddr).Port, Timeout: 2},
+			expectedStatus: monitor.StatusDown,
+			expectedReason: monitor.PortFailureUnreachable,
+		},
+	}
+
+	for _, testCase := range testCases {
+		status, _, _, details := r.checkResponseMonitoring(context.Background(), testCase.monitoring)
+		if status != testCase.expectedStatus {
+			t.Fatalf("%s: expected %s, got %s", testCase.name, testCase.expectedStatus, status)
+		}
+		if got := pointerStringValue(details.FailureReason); testCase.expectedReason != "" && got != testCase.expectedReason {
+			t.Fatalf("%s: expected failure reason %q, got %v", testCase.name, testCase.expectedReason, got)
+		}
+	}
+}
+
+func TestHandlePingMonitoringUsesSRVHost(t *testing.T) {
+	originalExecutor := pingExecutor
+	t.Cleanup(func() {
+		pingExecutor = originalExecutor
+	})
+
+	var receivedHost string
+	pingExecutor = func(_ context.Context, host string, _ int) ([]byte, error) {
+		receivedHost = host
+		return []byte("64 bytes from " + host + ": icmp_seq=1 ttl=57 time=1.23 ms"), nil
+	}
+
+	r := New(nil, config.Config{DNSResolver: startSRVDNS(t, 5060, 5061)}, slog.New(slog.DiscardHandler), nil)
+	status, _, _, _ := r.checkResponseMonitoring(context.Background(), monitor.Monitoring{
+		ID:        "sip",
+		Type:      monitor.TypePing,
+		Target:    "_svc._tcp.example.test",
+		SRVTarget: true,
+	})
+	if status != monitor.StatusUp {
+		t.Fatalf("expected up, got %s", status)
+	}
+	if receivedHost != "127.0.0.1" {
+		t.Fatalf("expected ping to the address of primary.example.test, got %q", receivedHost)
+	}
+}
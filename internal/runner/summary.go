@@ -0,0 +1,134 @@
+package runner
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+type runState struct {
+	posts *postBreaker
+
+	responses  *resultBatch[monitor.MonitoringResponsePayload]
+	sslResults *resultBatch[monitor.SSLResultPayload]
+
+	// results is set for runs whose results are returned to the caller
+	// instead of being posted.
+	results *resultCollector
+
+	mu                 sync.Mutex
+	checks             int
+	up                 int
+	down               int
+	degraded           int
+	unknown            int
+	skippedMaintenance int
+	responseTimes      []float64
+}
+
+func (r *Runner) newRunState() *runState {
+	run := &runState{posts: newPostBreaker(r.cfg.CorePostFailureThreshold)}
+	if r.batchesResults() {
+		run.responses = newResultBatch[monitor.MonitoringResponsePayload](r.cfg.CorePostBatchSize)
+		run.sslResults = newResultBatch[monitor.SSLResultPayload](r.cfg.CorePostBatchSize)
+	}
+	return run
+}
+
+func (s *runState) breaker() *postBreaker {
+	if s == nil {
+		return nil
+	}
+	return s.posts
+}
+
+func (s *runState) responseBatch() *resultBatch[monitor.MonitoringResponsePayload] {
+	if s == nil {
+		return nil
+	}
+	return s.responses
+}
+
+func (s *runState) sslBatch() *resultBatch[monitor.SSLResultPayload] {
+	if s == nil {
+		return nil
+	}
+	return s.sslResults
+}
+
+func (s *runState) recordCheck(status monitor.Status) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checks++
+	switch status {
+	case monitor.StatusUp:
+		s.up++
+	case monitor.StatusDown:
+		s.down++
+	case monitor.StatusDegraded:
+		s.degraded++
+	default:
+		s.unknown++
+	}
+}
+
+// recordResponseTime keeps the response time of a finished response check
+// for the latency percentiles of the run summary.
+func (s *runState) recordResponseTime(responseTime *float64) {
+	if s == nil || responseTime == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responseTimes = append(s.responseTimes, *responseTime)
+}
+
+func (s *runState) recordMaintenance() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.skippedMaintenance++
+}
+
+func (s *runState) summary(startedAt time.Time, duration time.Duration) monitor.RunSummaryPayload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payload := monitor.RunSummaryPayload{
+		StartedAt:          startedAt.UTC(),
+		DurationMs:         duration.Milliseconds(),
+		Checks:             s.checks,
+		Up:                 s.up,
+		Down:               s.down,
+		Degraded:           s.degraded,
+		Unknown:            s.unknown,
+		SkippedMaintenance: s.skippedMaintenance,
+	}
+	if len(s.responseTimes) > 0 {
+		sorted := slices.Sorted(slices.Values(s.responseTimes))
+		p50, p95, p99 := percentile(sorted, 50), percentile(sorted, 95), percentile(sorted, 99)
+		maximum := sorted[len(sorted)-1]
+		payload.ResponseTimeP50 = &p50
+		payload.ResponseTimeP95 = &p95
+		payload.ResponseTimeP99 = &p99
+		payload.ResponseTimeMax = &maximum
+	}
+	return payload
+}
+
+// percentile returns the nearest-rank percentile of sorted values: the
+// smallest value that at least p percent of all values are less than or equal
+// to.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
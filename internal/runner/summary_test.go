@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentileUsesNearestRank(t *testing.T) {
+	t.Parallel()
+
+	values := make([]float64, 100)
+	for index := range values {
+		values[index] = float64(index + 1)
+	}
+
+	testCases := []struct {
+		name     string
+		values   []float64
+		p        float64
+		expected float64
+	}{
+		{name: "p50 of 1..100", values: values, p: 50, expected: 50},
+		{name: "p95 of 1..100", values: values, p: 95, expected: 95},
+		{name: "p99 of 1..100", values: values, p: 99, expected: 99},
+		{name: "p100 of 1..100", values: values, p: 100, expected: 100},
+		{name: "p50 of odd count", values: []float64{10, 20, 30}, p: 50, expected: 20},
+		{name: "p95 of small set", values: []float64{10, 20, 30, 40}, p: 95, expected: 40},
+		{name: "p0 is the minimum", values: []float64{10, 20, 30}, p: 0, expected: 10},
+		{name: "single value", values: []float64{42}, p: 99, expected: 42},
+	}
+
+	for _, testCase := range testCases {
+		if got := percentile(testCase.values, testCase.p); got != testCase.expected {
+			t.Fatalf("%s: expected %v, got %v", testCase.name, testCase.expected, got)
+		}
+	}
+}
+
+func TestRunStateSummaryIncludesLatencyPercentiles(t *testing.T) {
+	t.Parallel()
+
+	run := &runState{}
+	for _, responseTime := range []float64{120, 80, 300, 45.5, 95, 210, 60, 150, 1000, 70} {
+		run.recordResponseTime(&responseTime)
+	}
+	run.recordResponseTime(nil)
+
+	summary := run.summary(time.Now(), time.Second)
+	for _, check := range []struct {
+		name     string
+		value    *float64
+		expected float64
+	}{
+		{name: "p50", value: summary.ResponseTimeP50, expected: 95},
+		{name: "p95", value: summary.ResponseTimeP95, expected: 1000},
+		{name: "p99", value: summary.ResponseTimeP99, expected: 1000},
+		{name: "max", value: summary.ResponseTimeMax, expected: 1000},
+	} {
+		if check.value == nil || *check.value != check.expected {
+			t.Fatalf("expected %s %v, got %v", check.name, check.expected, check.value)
+		}
+	}
+}
+
+func TestRunStateSummaryOmitsPercentilesWithoutResponseTimes(t *testing.T) {
+	t.Parallel()
+
+	summary := (&runState{}).summary(time.Now(), time.Second)
+	if summary.ResponseTimeP50 != nil || summary.ResponseTimeP95 != nil || summary.ResponseTimeP99 != nil || summary.ResponseTimeMax != nil {
+		t.Fatalf("expected no percentiles without response times, got %+v", summary)
+	}
+}
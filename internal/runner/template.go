@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// newTemplateVariables returns the replacer for the placeholders a monitoring
+// may use in its request body and header values. Each request gets its own
+// values:
+//
+//   - {{timestamp}}: the current time in RFC 3339 format (UTC)
+//   - {{unix}}: the current time in seconds since the Unix epoch
+//   - {{uuid}}: a random version 4 UUID
+//
+// Text without placeholders passes through unchanged.
+func newTemplateVariables(now time.Time) *strings.Replacer {
+	return strings.NewReplacer(
+		"{{timestamp}}", now.UTC().Format(time.RFC3339),
+		"{{unix}}", strconv.FormatInt(now.Unix(), 10),
+		"{{uuid}}", newUUID(),
+	)
+}
+
+func newUUID() string {
+	var value [16]byte
+	_, _ = rand.Read(value[:])
+	value[6] = (value[6] & 0x0f) | 0x40
+	value[8] = (value[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", value[0:4], value[4:6], value[6:8], value[8:10], value[10:16])
+}
+
+// newNonce returns 16 random hex characters.
+func newNonce() string {
+	var value [8]byte
+	_, _ = rand.Read(value[:])
+	return fmt.Sprintf("%x", value)
+}
+
+func expandTemplate(value string, variables *strings.Replacer) string {
+	if variables == nil || !strings.Contains(value, "{{") {
+		return value
+	}
+	return variables.Replace(value)
+}
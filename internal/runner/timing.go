@@ -0,0 +1,202 @@
+package runner
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// httpTimings holds what the client observed for a request: the duration of
+// each phase, the IP of the peer the connection reached, where redirects led
+// and the content type and cache validators of the final response.
+type httpTimings struct {
+	DNSMs         *float64
+	ConnectMs     *float64
+	TLSMs         *float64
+	TTFBMs        *float64
+	ResolvedIP    *string
+	FinalURL      *string
+	RedirectCount *int
+
+	SchemeDowngraded bool
+	HopStatusCodes   []int
+
+	ContentType  string
+	ETag         string
+	LastModified string
+}
+
+type httpTimingTracer struct {
+	mu sync.Mutex
+
+	requestStart time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+
+	timings      httpTimings
+	handshakeErr error
+}
+
+func newHTTPTimingTracer(requestStart time.Time) *httpTimingTracer {
+	return &httpTimingTracer{requestStart: requestStart}
+}
+
+func (t *httpTimingTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if info.Err == nil && t.timings.DNSMs == nil {
+				t.timings.DNSMs = elapsedMilliseconds(t.dnsStart)
+			}
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err == nil && t.timings.ConnectMs == nil {
+				t.timings.ConnectMs = elapsedMilliseconds(t.connectStart)
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if info.Conn != nil {
+				t.timings.ResolvedIP = remoteIP(info.Conn.RemoteAddr())
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err != nil {
+				t.handshakeErr = err
+				return
+			}
+			if t.timings.TLSMs == nil {
+				t.timings.TLSMs = elapsedMilliseconds(t.tlsStart)
+			}
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.timings.TTFBMs == nil {
+				t.timings.TTFBMs = elapsedMilliseconds(t.requestStart)
+			}
+		},
+	}
+}
+
+func (t *httpTimingTracer) result() httpTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.timings
+}
+
+// observeResponse records the URL the request ended at, how many redirects
+// led there, whether any of them went from https to http and the
+// Content-Type, ETag and Last-Modified the response carried. With
+// recordHops it also records the status code of every hop, first hop first.
+func (t *httpTimingTracer) observeResponse(response *http.Response, recordHops bool) {
+	if response == nil || response.Request == nil || response.Request.URL == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	redirects := 0
+	hops := []int{response.StatusCode}
+	next := response.Request
+	for previous := response.Request.Response; previous != nil && previous.Request != nil; previous = previous.Request.Response {
+		redirects++
+		hops = append(hops, previous.StatusCode)
+		if schemeDowngraded(previous.Request.URL, next.URL) {
+			t.timings.SchemeDowngraded = true
+		}
+		next = previous.Request
+	}
+	finalURL := response.Request.URL.String()
+	t.timings.FinalURL = &finalURL
+	t.timings.RedirectCount = &redirects
+	t.timings.ContentType = response.Header.Get("Content-Type")
+	t.timings.ETag = response.Header.Get("ETag")
+	t.timings.LastModified = response.Header.Get("Last-Modified")
+	if recordHops {
+		slices.Reverse(hops)
+		t.timings.HopStatusCodes = hops
+	}
+}
+
+// firstHopStatusCode returns the status code of the first response along a
+// recorded redirect chain, or nil when no hops were recorded.
+func firstHopStatusCode(hops []int) *int {
+	if len(hops) == 0 {
+		return nil
+	}
+	statusCode := hops[0]
+	return &statusCode
+}
+
+// finalHopStatusCode returns the status code of the response a recorded
+// redirect chain ended at, or nil when no hops were recorded.
+func finalHopStatusCode(hops []int) *int {
+	if len(hops) == 0 {
+		return nil
+	}
+	statusCode := hops[len(hops)-1]
+	return &statusCode
+}
+
+func schemeDowngraded(from, to *url.URL) bool {
+	return from != nil && to != nil && strings.EqualFold(from.Scheme, "https") && strings.EqualFold(to.Scheme, "http")
+}
+
+// handshakeFailed reports whether a TLS handshake of the request failed.
+func (t *httpTimingTracer) handshakeFailed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.handshakeErr != nil
+}
+
+func elapsedMilliseconds(start time.Time) *float64 {
+	value := roundMilliseconds(time.Since(start))
+	return &value
+}
+
+// remoteIP returns the IP part of a connection's remote address, or nil when
+// the address carries no IP.
+func remoteIP(address net.Addr) *string {
+	if address == nil {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address.String())
+	if err != nil {
+		host = address.String()
+	}
+	if net.ParseIP(host) == nil {
+		return nil
+	}
+	return &host
+}
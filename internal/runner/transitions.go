@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"context"
+	"sync"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+	"github.com/m-breuer/webguard-instance-v2/internal/target"
+)
+
+type StatusNotifier interface {
+	SendStatusTransition(ctx context.Context, payload monitor.StatusTransitionPayload) error
+}
+
+// statusStore remembers the last up/down status per monitoring across runs
+// so transitions can be detected. It lives only in memory; a restart starts
+// from a clean slate.
+type statusStore struct {
+	mu       sync.Mutex
+	statuses map[string]monitor.Status
+}
+
+func newStatusStore() *statusStore {
+	return &statusStore{statuses: make(map[string]monitor.Status)}
+}
+
+// observe records status and returns the previous one when it differs. The
+// first observation and unknown results never count as a transition. A
+// degraded check still reached its target, so it counts as up.
+func (s *statusStore) observe(monitoringID string, status monitor.Status) (monitor.Status, bool) {
+	if status == monitor.StatusDegraded {
+		status = monitor.StatusUp
+	}
+	if status != monitor.StatusUp && status != monitor.StatusDown {
+		return "", false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, seen := s.statuses[monitoringID]
+	s.statuses[monitoringID] = status
+	if !seen || previous == status {
+		return "", false
+	}
+	return previous, true
+}
+
+func (r *Runner) notifyStatusTransition(ctx context.Context, monitoring monitor.Monitoring, status monitor.Status) {
+	if r.notifier == nil {
+		return
+	}
+
+	previous, changed := r.statuses.observe(monitoring.ID, status)
+	if !changed {
+		return
+	}
+
+	payload := monitor.StatusTransitionPayload{
+		MonitoringID:   monitoring.ID,
+		Type:           monitoring.Type,
+		Target:         target.Redact(monitoring.Target),
+		PreviousStatus: previous,
+		Status:         status,
+		ChangedAt:      r.clock.Now().UTC(),
+	}
+	if r.cfg.DryRun {
+		r.logDryRun("status_transition", monitoring.ID, payload)
+		return
+	}
+	if err := r.notifier.SendStatusTransition(ctx, payload); err != nil {
+		r.logger.Error("Failed to send status notification", "monitoring_id", monitoring.ID, "error", err)
+	}
+}
@@ -0,0 +1,154 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+const fixedHTTPIdleConnTimeout = 90 * time.Second
+
+// transportKey holds the monitoring settings that end up in an
+// *http.Transport. Checks with equal keys share one transport and with it
+// the idle connections HTTP_MAX_IDLE_CONNS_PER_HOST keeps open.
+type transportKey struct {
+	proxyURL      string
+	tlsServerName string
+	clientCertPEM string
+	clientKeyPEM  string
+	http2         bool
+	ipVersion     monitor.IPVersion
+}
+
+func newTransportKey(monitoring monitor.Monitoring) transportKey {
+	return transportKey{
+		proxyURL:      monitoring.ProxyURL,
+		tlsServerName: monitoring.TLSServerName,
+		clientCertPEM: monitoring.ClientCertPEM,
+		clientKeyPEM:  monitoring.ClientKeyPEM,
+		http2:         monitoring.HTTPProtocol == monitor.HTTPProtocolHTTP2,
+		ipVersion:     monitoring.IPVersion,
+	}
+}
+
+// transportPool keeps one transport per transportKey. Keys no monitoring
+// used during a full run are dropped by evictUnused, so monitorings that
+// changed or went away do not keep their transports forever.
+type transportPool struct {
+	mu         sync.Mutex
+	transports map[transportKey]*http.Transport
+	used       map[transportKey]bool
+}
+
+func newTransportPool() *transportPool {
+	return &transportPool{
+		transports: make(map[transportKey]*http.Transport),
+		used:       make(map[transportKey]bool),
+	}
+}
+
+// get returns the transport for key, building it on first use. A failed
+// build is not cached.
+func (p *transportPool) get(key transportKey, build func() (*http.Transport, error)) (*http.Transport, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.used[key] = true
+	if transport, ok := p.transports[key]; ok {
+		return transport, nil
+	}
+	transport, err := build()
+	if err != nil {
+		return nil, err
+	}
+	p.transports[key] = transport
+	return transport, nil
+}
+
+// evictUnused closes and drops the transports not used since the previous
+// call. Requests still holding an evicted transport finish normally.
+func (p *transportPool) evictUnused() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, transport := range p.transports {
+		if p.used[key] {
+			continue
+		}
+		transport.CloseIdleConnections()
+		delete(p.transports, key)
+	}
+	clear(p.used)
+}
+
+func (p *transportPool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.transports)
+}
+
+func (p *transportPool) closeIdleConnections() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, transport := range p.transports {
+		transport.CloseIdleConnections()
+	}
+}
+
+// httpTransport returns the shared transport for the settings of
+// monitoring. HTTP_MAX_IDLE_CONNS_PER_HOST of 0 turns connection reuse off,
+// so every check dials anew.
+func (r *Runner) httpTransport(monitoring monitor.Monitoring) (*http.Transport, error) {
+	key := newTransportKey(monitoring)
+	return r.transports.get(key, func() (*http.Transport, error) {
+		// TLS_MIN_VERSION is checked by Config.Validate; an invalid value
+		// falls back to the Go default.
+		minVersion, _ := config.TLSVersion(r.cfg.TLSMinVersion)
+		tlsConfig := &tls.Config{
+			ServerName:         key.tlsServerName,
+			MinVersion:         minVersion,
+			RootCAs:            r.rootCAs,
+			InsecureSkipVerify: !r.cfg.VerifyTLS, //nolint:gosec // Skipped by default to keep PHP compatibility (withoutVerifying)
+		}
+		clientCertificate, hasClientCertificate, err := loadClientCertificate(monitoring)
+		if err != nil {
+			return nil, err
+		}
+		if hasClientCertificate {
+			tlsConfig.Certificates = []tls.Certificate{clientCertificate}
+		}
+
+		proxy, err := r.httpProxy(monitoring)
+		if err != nil {
+			return nil, err
+		}
+
+		keepAlive := time.Duration(r.cfg.HTTPKeepAliveSeconds) * time.Second
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: keepAlive, Resolver: r.resolver, Control: r.dialControl()}
+		// A fixed proxy is the only address this transport dials, and the
+		// operator chose it; the redirect and pre-dial checks still apply.
+		if key.proxyURL != "" || strings.TrimSpace(r.cfg.HTTPProxyURL) != "" {
+			dialer.Control = nil
+		}
+		return &http.Transport{
+			Proxy: proxy,
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return r.dialTCP(ctx, dialer, ipNetwork(network, key.ipVersion), address)
+			},
+			TLSClientConfig:     tlsConfig,
+			ForceAttemptHTTP2:   key.http2,
+			DisableKeepAlives:   r.cfg.HTTPMaxIdleConnsPerHost <= 0,
+			MaxIdleConnsPerHost: r.cfg.HTTPMaxIdleConnsPerHost,
+			IdleConnTimeout:     fixedHTTPIdleConnTimeout,
+		}, nil
+	})
+}
@@ -0,0 +1,156 @@
+package runner
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+// newConnectionCountingServer returns a server that answers 200 and counts
+// every TCP connection clients open to it.
+func newConnectionCountingServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write([]byte("ok"))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+	return server, &connections
+}
+
+func TestPerformHTTPRequestReusesConnectionsForSameHost(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name                string
+		maxIdleConnsPerHost int
+		expectedConnections int32
+	}{
+		{name: "reuse enabled", maxIdleConnsPerHost: 2, expectedConnections: 1},
+		{name: "reuse disabled", maxIdleConnsPerHost: 0, expectedConnections: 3},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			server, connections := newConnectionCountingServer(t)
+			r := New(nil, config.Config{HTTPMaxIdleConnsPerHost: testCase.maxIdleConnsPerHost}, slog.New(slog.DiscardHandler), nil)
+
+			for _, path := range []string{"/a", "/b", "/c"} {
+				statusCode, _, timings, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+					ID:      "mon" + path,
+					Target:  server.URL + path,
+					Timeout: 5,
+				})
+				if err != nil || statusCode != http.StatusOK {
+					t.Fatalf("expected 200 for %s, got %d (%v)", path, statusCode, err)
+				}
+				if timings.ResolvedIP == nil {
+					t.Fatalf("expected resolved ip for %s on a reused connection too", path)
+				}
+			}
+
+			if got := connections.Load(); got != testCase.expectedConnections {
+				t.Fatalf("expected %d connections, got %d", testCase.expectedConnections, got)
+			}
+		})
+	}
+}
+
+func TestPerformHTTPRequestSeparatesTransportsBySettings(t *testing.T) {
+	t.Parallel()
+
+	server, connections := newConnectionCountingServer(t)
+	r := New(nil, config.Config{HTTPMaxIdleConnsPerHost: 2}, slog.New(slog.DiscardHandler), nil)
+
+	for _, serverName := range []string{"", "a.example.test", "", "a.example.test"} {
+		statusCode, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{
+			Target:        server.URL,
+			Timeout:       5,
+			TLSServerName: serverName,
+		})
+		if err != nil || statusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d (%v)", statusCode, err)
+		}
+	}
+
+	if got := connections.Load(); got != 2 {
+		t.Fatalf("expected one connection per distinct transport setting, got %d", got)
+	}
+}
+
+func TestShutdownClosesIdleHTTPConnections(t *testing.T) {
+	t.Parallel()
+
+	closed := make(chan struct{}, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			select {
+			case closed <- struct{}{}:
+			default:
+			}
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+
+	r := New(nil, config.Config{HTTPMaxIdleConnsPerHost: 2}, slog.New(slog.DiscardHandler), nil)
+	if _, _, _, err := r.performHTTPRequest(context.Background(), monitor.Monitoring{Target: server.URL, Timeout: 5}); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	if err := r.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected shutdown to close the idle connection")
+	}
+}
+
+func TestTransportPoolEvictsTransportsUnusedSinceLastRun(t *testing.T) {
+	t.Parallel()
+
+	pool := newTransportPool()
+	build := func() (*http.Transport, error) { return &http.Transport{}, nil }
+	kept := transportKey{tlsServerName: "kept.example.test"}
+	dropped := transportKey{tlsServerName: "dropped.example.test"}
+
+	keptTransport, _ := pool.get(kept, build)
+	_, _ = pool.get(dropped, build)
+	pool.evictUnused()
+	if got := pool.size(); got != 2 {
+		t.Fatalf("expected transports used in the run to stay, got %d", got)
+	}
+
+	_, _ = pool.get(kept, build)
+	pool.evictUnused()
+	if got := pool.size(); got != 1 {
+		t.Fatalf("expected the unused transport to be evicted, got %d transports", got)
+	}
+	if transport, _ := pool.get(kept, build); transport != keptTransport {
+		t.Fatalf("expected the used transport to be reused")
+	}
+}
@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+const fixedWebSocketTimeoutSeconds = 10
+
+var errWebSocketPong = errors.New("websocket pong received")
+
+func (r *Runner) handleWebSocketMonitoring(ctx context.Context, monitoring monitor.Monitoring) (monitor.Status, *float64) {
+	endpoint, err := webSocketURL(monitoring.Target)
+	if err != nil {
+		return monitor.StatusDown, nil
+	}
+
+	timeoutSeconds := fixedWebSocketTimeoutSeconds
+	if monitoring.Timeout > 0 {
+		timeoutSeconds = monitoring.Timeout
+	}
+	timeout := time.Duration(timeoutSeconds) * time.Second
+
+	start := time.Now()
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	conn, response, err := r.webSocketDialer(monitoring.IPVersion, timeout).DialContext(dialCtx, endpoint.String(), nil)
+	if response != nil && response.Body != nil {
+		_ = response.Body.Close()
+	}
+	if err != nil {
+		return monitor.StatusDown, nil
+	}
+	defer conn.Close()
+	deadline := start.Add(timeout)
+	_ = conn.SetReadDeadline(deadline)
+	_ = conn.SetWriteDeadline(deadline)
+
+	if monitoring.WebSocketPing {
+		if err := webSocketPing(conn, deadline); err != nil {
+			return monitor.StatusDown, nil
+		}
+	}
+	responseTime := roundMilliseconds(time.Since(start))
+
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), deadline)
+	return monitor.StatusUp, &responseTime
+}
+
+func webSocketURL(rawTarget string) (*url.URL, error) {
+	endpoint, err := url.Parse(strings.TrimSpace(rawTarget))
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(endpoint.Scheme) {
+	case "ws", "http":
+		endpoint.Scheme = "ws"
+	case "wss", "https":
+		endpoint.Scheme = "wss"
+	default:
+		return nil, fmt.Errorf("unsupported websocket scheme %q", endpoint.Scheme)
+	}
+	if endpoint.Hostname() == "" {
+		return nil, fmt.Errorf("websocket target has no host")
+	}
+	return endpoint, nil
+}
+
+// webSocketDialer connects through dialTCP, so SOCKS5_PROXY, ip_version and
+// BLOCK_PRIVATE_TARGETS apply as for other TCP checks, and upgrades with
+// gorilla/websocket, which validates the whole handshake response.
+func (r *Runner) webSocketDialer(ipVersion monitor.IPVersion, timeout time.Duration) *websocket.Dialer {
+	return &websocket.Dialer{
+		NetDialContext: func(ctx context.Context, _, address string) (net.Conn, error) {
+			return r.dialTCP(ctx, r.dialer(timeout), ipNetwork("tcp", ipVersion), address)
+		},
+		TLSClientConfig: &tls.Config{
+			RootCAs:            r.rootCAs,
+			InsecureSkipVerify: !r.cfg.VerifyTLS, //nolint:gosec // Controlled by VERIFY_TLS for parity with HTTP checks.
+		},
+		HandshakeTimeout: timeout,
+	}
+}
+
+// webSocketPing sends a ping and reads until the matching pong. Control
+// frames are only handled while reading, so the pong handler ends the read
+// loop by returning errWebSocketPong.
+func webSocketPing(conn *websocket.Conn, deadline time.Time) error {
+	const payload = "webguard"
+	conn.SetPongHandler(func(data string) error {
+		if data == payload {
+			return errWebSocketPong
+		}
+		return nil
+	})
+	if err := conn.WriteControl(websocket.PingMessage, []byte(payload), deadline); err != nil {
+		return err
+	}
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			if errors.Is(err, errWebSocketPong) {
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package runner
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec // Required by the WebSocket handshake (RFC 6455).
+	"encoding/base64"
+	"errors"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/m-breuer/webguard-instance-v2/internal/config"
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+// fakeWebSocketServer upgrades with gorilla/websocket and answers pings
+// only when answerPing is set; otherwise a ping closes the connection.
+type fakeWebSocketServer struct {
+	answerPing bool
+}
+
+func (s fakeWebSocketServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(writer, request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	if !s.answerPing {
+		conn.SetPingHandler(func(string) error { return errors.New("ping not answered") })
+	}
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// rawUpgradeHandler answers the upgrade request with a hand-written 101
+// response, so tests can break single parts of the handshake.
+type rawUpgradeHandler struct {
+	acceptKey      func(key string) string
+	omitConnection bool
+}
+
+func (h rawUpgradeHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	conn, _, err := writer.(http.Hijacker).Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	acceptKey := webSocketAcceptKey
+	if h.acceptKey != nil {
+		acceptKey = h.acceptKey
+	}
+	response := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\n"
+	if !h.omitConnection {
+		response += "Connection: Upgrade\r\n"
+	}
+	response += "Sec-WebSocket-Accept: " + acceptKey(request.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n"
+	_, _ = io.WriteString(conn, response)
+	_, _ = io.Copy(io.Discard, conn)
+}
+
+func webSocketAcceptKey(key string) string {
+	digest := sha1.Sum([]byte(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11")) //nolint:gosec // Required by RFC 6455.
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+func TestHandleWebSocketMonitoring(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		handler        http.Handler
+		ping           bool
+		useTLS         bool
+		expectedStatus monitor.Status
+	}{
+		{
+			name:           "successful upgrade",
+			handler:        fakeWebSocketServer{},
+			expectedStatus: monitor.StatusUp,
+		},
+		{
+			name:           "successful upgrade with ping",
+			handler:        fakeWebSocketServer{answerPing: true},
+			ping:           true,
+			expectedStatus: monitor.StatusUp,
+		},
+		{
+			name:           "successful upgrade over tls",
+			handler:        fakeWebSocketServer{answerPing: true},
+			ping:           true,
+			useTLS:         true,
+			expectedStatus: monitor.StatusUp,
+		},
+		{
+			name:           "ping without pong",
+			handler:        fakeWebSocketServer{},
+			ping:           true,
+			expectedStatus: monitor.StatusDown,
+		},
+		{
+			name:           "raw upgrade",
+			handler:        rawUpgradeHandler{},
+			expectedStatus: monitor.StatusUp,
+		},
+		{
+			name:           "invalid accept key",
+			handler:        rawUpgradeHandler{acceptKey: func(string) string { return "invalid" }},
+			expectedStatus: monitor.StatusDown,
+		},
+		{
+			name:           "missing connection header",
+			handler:        rawUpgradeHandler{omitConnection: true},
+			expectedStatus: monitor.StatusDown,
+		},
+		{
+			name: "non-101 response",
+			handler: http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+				writer.WriteHeader(http.StatusOK)
+			}),
+			expectedStatus: monitor.StatusDown,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewUnstartedServer(testCase.handler)
+			server.Config.ErrorLog = log.New(io.Discard, "", 0)
+			if testCase.useTLS {
+				server.StartTLS()
+			} else {
+				server.Start()
+			}
+			defer server.Close()
+
+			targetURL := strings.Replace(strings.Replace(server.URL, "https://", "wss://", 1), "http://", "ws://", 1) + "/socket"
+
+			r := New(nil, config.Config{}, slog.New(slog.DiscardHandler), nil)
+			status, responseTime := r.handleWebSocketMonitoring(context.Background(), monitor.Monitoring{
+				Type:          monitor.TypeWebSocket,
+				Target:        targetURL,
+				Timeout:       2,
+				WebSocketPing: testCase.ping,
+			})
+
+			if status != testCase.expectedStatus {
+				t.Fatalf("expected %s, got %s", testCase.expectedStatus, status)
+			}
+			if (responseTime != nil) != (testCase.expectedStatus == monitor.StatusUp) {
+				t.Fatalf("expected response time only when up, got %v", pointerFloat64Value(responseTime))
+			}
+		})
+	}
+}
+
+func TestWebSocketURL(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		target    string
+		expected  string
+		expectErr bool
+	}{
+		{target: "ws://example.com/socket", expected: "ws://example.com/socket"},
+		{target: "https://example.com:8443/socket", expected: "wss://example.com:8443/socket"},
+		{target: "ftp://example.com", expectErr: true},
+		{target: "example.com", expectErr: true},
+	}
+
+	for _, testCase := range testCases {
+		endpoint, err := webSocketURL(testCase.target)
+		if testCase.expectErr {
+			if err == nil {
+				t.Fatalf("expected error for %q", testCase.target)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("expected no error for %q, got %v", testCase.target, err)
+		}
+		if endpoint.String() != testCase.expected {
+			t.Fatalf("expected %s, got %s", testCase.expected, endpoint.String())
+		}
+	}
+}
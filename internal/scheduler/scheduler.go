@@ -2,31 +2,104 @@ package scheduler
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"math/rand/v2"
 	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/clock"
 )
 
-func RunEveryFiveMinutes(ctx context.Context, logger *log.Logger, task func(context.Context) error) {
-	timer := time.NewTimer(time.Until(nextFiveMinuteBoundary(time.Now())))
+const defaultInterval = 5 * time.Minute
+
+// Options holds the optional settings of RunEveryInterval. The zero value
+// uses the real clock, runs tasks with ctx and neither limits nor delays
+// them.
+type Options struct {
+	// Clock supplies the time the boundaries are computed from.
+	Clock clock.Clock
+	// RunContext is passed to runs instead of ctx, so a run in flight when
+	// scheduling stops keeps going until RunContext is done.
+	RunContext context.Context
+	// MaxDuration, when positive, cancels a run's context once it has been
+	// running that long.
+	MaxDuration time.Duration
+	// Jitter, when positive, delays each run by a random amount up to
+	// Jitter past its boundary.
+	Jitter time.Duration
+}
+
+// RunEveryInterval runs task on every interval boundary until ctx is done.
+// Runs never overlap: a boundary reached while the previous run is still
+// active is skipped.
+func RunEveryInterval(ctx context.Context, logger *slog.Logger, interval time.Duration, task func(context.Context) error, options Options) {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	clk := options.Clock
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	runCtx := options.RunContext
+	if runCtx == nil {
+		runCtx = ctx
+	}
+	jitter := options.Jitter
+
+	timer := time.NewTimer(untilNextRun(clk.Now(), interval, jitter))
 	defer timer.Stop()
 
+	active := make(chan struct{}, 1)
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-timer.C:
-			if err := task(ctx); err != nil && logger != nil {
-				logger.Printf("Scheduled run failed: %v", err)
+			select {
+			case active <- struct{}{}:
+				go func() {
+					defer func() { <-active }()
+					runTask(runCtx, logger, options.MaxDuration, task)
+				}()
+			default:
+				if logger != nil {
+					logger.Warn("Skipping scheduled run, previous run is still active")
+				}
 			}
-			timer.Reset(5 * time.Minute)
+			timer.Reset(untilNextRun(clk.Now(), interval, jitter))
 		}
 	}
 }
 
-func nextFiveMinuteBoundary(now time.Time) time.Time {
-	boundary := now.Truncate(5 * time.Minute)
+func runTask(ctx context.Context, logger *slog.Logger, maxDuration time.Duration, task func(context.Context) error) {
+	if maxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, maxDuration)
+		defer cancel()
+	}
+
+	if err := task(ctx); err != nil && logger != nil {
+		logger.Error("Scheduled run failed", "error", err)
+	}
+}
+
+// untilNextRun spreads runs of many instances that share the same boundary
+// by adding a random delay in [0, jitter].
+func untilNextRun(now time.Time, interval time.Duration, jitter time.Duration) time.Duration {
+	delay := untilNextBoundary(now, interval)
+	if jitter > 0 {
+		delay += time.Duration(rand.Int64N(int64(jitter) + 1))
+	}
+	return delay
+}
+
+func untilNextBoundary(now time.Time, interval time.Duration) time.Duration {
+	return nextIntervalBoundary(now, interval).Sub(now)
+}
+
+func nextIntervalBoundary(now time.Time, interval time.Duration) time.Time {
+	boundary := now.Truncate(interval)
 	if !boundary.After(now) {
-		boundary = boundary.Add(5 * time.Minute)
+		boundary = boundary.Add(interval)
 	}
 	return boundary
 }
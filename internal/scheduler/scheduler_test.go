@@ -2,17 +2,18 @@ package scheduler
 
 import (
 	"context"
-	"io"
-	"log"
+	"errors"
+	"log/slog"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
-func TestNextFiveMinuteBoundary(t *testing.T) {
+func TestNextIntervalBoundaryFiveMinutes(t *testing.T) {
 	t.Parallel()
 
 	now := time.Date(2026, 2, 20, 11, 2, 31, 0, time.UTC)
-	next := nextFiveMinuteBoundary(now)
+	next := nextIntervalBoundary(now, 5*time.Minute)
 
 	expected := time.Date(2026, 2, 20, 11, 5, 0, 0, time.UTC)
 	if !next.Equal(expected) {
@@ -20,7 +21,27 @@ func TestNextFiveMinuteBoundary(t *testing.T) {
 	}
 }
 
-func TestRunEveryFiveMinutesReturnsOnCanceledContext(t *testing.T) {
+func TestNextIntervalBoundarySixtySeconds(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 20, 11, 2, 31, 0, time.UTC)
+	next := nextIntervalBoundary(now, 60*time.Second)
+
+	expected := time.Date(2026, 2, 20, 11, 3, 0, 0, time.UTC)
+	if !next.Equal(expected) {
+		t.Fatalf("expected %s, got %s", expected, next)
+	}
+
+	onBoundary := time.Date(2026, 2, 20, 11, 3, 0, 0, time.UTC)
+	next = nextIntervalBoundary(onBoundary, 60*time.Second)
+
+	expected = time.Date(2026, 2, 20, 11, 4, 0, 0, time.UTC)
+	if !next.Equal(expected) {
+		t.Fatalf("expected %s, got %s", expected, next)
+	}
+}
+
+func TestRunEveryIntervalReturnsOnCanceledContext(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -29,10 +50,10 @@ func TestRunEveryFiveMinutesReturnsOnCanceledContext(t *testing.T) {
 	done := make(chan struct{})
 	taskCalled := make(chan struct{}, 1)
 	go func() {
-		RunEveryFiveMinutes(ctx, log.New(io.Discard, "", 0), func(context.Context) error {
+		RunEveryInterval(ctx, slog.New(slog.DiscardHandler), time.Minute, func(context.Context) error {
 			taskCalled <- struct{}{}
 			return nil
-		})
+		}, Options{})
 		close(done)
 	}()
 
@@ -48,3 +69,187 @@ func TestRunEveryFiveMinutesReturnsOnCanceledContext(t *testing.T) {
 	default:
 	}
 }
+
+func TestRunEveryIntervalRunsTaskOnBoundary(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	taskCalled := make(chan struct{}, 1)
+	go RunEveryInterval(ctx, slog.New(slog.DiscardHandler), 50*time.Millisecond, func(context.Context) error {
+		select {
+		case taskCalled <- struct{}{}:
+		default:
+		}
+		return nil
+	}, Options{})
+
+	select {
+	case <-taskCalled:
+	case <-time.After(time.Second):
+		t.Fatalf("expected task to run on the next interval boundary")
+	}
+}
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func TestRunEveryIntervalUsesClockForBoundary(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Fifty milliseconds before a five-minute boundary, the first run must
+	// fire almost immediately even though the real clock is elsewhere.
+	clk := fixedClock{now: time.Date(2026, 2, 20, 11, 4, 59, 950_000_000, time.UTC)}
+	taskCalled := make(chan struct{}, 1)
+	go RunEveryInterval(ctx, slog.New(slog.DiscardHandler), 5*time.Minute, func(context.Context) error {
+		select {
+		case taskCalled <- struct{}{}:
+		default:
+		}
+		return nil
+	}, Options{Clock: clk})
+
+	select {
+	case <-taskCalled:
+	case <-time.After(time.Second):
+		t.Fatalf("expected task to run at the boundary computed from the injected clock")
+	}
+}
+
+func TestUntilNextBoundary(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 20, 11, 2, 31, 0, time.UTC)
+	if got := untilNextBoundary(now, 5*time.Minute); got != 2*time.Minute+29*time.Second {
+		t.Fatalf("expected 2m29s, got %s", got)
+	}
+}
+
+func TestRunEveryIntervalCancelsRunAfterMaxDuration(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runErrors := make(chan error, 1)
+	go RunEveryInterval(ctx, slog.New(slog.DiscardHandler), 20*time.Millisecond, func(runCtx context.Context) error {
+		select {
+		case <-runCtx.Done():
+		case <-time.After(time.Second):
+		}
+		select {
+		case runErrors <- runCtx.Err():
+		default:
+		}
+		return runCtx.Err()
+	}, Options{MaxDuration: 50 * time.Millisecond})
+
+	select {
+	case err := <-runErrors:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected the run to be canceled by its deadline, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the run to be canceled after the max duration")
+	}
+}
+
+func TestRunEveryIntervalSkipsTickWhileRunActive(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var active, peak, runs atomic.Int32
+	go RunEveryInterval(ctx, slog.New(slog.DiscardHandler), 10*time.Millisecond, func(context.Context) error {
+		current := active.Add(1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		runs.Add(1)
+		time.Sleep(60 * time.Millisecond)
+		active.Add(-1)
+		return nil
+	}, Options{})
+
+	time.Sleep(250 * time.Millisecond)
+	cancel()
+
+	if got := peak.Load(); got != 1 {
+		t.Fatalf("expected runs never to overlap, got %d concurrent runs", got)
+	}
+	// Ten-millisecond ticks over 250ms would start far more runs if
+	// ticks during an active run were queued instead of skipped.
+	if got := runs.Load(); got < 1 || got > 5 {
+		t.Fatalf("expected between 1 and 5 runs, got %d", got)
+	}
+}
+
+func TestUntilNextRunStaysWithinJitterWindow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 20, 11, 2, 31, 0, time.UTC)
+	boundary := 2*time.Minute + 29*time.Second
+	jitter := 30 * time.Second
+
+	for range 100 {
+		got := untilNextRun(now, 5*time.Minute, jitter)
+		if got < boundary || got > boundary+jitter {
+			t.Fatalf("expected delay within [%s, %s], got %s", boundary, boundary+jitter, got)
+		}
+	}
+
+	if got := untilNextRun(now, 5*time.Minute, 0); got != boundary {
+		t.Fatalf("expected no jitter by default, got %s", got)
+	}
+}
+
+func TestRunEveryIntervalLetsActiveRunFinishAfterStop(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	finished := make(chan error, 1)
+	go RunEveryInterval(ctx, slog.New(slog.DiscardHandler), 10*time.Millisecond, func(runCtx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+			return nil
+		}
+		<-release
+		finished <- runCtx.Err()
+		return nil
+	}, Options{RunContext: context.Background()})
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatalf("expected task to start")
+	}
+	cancel()
+	close(release)
+
+	select {
+	case err := <-finished:
+		if err != nil {
+			t.Fatalf("expected the active run to keep its context, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the active run to finish")
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// PauseController is the part of the runner the admin endpoints control.
+type PauseController interface {
+	Pause()
+	Resume()
+	Paused() bool
+}
+
+// AdminHandler serves /admin/pause, /admin/resume and /admin/status. Every
+// request must carry "Authorization: Bearer <token>".
+func AdminHandler(controller PauseController, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/pause", func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		controller.Pause()
+		writeAdminStatus(writer, controller)
+	})
+	mux.HandleFunc("/admin/resume", func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		controller.Resume()
+		writeAdminStatus(writer, controller)
+	})
+	mux.HandleFunc("/admin/status", func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		writeAdminStatus(writer, controller)
+	})
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if !authorized(request, token) {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(writer, request)
+	})
+}
+
+func authorized(request *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+	provided, found := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+	return found && subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+}
+
+func writeAdminStatus(writer http.ResponseWriter, controller PauseController) {
+	status := "running"
+	if controller.Paused() {
+		status = "paused"
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(writer).Encode(map[string]any{"status": status, "paused": controller.Paused()})
+}
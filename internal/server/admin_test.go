@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+type fakePauseController struct {
+	paused atomic.Bool
+}
+
+func (f *fakePauseController) Pause() {
+	f.paused.Store(true)
+}
+
+func (f *fakePauseController) Resume() {
+	f.paused.Store(false)
+}
+
+func (f *fakePauseController) Paused() bool {
+	return f.paused.Load()
+}
+
+func adminRequest(t *testing.T, handler http.Handler, method, path, token string) (int, map[string]any) {
+	t.Helper()
+
+	request := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	var body map[string]any
+	if recorder.Code == http.StatusOK {
+		if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode admin response: %v", err)
+		}
+	}
+	return recorder.Code, body
+}
+
+func TestAdminHandlerTogglesPause(t *testing.T) {
+	t.Parallel()
+
+	controller := &fakePauseController{}
+	handler := Handler(nil, BuildInfo{}, nil, AdminHandler(controller, "admin-secret"), slog.New(slog.DiscardHandler))
+
+	statusCode, body := adminRequest(t, handler, http.MethodPost, "/admin/pause", "admin-secret")
+	if statusCode != http.StatusOK || body["status"] != "paused" || body["paused"] != true {
+		t.Fatalf("expected paused status, got %d %v", statusCode, body)
+	}
+	if !controller.Paused() {
+		t.Fatalf("expected controller to be paused")
+	}
+
+	statusCode, body = adminRequest(t, handler, http.MethodGet, "/admin/status", "admin-secret")
+	if statusCode != http.StatusOK || body["status"] != "paused" {
+		t.Fatalf("expected status to report paused, got %d %v", statusCode, body)
+	}
+
+	statusCode, body = adminRequest(t, handler, http.MethodPost, "/admin/resume", "admin-secret")
+	if statusCode != http.StatusOK || body["status"] != "running" || body["paused"] != false {
+		t.Fatalf("expected running status, got %d %v", statusCode, body)
+	}
+	if controller.Paused() {
+		t.Fatalf("expected controller to be resumed")
+	}
+}
+
+func TestAdminHandlerRejectsRequests(t *testing.T) {
+	t.Parallel()
+
+	controller := &fakePauseController{}
+	handler := AdminHandler(controller, "admin-secret")
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		expected int
+	}{
+		{name: "missing token", method: http.MethodPost, path: "/admin/pause", expected: http.StatusUnauthorized},
+		{name: "wrong token", method: http.MethodPost, path: "/admin/pause", token: "guess", expected: http.StatusUnauthorized},
+		{name: "get pause", method: http.MethodGet, path: "/admin/pause", token: "admin-secret", expected: http.StatusMethodNotAllowed},
+		{name: "post status", method: http.MethodPost, path: "/admin/status", token: "admin-secret", expected: http.StatusMethodNotAllowed},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			statusCode, _ := adminRequest(t, handler, testCase.method, testCase.path, testCase.token)
+			if statusCode != testCase.expected {
+				t.Fatalf("expected %d, got %d", testCase.expected, statusCode)
+			}
+		})
+	}
+	if controller.Paused() {
+		t.Fatalf("expected rejected requests not to pause the controller")
+	}
+}
+
+func TestHandlerWithoutAdminDoesNotServeAdminEndpoints(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/admin/pause", nil)
+	Handler(nil, BuildInfo{}, nil, nil, slog.New(slog.DiscardHandler)).ServeHTTP(recorder, request)
+
+	if recorder.Code == http.StatusOK {
+		t.Fatalf("expected admin endpoints to be unavailable without a token")
+	}
+}
@@ -2,36 +2,131 @@ package server
 
 import (
 	"context"
-	"log"
+	"encoding/json"
+	"log/slog"
+	"net"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/metrics"
 )
 
-func Start(ctx context.Context, address string, logger *log.Logger) error {
+const readinessTimeout = 5 * time.Second
+
+type CoreClient interface {
+	Ping(ctx context.Context) error
+}
+
+type BuildInfo struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+}
+
+// Start serves handler on address until ctx is done, then gives in-flight
+// requests shutdownGrace to finish.
+func Start(ctx context.Context, address string, handler http.Handler, shutdownGrace time.Duration, logger *slog.Logger) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	if logger != nil {
+		logger.Info("Health server listening", "address", address)
+	}
+
+	return serve(ctx, listener, handler, shutdownGrace)
+}
+
+// serve runs the server on listener until ctx is done. In-flight requests
+// then get shutdownGrace to finish before their connections are closed.
+func serve(ctx context.Context, listener net.Listener, handler http.Handler, shutdownGrace time.Duration) error {
 	server := &http.Server{
-		Addr:              address,
-		Handler:           HealthHandler(),
+		Handler:           handler,
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
 	go func() {
 		<-ctx.Done()
-		shutdownContext, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		shutdownContext, cancel := context.WithTimeout(context.Background(), shutdownGrace)
 		defer cancel()
-		_ = server.Shutdown(shutdownContext)
+		if err := server.Shutdown(shutdownContext); err != nil {
+			_ = server.Close()
+		}
 	}()
 
-	if logger != nil {
-		logger.Printf("Health server listening on %s", address)
-	}
-
-	err := server.ListenAndServe()
+	err := server.Serve(listener)
 	if err == http.ErrServerClosed {
 		return nil
 	}
 	return err
 }
 
+// Handler combines the health server endpoints. The admin endpoints are only
+// mounted when admin is not nil.
+func Handler(client CoreClient, info BuildInfo, registry *metrics.Registry, admin http.Handler, logger *slog.Logger) http.Handler {
+	return Mount(PublicHandler(client, info, logger), InternalHandler(registry, admin))
+}
+
+// PublicHandler serves the endpoints probes and load balancers call: the
+// health checks, /readyz and /version.
+func PublicHandler(client CoreClient, info BuildInfo, logger *slog.Logger) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", HealthHandler())
+	mux.Handle("/readyz", ReadinessHandler(client, logger))
+	mux.Handle("/version", VersionHandler(info))
+	return mux
+}
+
+// InternalHandler serves /metrics and, when admin is not nil, /admin/. It can
+// listen on its own address so these stay off the public interface.
+func InternalHandler(registry *metrics.Registry, admin http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", registry.Handler())
+	if admin != nil {
+		mux.Handle("/admin/", admin)
+	}
+	return mux
+}
+
+// Mount serves public and internal from a single listener, routing /metrics
+// and /admin/ to internal.
+func Mount(public, internal http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", public)
+	mux.Handle("/metrics", internal)
+	mux.Handle("/admin/", internal)
+	return mux
+}
+
+// WithPathPrefix serves handler below prefix for reverse-proxied deployments:
+// with "/internal/health", "/internal/health" answers like "/" and
+// "/internal/health/readyz" like "/readyz". Paths outside the prefix get 404.
+// An empty prefix returns handler unchanged.
+func WithPathPrefix(prefix string, handler http.Handler) http.Handler {
+	prefix = strings.TrimRight(strings.TrimSpace(prefix), "/")
+	if prefix == "" {
+		return handler
+	}
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		rest, found := strings.CutPrefix(request.URL.Path, prefix)
+		if !found || (rest != "" && !strings.HasPrefix(rest, "/")) {
+			http.NotFound(writer, request)
+			return
+		}
+		if rest == "" {
+			rest = "/"
+		}
+
+		stripped := request.Clone(request.Context())
+		stripped.URL.Path = rest
+		stripped.URL.RawPath = ""
+		handler.ServeHTTP(writer, stripped)
+	})
+}
+
 func HealthHandler() http.Handler {
 	mux := http.NewServeMux()
 	healthHandler := func(writer http.ResponseWriter, request *http.Request) {
@@ -48,3 +143,54 @@ func HealthHandler() http.Handler {
 
 	return mux
 }
+
+// ReadinessHandler reports whether Core answers. The endpoint is public, so
+// the Core error is only logged and the body carries a fixed reason.
+func ReadinessHandler(client CoreClient, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if client == nil {
+			writeReadiness(writer, http.StatusServiceUnavailable, "unavailable", "core client is not configured")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(request.Context(), readinessTimeout)
+		defer cancel()
+
+		if err := client.Ping(ctx); err != nil {
+			logger.Warn("Readiness check failed to reach Core", "error", err)
+			writeReadiness(writer, http.StatusServiceUnavailable, "unavailable", "core unreachable")
+			return
+		}
+
+		writeReadiness(writer, http.StatusOK, "ready", "")
+	})
+}
+
+func VersionHandler(info BuildInfo) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(writer).Encode(info)
+	})
+}
+
+func writeReadiness(writer http.ResponseWriter, statusCode int, status, reason string) {
+	payload := map[string]string{"status": status}
+	if reason != "" {
+		payload["error"] = reason
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(statusCode)
+	_ = json.NewEncoder(writer).Encode(payload)
+}
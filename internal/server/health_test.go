@@ -2,12 +2,18 @@ package server
 
 import (
 	"context"
-	"io"
-	"log"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/core"
+	"github.com/m-breuer/webguard-instance-v2/internal/metrics"
 )
 
 func TestHealthHandlerGet(t *testing.T) {
@@ -63,7 +69,7 @@ func TestStartShutsDownOnContextCancel(t *testing.T) {
 
 	done := make(chan error, 1)
 	go func() {
-		done <- Start(ctx, "127.0.0.1:0", log.New(io.Discard, "", 0))
+		done <- Start(ctx, "127.0.0.1:0", HealthHandler(), 5*time.Second, slog.New(slog.DiscardHandler))
 	}()
 
 	time.Sleep(50 * time.Millisecond)
@@ -78,3 +84,335 @@ func TestStartShutsDownOnContextCancel(t *testing.T) {
 		t.Fatalf("server did not shutdown in time")
 	}
 }
+
+func TestServeHonoursShutdownGrace(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		grace         time.Duration
+		handlerDelay  time.Duration
+		expectSuccess bool
+	}{
+		{name: "handler finishes within grace", grace: 2 * time.Second, handlerDelay: 200 * time.Millisecond, expectSuccess: true},
+		{name: "handler exceeds grace", grace: 100 * time.Millisecond, handlerDelay: 2 * time.Second, expectSuccess: false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("failed to listen: %v", err)
+			}
+
+			started := make(chan struct{})
+			handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				close(started)
+				select {
+				case <-time.After(testCase.handlerDelay):
+				case <-request.Context().Done():
+					return
+				}
+				writer.WriteHeader(http.StatusOK)
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := make(chan error, 1)
+			go func() {
+				done <- serve(ctx, listener, handler, testCase.grace)
+			}()
+
+			responseErr := make(chan error, 1)
+			go func() {
+				response, err := http.Get("http://" + listener.Addr().String() + "/")
+				if err == nil {
+					_ = response.Body.Close()
+					if response.StatusCode != http.StatusOK {
+						err = fmt.Errorf("unexpected status %d", response.StatusCode)
+					}
+				}
+				responseErr <- err
+			}()
+
+			<-started
+			cancel()
+
+			select {
+			case err := <-responseErr:
+				if testCase.expectSuccess && err != nil {
+					t.Fatalf("expected in-flight request to complete, got %v", err)
+				}
+				if !testCase.expectSuccess && err == nil {
+					t.Fatalf("expected in-flight request to be cut off after the grace period")
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("request did not finish in time")
+			}
+
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("expected graceful shutdown, got %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("server did not shutdown in time")
+			}
+		})
+	}
+}
+
+func TestReadinessHandlerReachableCore(t *testing.T) {
+	t.Parallel()
+
+	coreServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodHead {
+			t.Fatalf("expected HEAD, got %s", request.Method)
+		}
+		if request.Header.Get("X-API-KEY") != "secret-key" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer coreServer.Close()
+
+	request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	recorder := httptest.NewRecorder()
+
+	Handler(core.NewClient(coreServer.URL, "secret-key", "de-1"), BuildInfo{}, nil, nil, slog.New(slog.DiscardHandler)).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (%s)", recorder.Code, recorder.Body.String())
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("expected JSON body, got %q", recorder.Body.String())
+	}
+	if payload["status"] != "ready" {
+		t.Fatalf("expected ready status, got %#v", payload)
+	}
+}
+
+func TestReadinessHandlerUnreachableCore(t *testing.T) {
+	t.Parallel()
+
+	coreServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+	coreURL := coreServer.URL
+	coreServer.Close()
+
+	request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	recorder := httptest.NewRecorder()
+
+	Handler(core.NewClient(coreURL, "secret-key", "de-1"), BuildInfo{}, nil, nil, slog.New(slog.DiscardHandler)).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", recorder.Code)
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("expected JSON body, got %q", recorder.Body.String())
+	}
+	if payload["status"] != "unavailable" {
+		t.Fatalf("expected unavailable status, got %#v", payload)
+	}
+	if payload["error"] != "core unreachable" {
+		t.Fatalf("expected a fixed failure reason in body, got %#v", payload)
+	}
+	if strings.Contains(recorder.Body.String(), coreURL) {
+		t.Fatalf("expected the Core URL to stay out of the body, got %q", recorder.Body.String())
+	}
+}
+
+func TestReadinessHandlerCoreErrorStatus(t *testing.T) {
+	t.Parallel()
+
+	coreServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusForbidden)
+	}))
+	defer coreServer.Close()
+
+	request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	recorder := httptest.NewRecorder()
+
+	ReadinessHandler(core.NewClient(coreServer.URL, "wrong-key", "de-1"), slog.New(slog.DiscardHandler)).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", recorder.Code)
+	}
+}
+
+func TestHandlerKeepsLivenessOnRoot(t *testing.T) {
+	t.Parallel()
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	recorder := httptest.NewRecorder()
+
+	Handler(nil, BuildInfo{}, nil, nil, slog.New(slog.DiscardHandler)).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if recorder.Body.String() != "ok" {
+		t.Fatalf("expected body ok, got %q", recorder.Body.String())
+	}
+}
+
+func TestHandlerVersion(t *testing.T) {
+	t.Parallel()
+
+	request := httptest.NewRequest(http.MethodGet, "/version", nil)
+	recorder := httptest.NewRecorder()
+
+	Handler(nil, BuildInfo{Version: "v1.2.3", Commit: "abc123", Date: "2026-04-24T12:00:00Z"}, nil, nil, slog.New(slog.DiscardHandler)).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+
+	var info BuildInfo
+	if err := json.Unmarshal(recorder.Body.Bytes(), &info); err != nil {
+		t.Fatalf("expected JSON body, got %q", recorder.Body.String())
+	}
+	if info.Version != "v1.2.3" || info.Commit != "abc123" || info.Date != "2026-04-24T12:00:00Z" {
+		t.Fatalf("unexpected build info: %#v", info)
+	}
+}
+
+func TestHandlerMetrics(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	registry.ObserveCheck("http", "up", 20*time.Millisecond)
+
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	recorder := httptest.NewRecorder()
+
+	Handler(nil, BuildInfo{}, registry, nil, slog.New(slog.DiscardHandler)).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("expected text/plain content type, got %q", recorder.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(recorder.Body.String(), `webguard_monitoring_checks_total{status="up",type="http"} 1`) {
+		t.Fatalf("expected check counter in body, got %q", recorder.Body.String())
+	}
+}
+
+func TestWithPathPrefixServesRoutesUnderPrefix(t *testing.T) {
+	t.Parallel()
+
+	handler := WithPathPrefix("/internal/health/", PublicHandler(nil, BuildInfo{Version: "v1.2.3"}, slog.New(slog.DiscardHandler)))
+
+	testCases := []struct {
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{path: "/internal/health", expectedCode: http.StatusOK, expectedBody: "ok"},
+		{path: "/internal/health/", expectedCode: http.StatusOK, expectedBody: "ok"},
+		{path: "/internal/health/health", expectedCode: http.StatusOK, expectedBody: "ok"},
+		{path: "/internal/health/version", expectedCode: http.StatusOK, expectedBody: `"version":"v1.2.3"`},
+		{path: "/internal/health/readyz", expectedCode: http.StatusServiceUnavailable, expectedBody: `"status":"unavailable"`},
+		{path: "/", expectedCode: http.StatusNotFound},
+		{path: "/health", expectedCode: http.StatusNotFound},
+		{path: "/internal/healthz", expectedCode: http.StatusNotFound},
+	}
+
+	for _, testCase := range testCases {
+		request := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != testCase.expectedCode {
+			t.Fatalf("expected status %d for %s, got %d", testCase.expectedCode, testCase.path, recorder.Code)
+		}
+		if !strings.Contains(recorder.Body.String(), testCase.expectedBody) {
+			t.Fatalf("expected body for %s to contain %q, got %q", testCase.path, testCase.expectedBody, recorder.Body.String())
+		}
+	}
+}
+
+func TestWithPathPrefixEmptyKeepsRoutes(t *testing.T) {
+	t.Parallel()
+
+	request := httptest.NewRequest(http.MethodGet, "/health", nil)
+	recorder := httptest.NewRecorder()
+
+	WithPathPrefix("", PublicHandler(nil, BuildInfo{}, slog.New(slog.DiscardHandler))).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+}
+
+func TestMountKeepsInternalRoutesOutsidePrefix(t *testing.T) {
+	t.Parallel()
+
+	registry := metrics.NewRegistry()
+	controller := &fakePauseController{}
+	handler := Mount(
+		WithPathPrefix("/internal/health", PublicHandler(nil, BuildInfo{}, slog.New(slog.DiscardHandler))),
+		InternalHandler(registry, AdminHandler(controller, "admin-secret")),
+	)
+
+	testCases := []struct {
+		path         string
+		token        string
+		expectedCode int
+	}{
+		{path: "/internal/health", expectedCode: http.StatusOK},
+		{path: "/metrics", expectedCode: http.StatusOK},
+		{path: "/admin/status", token: "admin-secret", expectedCode: http.StatusOK},
+		{path: "/", expectedCode: http.StatusNotFound},
+	}
+
+	for _, testCase := range testCases {
+		request := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+		if testCase.token != "" {
+			request.Header.Set("Authorization", "Bearer "+testCase.token)
+		}
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != testCase.expectedCode {
+			t.Fatalf("expected status %d for %s, got %d", testCase.expectedCode, testCase.path, recorder.Code)
+		}
+	}
+}
+
+func TestInternalHandlerServesOnlyMetricsAndAdmin(t *testing.T) {
+	t.Parallel()
+
+	handler := InternalHandler(metrics.NewRegistry(), nil)
+
+	testCases := []struct {
+		path         string
+		expectedCode int
+	}{
+		{path: "/metrics", expectedCode: http.StatusOK},
+		{path: "/", expectedCode: http.StatusNotFound},
+		{path: "/health", expectedCode: http.StatusNotFound},
+		{path: "/admin/status", expectedCode: http.StatusNotFound},
+	}
+
+	for _, testCase := range testCases {
+		request := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != testCase.expectedCode {
+			t.Fatalf("expected status %d for %s, got %d", testCase.expectedCode, testCase.path, recorder.Code)
+		}
+	}
+}
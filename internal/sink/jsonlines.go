@@ -0,0 +1,69 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+// Record is one line written by JSONLines. Kind names the Core endpoint the
+// payload would otherwise have been posted to.
+type Record struct {
+	Kind    string `json:"kind"`
+	Payload any    `json:"payload"`
+}
+
+const (
+	KindMonitoringResponse = "monitoring_response"
+	KindSSLResult          = "ssl_result"
+	KindDomainResult       = "domain_result"
+)
+
+// JSONLines writes every result as a single JSON object per line.
+type JSONLines struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func NewJSONLines(writer io.Writer) *JSONLines {
+	return &JSONLines{writer: writer}
+}
+
+// OpenFile appends JSON lines to the file at path, creating it if needed.
+func OpenFile(path string) (*JSONLines, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open result file: %w", err)
+	}
+	return NewJSONLines(file), nil
+}
+
+func (s *JSONLines) EmitResponse(_ context.Context, payload monitor.MonitoringResponsePayload) error {
+	return s.write(KindMonitoringResponse, payload)
+}
+
+func (s *JSONLines) EmitSSL(_ context.Context, payload monitor.SSLResultPayload) error {
+	return s.write(KindSSLResult, payload)
+}
+
+func (s *JSONLines) EmitDomain(_ context.Context, payload monitor.DomainResultPayload) error {
+	return s.write(KindDomainResult, payload)
+}
+
+func (s *JSONLines) write(kind string, payload any) error {
+	encoded, err := json.Marshal(Record{Kind: kind, Payload: payload})
+	if err != nil {
+		return err
+	}
+	encoded = append(encoded, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.writer.Write(encoded)
+	return err
+}
@@ -0,0 +1,85 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+func TestJSONLinesWritesOneRecordPerLine(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	sink := NewJSONLines(&buffer)
+
+	if err := sink.EmitResponse(context.Background(), monitor.MonitoringResponsePayload{MonitoringID: "http-1", Status: monitor.StatusUp}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sink.EmitSSL(context.Background(), monitor.SSLResultPayload{MonitoringID: "ssl-1", IsValid: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sink.EmitDomain(context.Background(), monitor.DomainResultPayload{MonitoringID: "domain-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var kinds, ids []string
+	scanner := bufio.NewScanner(&buffer)
+	for scanner.Scan() {
+		var record struct {
+			Kind    string `json:"kind"`
+			Payload struct {
+				MonitoringID string `json:"monitoring_id"`
+			} `json:"payload"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("expected a JSON object per line, got %q: %v", scanner.Text(), err)
+		}
+		kinds = append(kinds, record.Kind)
+		ids = append(ids, record.Payload.MonitoringID)
+	}
+
+	expectedKinds := []string{KindMonitoringResponse, KindSSLResult, KindDomainResult}
+	expectedIDs := []string{"http-1", "ssl-1", "domain-1"}
+	for index := range expectedKinds {
+		if index >= len(kinds) || kinds[index] != expectedKinds[index] || ids[index] != expectedIDs[index] {
+			t.Fatalf("expected kinds %v with ids %v, got %v with %v", expectedKinds, expectedIDs, kinds, ids)
+		}
+	}
+}
+
+func TestOpenFileAppends(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	for range 2 {
+		sink, err := OpenFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := sink.EmitResponse(context.Background(), monitor.MonitoringResponsePayload{MonitoringID: "http-1"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read result file: %v", err)
+	}
+	if lines := bytes.Count(content, []byte("\n")); lines != 2 {
+		t.Fatalf("expected two appended lines, got %d", lines)
+	}
+}
+
+func TestOpenFileFailsForMissingDirectory(t *testing.T) {
+	t.Parallel()
+
+	if _, err := OpenFile(filepath.Join(t.TempDir(), "missing", "results.jsonl")); err == nil {
+		t.Fatalf("expected error for a missing directory")
+	}
+}
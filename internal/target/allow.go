@@ -0,0 +1,63 @@
+package target
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// ErrPrivateAddress is wrapped by every error that rejects a private
+// target address.
+var ErrPrivateAddress = errors.New("private target address")
+
+// IsAllowed rejects hosts that are, or resolve to, loopback, private
+// (RFC 1918 and fc00::/7), link-local or unspecified addresses. Hostnames are
+// resolved with resolver, or the default resolver when it is nil. A hostname
+// that does not resolve is allowed: the check itself will report it down.
+func IsAllowed(ctx context.Context, host string, resolver *net.Resolver) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if isPrivateIP(ip) {
+			return fmt.Errorf("%w: target address %s is in a private range", ErrPrivateAddress, ip)
+		}
+		return nil
+	}
+
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	addresses, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, address := range addresses {
+		if isPrivateIP(address.IP) {
+			return fmt.Errorf("%w: target %s resolves to private address %s", ErrPrivateAddress, host, address.IP)
+		}
+	}
+	return nil
+}
+
+// DialControl is a net.Dialer Control hook that refuses to connect to a
+// private address. It runs on the address actually dialed, after
+// resolution, so a DNS answer that changes after IsAllowed cannot slip a
+// private address through.
+func DialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: cannot parse dialed address %s", ErrPrivateAddress, address)
+	}
+	if isPrivateIP(ip) {
+		return fmt.Errorf("%w: refusing to connect to %s", ErrPrivateAddress, ip)
+	}
+	return nil
+}
+
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
@@ -0,0 +1,32 @@
+package target
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// toASCIIHost converts an internationalized hostname to its punycode form so
+// it can be dialed and used for SNI, applying the UTS #46 lookup mapping.
+// ASCII hostnames and IP literals are returned unchanged.
+func toASCIIHost(host string) (string, error) {
+	if isASCII(host) {
+		return host, nil
+	}
+
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid hostname %q: %w", host, err)
+	}
+	return ascii, nil
+}
+
+func isASCII(value string) bool {
+	for index := 0; index < len(value); index++ {
+		if value[index] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
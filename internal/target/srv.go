@@ -0,0 +1,39 @@
+package target
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ResolveSRV looks up the SRV record named by the host of rawTarget, such as
+// _sip._tcp.example.com, and returns the host and port of its
+// highest-priority record. Records of equal priority are ordered by weight
+// as the resolver returns them. The name is resolved with resolver, or the
+// default resolver when it is nil.
+func ResolveSRV(ctx context.Context, rawTarget string, resolver *net.Resolver) (string, int, error) {
+	name, err := Host(rawTarget)
+	if err != nil {
+		return "", 0, err
+	}
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	_, records, err := resolver.LookupSRV(ctx, "", "", name)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(records) == 0 {
+		return "", 0, fmt.Errorf("no SRV record for %s", name)
+	}
+
+	// A target of "." means the service is decidedly not available at this
+	// domain (RFC 2782).
+	host := strings.TrimSuffix(records[0].Target, ".")
+	if host == "" {
+		return "", 0, fmt.Errorf("SRV record for %s marks the service as unavailable", name)
+	}
+	return host, int(records[0].Port), nil
+}
@@ -27,6 +27,53 @@ func Host(rawTarget string) (string, error) {
 	return host, nil
 }
 
+// HTTPURL turns a target without a scheme into a URL for HTTP checks. Bare
+// hosts and hosts on 443 or 8443 get https://, hosts with any other explicit
+// port get http://. Targets that already carry a scheme are returned as is.
+func HTTPURL(rawTarget string) string {
+	target := strings.TrimSpace(rawTarget)
+	if target == "" || strings.Contains(target, "://") {
+		return target
+	}
+
+	hostPort, _, _ := strings.Cut(target, "/")
+	if at := strings.LastIndex(hostPort, "@"); at >= 0 {
+		hostPort = hostPort[at+1:]
+	}
+	if _, port, err := net.SplitHostPort(hostPort); err == nil && port != "443" && port != "8443" {
+		return "http://" + target
+	}
+	return "https://" + target
+}
+
+// SplitUserinfo removes user:password@ from an HTTP URL so the credentials
+// can be sent as basic auth instead of showing up in logs and reported URLs.
+// URLs without userinfo, or that do not parse, are returned unchanged.
+func SplitUserinfo(rawURL string) (string, *url.Userinfo) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.User == nil {
+		return rawURL, nil
+	}
+	credentials := parsedURL.User
+	parsedURL.User = nil
+	return parsedURL.String(), credentials
+}
+
+// Redact removes user:password@ from a target so it can leave the process in
+// logs, notifications and printed results. Targets with and without a scheme
+// are handled; anything else is returned unchanged.
+func Redact(rawTarget string) string {
+	if strings.Contains(rawTarget, "://") {
+		redacted, _ := SplitUserinfo(rawTarget)
+		return redacted
+	}
+	authority, _, _ := strings.Cut(rawTarget, "/")
+	if at := strings.LastIndex(authority, "@"); at >= 0 {
+		return rawTarget[at+1:]
+	}
+	return rawTarget
+}
+
 func SSLAddressAndServerName(rawTarget string) (string, string, error) {
 	host, parsedPort, err := extractHostPort(rawTarget)
 	if err != nil {
@@ -64,9 +111,15 @@ func extractHostPort(rawTarget string) (string, string, error) {
 	}
 
 	host, port, err := net.SplitHostPort(hostPort)
-	if err == nil {
-		return host, port, nil
+	if err != nil {
+		host, port = hostPort, ""
 	}
 
-	return hostPort, "", nil
+	// The original target stays untouched for display; only the dialed host
+	// and SNI name use the ASCII form.
+	host, err = toASCIIHost(host)
+	if err != nil {
+		return "", "", err
+	}
+	return host, port, nil
 }
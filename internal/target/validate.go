@@ -0,0 +1,101 @@
+package target
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/m-breuer/webguard-instance-v2/internal/monitor"
+)
+
+// Validate checks that rawTarget has the shape the given monitoring type
+// dials: an http(s) URL or bare host for HTTP and keyword checks, a ws(s) or http(s) URL
+// for WebSocket checks and a host with an optional port or URL for the
+// rest. Types without a target, such as heartbeats, always pass.
+func Validate(rawTarget string, monitoringType monitor.Type) error {
+	switch monitoringType {
+	case monitor.TypeHTTP, monitor.TypeKeyword:
+		return validateURL(HTTPURL(rawTarget), "http", "https")
+	case monitor.TypeWebSocket:
+		return validateURL(rawTarget, "ws", "wss", "http", "https")
+	case monitor.TypePing, monitor.TypeICMP, monitor.TypePort, monitor.TypeDNS, monitor.TypeSMTP, monitor.TypeDomainExpiration:
+		return validateHost(rawTarget)
+	default:
+		return nil
+	}
+}
+
+func validateURL(rawTarget string, schemes ...string) error {
+	target := strings.TrimSpace(rawTarget)
+	if target == "" {
+		return fmt.Errorf("target is empty")
+	}
+	if strings.ContainsAny(target, " \t\r\n") {
+		return fmt.Errorf("target %q contains whitespace", target)
+	}
+
+	parsedURL, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("target %q is not a valid URL: %w", target, err)
+	}
+	if !slicesContainsFold(schemes, parsedURL.Scheme) {
+		return fmt.Errorf("target %q must use one of the schemes %s", target, strings.Join(schemes, ", "))
+	}
+	return validateHost(target)
+}
+
+func validateHost(rawTarget string) error {
+	target := strings.TrimSpace(rawTarget)
+	if strings.ContainsAny(target, " \t\r\n") {
+		return fmt.Errorf("target %q contains whitespace", target)
+	}
+
+	host, port, err := extractHostPort(target)
+	if err != nil {
+		return err
+	}
+	if port != "" {
+		if value, err := strconv.Atoi(port); err != nil || value < 1 || value > 65535 {
+			return fmt.Errorf("target %q has an invalid port %q", target, port)
+		}
+	}
+	if net.ParseIP(strings.Trim(host, "[]")) != nil {
+		return nil
+	}
+	if !isValidHostname(host) {
+		return fmt.Errorf("target %q has an invalid host %q", target, host)
+	}
+	return nil
+}
+
+func isValidHostname(host string) bool {
+	host = strings.TrimSuffix(host, ".")
+	if host == "" || len(host) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for index := 0; index < len(label); index++ {
+			character := label[index]
+			isAlphanumeric := (character >= 'a' && character <= 'z') || (character >= 'A' && character <= 'Z') || (character >= '0' && character <= '9')
+			if !isAlphanumeric && character != '-' && character != '_' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func slicesContainsFold(values []string, candidate string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, candidate) {
+			return true
+		}
+	}
+	return false
+}